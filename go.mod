@@ -1,3 +1,3 @@
 module github.com/pascaldekloe/tripn
 
-go 1.21
+go 1.23
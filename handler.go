@@ -0,0 +1,91 @@
+package tripn
+
+import (
+	"errors"
+	"io"
+)
+
+// Handler receives the content of a document in order of appearance.
+type Handler interface {
+	// Triple receives statements, with the nested nodes, if any, before
+	// their enclosing statement.
+	Triple(Triple) error
+
+	// Prefix receives each "@prefix" and "PREFIX" directive. The IRI is
+	// resolved against the base IRI in effect.
+	Prefix(label, IRI string) error
+
+	// Base receives each "@base" and "BASE" directive. The IRI is resolved
+	// against the base IRI in effect.
+	Base(IRI string) error
+}
+
+// CommentHandler is an optional extension of Handler.
+type CommentHandler interface {
+	Handler
+
+	// Comment receives the text after each "#" up to the end of line.
+	Comment(text string) error
+}
+
+// ReadTo passes the input stream to h until EOF. Any error from h aborts the
+// read, and it is returned as is. SyntaxErrors and stream errors other than
+// io.EOF are returned like ReadAppend does. ReadTo uses CommentHandler when
+// implemented by h.
+//
+// Triples are passed as soon as they are parsed, without any buffering. A
+// statement with a syntax error may thus have some of its triples passed
+// already, including in Recover mode.
+func (r *Reader) ReadTo(h Handler) error {
+	var handlerErr error
+	r.emit = func(t Triple) error {
+		handlerErr = h.Triple(t)
+		if handlerErr != nil {
+			return errStopped
+		}
+		return nil
+	}
+	r.handler = h
+	r.commentHandler, _ = h.(CommentHandler)
+	defer func() {
+		r.emit = nil
+		r.handler = nil
+		r.commentHandler = nil
+	}()
+
+	for {
+		_, err := r.ReadAppend(nil)
+		if err != nil {
+			switch {
+			case handlerErr != nil:
+				return handlerErr
+			case errors.Is(err, io.EOF):
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// ErrStopped aborts a read on behalf of the triple receiver.
+var errStopped = errors.New("tripn: read stopped by receiver")
+
+// Comment passes the text of a comment, or a continuation of the text after a
+// chunk split, to the comment handler.
+func (r *Reader) comment(text []byte) error {
+	if r.inComment {
+		// await remainder in next chunk
+		r.commentBuf = append(r.commentBuf, text...)
+		return nil
+	}
+
+	if len(r.commentBuf) != 0 {
+		text = append(r.commentBuf, text...)
+		r.commentBuf = r.commentBuf[:0]
+	}
+	// strip line end
+	for len(text) != 0 && (text[len(text)-1] == '\n' || text[len(text)-1] == '\r') {
+		text = text[:len(text)-1]
+	}
+	return r.commentHandler.Comment(string(text))
+}
@@ -0,0 +1,83 @@
+package tripn
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// EventRecorder implements CommentHandler.
+type eventRecorder []string
+
+func (rec *eventRecorder) Triple(t Triple) error {
+	*rec = append(*rec, "triple "+t.String())
+	return nil
+}
+
+func (rec *eventRecorder) Prefix(label, IRI string) error {
+	*rec = append(*rec, fmt.Sprintf("prefix %s: <%s>", label, IRI))
+	return nil
+}
+
+func (rec *eventRecorder) Base(IRI string) error {
+	*rec = append(*rec, fmt.Sprintf("base <%s>", IRI))
+	return nil
+}
+
+func (rec *eventRecorder) Comment(text string) error {
+	*rec = append(*rec, "comment "+text)
+	return nil
+}
+
+func TestReadTo(t *testing.T) {
+	const turtle = `# header
+@base <http://example.com/> .
+PREFIX ex: <ns#>
+<s> ex:p [ ex:q <o> ] ; # inline
+	ex:r "#no comment" .
+BASE <http://example.net/> # long comment ` + "exceeds buffer size"
+	want := []string{
+		"comment  header",
+		"base <http://example.com/>",
+		"prefix ex: <http://example.com/ns#>",
+		"triple <http://example.com/skolem-stub/anon#1> <http://example.com/ns#q> <http://example.com/o> .",
+		"triple <http://example.com/s> <http://example.com/ns#p> <http://example.com/skolem-stub/anon#1> .",
+		"comment  inline",
+		`triple <http://example.com/s> <http://example.com/ns#r> "#no comment" .`,
+		"base <http://example.net/>",
+		"comment  long comment exceeds buffer size",
+	}
+
+	for _, bufSize := range []int{16, 4096} {
+		r := Reader{
+			R:          bufio.NewReaderSize(strings.NewReader(turtle), bufSize),
+			skolemizer: skolemStub,
+		}
+		var got eventRecorder
+		err := r.ReadTo(&got)
+		if err != nil {
+			t.Fatal("read error:", err)
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("buffer size %d got events:\n%s\nwant:\n%s",
+				bufSize, strings.Join(got, "\n"), strings.Join(want, "\n"))
+		}
+	}
+}
+
+func TestReadToStreams(t *testing.T) {
+	const turtle = `<http://example.com/s> <http://example.com/p> <http://example.com/o>, no:o .`
+	r := Reader{R: bufio.NewReader(strings.NewReader(turtle))}
+	var got eventRecorder
+	err := r.ReadTo(&got)
+	if !errors.Is(err, ErrUndefinedPrefix) {
+		t.Errorf("got error %v, want ErrUndefinedPrefix", err)
+	}
+	want := []string{"triple <http://example.com/s> <http://example.com/p> <http://example.com/o> ."}
+	if !slices.Equal(got, want) {
+		t.Errorf("got events %q, want %q", got, want)
+	}
+}
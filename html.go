@@ -0,0 +1,544 @@
+package tripn
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// HTMLReader extracts RDF from HTML documents. Both the RDFa 1.1 attributes
+// and the script elements with Turtle ("text/turtle"), N-Triples
+// ("application/n-triples") or JSON-LD ("application/ld+json") apply. Triples
+// come in the same form as Reader produces them. The default graph of JSON-LD
+// applies exclusively.
+//
+// HTML is read leniently, like browsers do, without any validation. Malformed
+// RDFa, such as relative references without a base, and malformed content in
+// script elements get a *SyntaxError.
+//
+// Anonymous RDFa resources get a new Skolem IRI. Blank node identifiers of RDFa,
+// as in "_:x", get a Skolem IRI each, which is the same for all occurrences in
+// the attributes of the document. Blank node labels in a script element get
+// Skolem IRIs of their own, local to the respective element.
+type HTMLReader struct {
+	R io.Reader
+
+	// BaseIRI is the document base, which a base element in the page may
+	// replace. Users should set the base IRI to the location of the page.
+	// Without any base, RDFa makes no statements on the document itself.
+	BaseIRI *url.URL
+
+	// Remote contexts of JSON-LD are retrieved with Loader. Scripts which
+	// refer to a remote context get an error when Loader is nil.
+	Loader DocumentLoader
+
+	done bool     // read completed
+	base *url.URL // in effect
+
+	scriptNo int // script elements read
+
+	skolemizer // blank node IRIs
+}
+
+// HTMLNode is an element, or text when name is zero.
+type htmlNode struct {
+	name     string      // in lower case
+	attrs    []htmlAttr  // in order of appearance
+	children []*htmlNode // content
+
+	text string // character data, or raw text of script and style elements
+
+	lineNo     int   // input position of start tag
+	textLineNo int   // input position of raw text
+	textOffset int64 // input position of raw text
+}
+
+// HTMLAttr is an attribute, with its name in lower case.
+type htmlAttr struct {
+	name, value string
+}
+
+// Attr returns the value of the attribute with name, if any.
+func (n *htmlNode) attr(name string) (value string, ok bool) {
+	for _, a := range n.attrs {
+		if a.name == name {
+			return a.value, true
+		}
+	}
+	return "", false
+}
+
+// SyntaxErr is a convenience constructor. The position is that of the start
+// tag of n.
+func (r *HTMLReader) syntaxErr(n *htmlNode, kind error, reason string) error {
+	return &SyntaxError{
+		LineNo:  n.lineNo,
+		Offset:  -1,
+		Reason:  reason,
+		Err:     kind,
+		grammar: htmlGrammar,
+	}
+}
+
+// ReadAppend adds all triples from the input stream to dst on the first call,
+// and it returns the extended buffer. Any following calls get io.EOF. Reads
+// match the order of appearance in the document.
+//
+// SyntaxError is used for malformed RDFa, and for malformed content of script
+// elements, with the grammar of the respective content. Stream errors pass as
+// is, and so do errors from Loader.
+func (r *HTMLReader) ReadAppend(dst []Triple) ([]Triple, error) {
+	if r.done {
+		return dst, io.EOF
+	}
+	r.done = true
+
+	doc, err := io.ReadAll(r.R)
+	if err != nil {
+		return dst, err
+	}
+	root := parseHTML(doc)
+
+	r.base = r.BaseIRI
+	if n := findHTMLElement(root, "base"); n != nil {
+		if href, ok := n.attr("href"); ok {
+			l, err := url.Parse(strings.TrimSpace(href))
+			if err != nil {
+				return dst, r.syntaxErr(n, ErrIllegalIRI, fmt.Sprintf("malformed IRI reference %q in base element", href))
+			}
+			if r.base != nil {
+				l = r.base.ResolveReference(l)
+			}
+			if l.Scheme != "" {
+				r.base = l
+			}
+		}
+	}
+	if r.base != nil {
+		// fragment does not apply
+		base := *r.base
+		base.Fragment = ""
+		base.RawFragment = ""
+		r.base = &base
+	}
+
+	return r.rdfa(root, dst)
+}
+
+// FindHTMLElement returns the first element with name in n, if any.
+func findHTMLElement(n *htmlNode, name string) *htmlNode {
+	for _, c := range n.children {
+		if c.name == name {
+			return c
+		}
+		if found := findHTMLElement(c, name); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// Script appends the triples of a script element to dst. Elements without
+// any of the supported media types are ignored.
+func (r *HTMLReader) script(n *htmlNode, dst []Triple) ([]Triple, error) {
+	typ, _ := n.attr("type")
+	typ, _, err := mime.ParseMediaType(typ)
+	if err != nil {
+		return dst, nil // not for us
+	}
+
+	r.scriptNo++
+	skolemIRIRoot := fmt.Sprintf("%sscript%d/", r.skolemIRIRoot(), r.scriptNo)
+
+	switch typ {
+	case "text/turtle":
+		in := Reader{
+			R:          bufio.NewReader(strings.NewReader(n.text)),
+			BaseIRI:    r.base,
+			skolemizer: skolemizer{skolemIRICache: skolemIRIRoot},
+		}
+		for {
+			dst, err = in.ReadAppend(dst)
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					return dst, nil
+				}
+				return dst, scriptErr(n, err)
+			}
+		}
+
+	case "application/n-triples":
+		in := NTriplesReader{R: bufio.NewReader(strings.NewReader(n.text))}
+		in.r.skolemIRICache = skolemIRIRoot
+		for {
+			dst, err = in.ReadAppend(dst)
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					return dst, nil
+				}
+				return dst, scriptErr(n, err)
+			}
+		}
+
+	case "application/ld+json":
+		in := JSONLDReader{
+			R:          strings.NewReader(n.text),
+			BaseIRI:    r.base,
+			Loader:     r.Loader,
+			skolemizer: skolemizer{skolemIRICache: skolemIRIRoot},
+		}
+		quads, err := in.ReadAppend(nil)
+		if err != nil {
+			return dst, scriptErr(n, err)
+		}
+		for _, q := range quads {
+			if q.GraphIRI == "" {
+				dst = append(dst, q.Triple)
+			}
+		}
+	}
+	return dst, nil
+}
+
+// ScriptErr moves the input position of any SyntaxError in err from the script
+// content to the HTML document.
+func scriptErr(n *htmlNode, err error) error {
+	var e *SyntaxError
+	if errors.As(err, &e) {
+		if e.LineNo != 0 {
+			if e.LineNo == 1 {
+				e.Column = 0 // unknown
+			}
+			e.LineNo += n.textLineNo - 1
+		}
+		if e.Offset >= 0 {
+			e.Offset += n.textOffset
+		}
+	}
+	return err
+}
+
+// HTMLVoidElements have no content nor end tag.
+var htmlVoidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true,
+	"hr": true, "img": true, "input": true, "link": true, "meta": true,
+	"param": true, "source": true, "track": true, "wbr": true,
+}
+
+// HTMLClosedBy has the elements which end an open element implicitly with
+// their start tag.
+var htmlClosedBy = map[string]map[string]bool{
+	"li":     {"li": true},
+	"dt":     {"dt": true, "dd": true},
+	"dd":     {"dt": true, "dd": true},
+	"tr":     {"tr": true},
+	"td":     {"td": true, "th": true, "tr": true},
+	"th":     {"td": true, "th": true, "tr": true},
+	"option": {"option": true},
+	"p": {
+		"address": true, "article": true, "aside": true, "blockquote": true,
+		"div": true, "dl": true, "fieldset": true, "footer": true,
+		"form": true, "h1": true, "h2": true, "h3": true, "h4": true,
+		"h5": true, "h6": true, "header": true, "hr": true, "main": true,
+		"nav": true, "ol": true, "p": true, "pre": true, "section": true,
+		"table": true, "ul": true,
+	},
+}
+
+// ParseHTML returns the document node of doc. Unknown constructs are read as
+// text, and end tags without a matching start tag are ignored.
+func parseHTML(doc []byte) *htmlNode {
+	root := new(htmlNode)
+	open := []*htmlNode{root}
+	lineNo := 1
+
+	for i := 0; i < len(doc); {
+		parent := open[len(open)-1]
+
+		if doc[i] != '<' {
+			end := bytes.IndexByte(doc[i:], '<')
+			if end < 0 {
+				end = len(doc)
+			} else {
+				end += i
+			}
+			parent.appendText(decodeHTMLText(doc[i:end]))
+			lineNo += bytes.Count(doc[i:end], []byte{'\n'})
+			i = end
+			continue
+		}
+
+		switch {
+		case bytes.HasPrefix(doc[i:], []byte("<!--")):
+			end := bytes.Index(doc[i+4:], []byte("-->"))
+			if end < 0 {
+				end = len(doc)
+			} else {
+				end += i + 7
+			}
+			lineNo += bytes.Count(doc[i:end], []byte{'\n'})
+			i = end
+
+		case i+1 < len(doc) && (doc[i+1] == '!' || doc[i+1] == '?'):
+			// doctype or processing instruction
+			end := bytes.IndexByte(doc[i:], '>')
+			if end < 0 {
+				end = len(doc)
+			} else {
+				end += i + 1
+			}
+			lineNo += bytes.Count(doc[i:end], []byte{'\n'})
+			i = end
+
+		case i+2 < len(doc) && doc[i+1] == '/' && isASCIILetter(doc[i+2]):
+			name, end := scanHTMLName(doc, i+2)
+			if gt := bytes.IndexByte(doc[end:], '>'); gt < 0 {
+				end = len(doc)
+			} else {
+				end += gt + 1
+			}
+			lineNo += bytes.Count(doc[i:end], []byte{'\n'})
+			i = end
+
+			for j := len(open) - 1; j > 0; j-- {
+				if open[j].name == name {
+					open = open[:j]
+					break
+				}
+			}
+
+		case i+1 < len(doc) && isASCIILetter(doc[i+1]):
+			n := &htmlNode{lineNo: lineNo}
+			var selfClosing bool
+			var end int
+			n.name, end = scanHTMLName(doc, i+1)
+			n.attrs, selfClosing, end = scanHTMLAttrs(doc, end)
+			lineNo += bytes.Count(doc[i:end], []byte{'\n'})
+			i = end
+
+			// implied end tags
+			for len(open) > 1 && htmlClosedBy[open[len(open)-1].name][n.name] {
+				open = open[:len(open)-1]
+			}
+			parent = open[len(open)-1]
+			parent.children = append(parent.children, n)
+
+			switch {
+			case n.name == "script" || n.name == "style":
+				// raw text up to the end tag
+				n.textLineNo = lineNo
+				n.textOffset = int64(i)
+				end := indexHTMLEndTag(doc[i:], n.name)
+				if end < 0 {
+					end = len(doc)
+				} else {
+					end += i
+				}
+				n.text = string(doc[i:end])
+				lineNo += bytes.Count(doc[i:end], []byte{'\n'})
+				i = end
+				if i < len(doc) {
+					// pass end tag
+					if gt := bytes.IndexByte(doc[i:], '>'); gt < 0 {
+						i = len(doc)
+					} else {
+						i += gt + 1
+					}
+				}
+
+			case !selfClosing && !htmlVoidElements[n.name]:
+				open = append(open, n)
+			}
+
+		default:
+			parent.appendText("<")
+			i++
+		}
+	}
+	return root
+}
+
+// AppendText adds character data to the content of n.
+func (n *htmlNode) appendText(s string) {
+	if len(n.children) != 0 {
+		if last := n.children[len(n.children)-1]; last.name == "" {
+			last.text += s
+			return
+		}
+	}
+	n.children = append(n.children, &htmlNode{text: s})
+}
+
+// IsASCIILetter returns whether c is in A–Z or a–z.
+func isASCIILetter(c byte) bool {
+	return c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z'
+}
+
+// ScanHTMLName returns the name at offset i of doc in lower case, with the
+// offset after the name.
+func scanHTMLName(doc []byte, i int) (name string, end int) {
+	end = i
+	for end < len(doc) {
+		switch doc[end] {
+		case ' ', '\t', '\r', '\n', '\f', '/', '>':
+			return strings.ToLower(string(doc[i:end])), end
+		}
+		end++
+	}
+	return strings.ToLower(string(doc[i:])), end
+}
+
+// ScanHTMLAttrs reads the attributes at offset i of doc up to the end of the
+// tag, with the offset after the tag.
+func scanHTMLAttrs(doc []byte, i int) (attrs []htmlAttr, selfClosing bool, end int) {
+	for i < len(doc) {
+		switch doc[i] {
+		case ' ', '\t', '\r', '\n', '\f':
+			i++
+			continue
+		case '>':
+			return attrs, selfClosing, i + 1
+		case '/':
+			selfClosing = true
+			i++
+			continue
+		}
+		selfClosing = false
+
+		// attribute name
+		start := i
+		for i < len(doc) {
+			c := doc[i]
+			if c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == '/' || c == '>' || (c == '=' && i != start) {
+				break
+			}
+			i++
+		}
+		a := htmlAttr{name: strings.ToLower(string(doc[start:i]))}
+
+		// optional value
+		j := i
+		for j < len(doc) && (doc[j] == ' ' || doc[j] == '\t' || doc[j] == '\r' || doc[j] == '\n' || doc[j] == '\f') {
+			j++
+		}
+		if j < len(doc) && doc[j] == '=' {
+			j++
+			for j < len(doc) && (doc[j] == ' ' || doc[j] == '\t' || doc[j] == '\r' || doc[j] == '\n' || doc[j] == '\f') {
+				j++
+			}
+			if j < len(doc) && (doc[j] == '"' || doc[j] == '\'') {
+				q := bytes.IndexByte(doc[j+1:], doc[j])
+				if q < 0 {
+					q = len(doc) - j - 1
+				}
+				a.value = decodeHTMLText(doc[j+1 : j+1+q])
+				i = min(j+2+q, len(doc))
+			} else {
+				start := j
+				for j < len(doc) && doc[j] != ' ' && doc[j] != '\t' && doc[j] != '\r' && doc[j] != '\n' && doc[j] != '\f' && doc[j] != '>' {
+					j++
+				}
+				a.value = decodeHTMLText(doc[start:j])
+				i = j
+			}
+		}
+
+		// first one wins
+		if !slices.ContainsFunc(attrs, func(b htmlAttr) bool { return b.name == a.name }) {
+			attrs = append(attrs, a)
+		}
+	}
+	return attrs, selfClosing, len(doc)
+}
+
+// IndexHTMLEndTag returns the index of the end tag of name in text, with -1
+// for none.
+func indexHTMLEndTag(text []byte, name string) int {
+	for i := 0; ; {
+		j := bytes.Index(text[i:], []byte("</"))
+		if j < 0 {
+			return -1
+		}
+		i += j
+		end := i + 2 + len(name)
+		if end <= len(text) && strings.EqualFold(string(text[i+2:end]), name) &&
+			(end == len(text) || bytes.IndexByte([]byte(" \t\r\n\f/>"), text[end]) >= 0) {
+			return i
+		}
+		i += 2
+	}
+}
+
+// DecodeHTMLText resolves the character references in text.
+func decodeHTMLText(text []byte) string {
+	i := bytes.IndexByte(text, '&')
+	if i < 0 {
+		return string(text)
+	}
+
+	var b strings.Builder
+	b.Grow(len(text))
+	for ; i >= 0; i = bytes.IndexByte(text, '&') {
+		b.Write(text[:i])
+		text = text[i:]
+		if s, n := htmlCharRef(text); n != 0 {
+			b.WriteString(s)
+			text = text[n:]
+		} else {
+			b.WriteByte('&')
+			text = text[1:]
+		}
+	}
+	b.Write(text)
+	return b.String()
+}
+
+// HTMLCharRef returns the character reference at the start of text, with its
+// size in bytes. The size is zero for none.
+func htmlCharRef(text []byte) (s string, n int) {
+	end := bytes.IndexByte(text, ';')
+	if end < 2 || end > 33 {
+		return "", 0
+	}
+	ref := string(text[1:end])
+
+	if ref[0] == '#' {
+		var u uint64
+		var err error
+		if len(ref) > 1 && (ref[1] == 'x' || ref[1] == 'X') {
+			u, err = strconv.ParseUint(ref[2:], 16, 32)
+		} else {
+			u, err = strconv.ParseUint(ref[1:], 10, 32)
+		}
+		if err != nil || u == 0 || !utf8.ValidRune(rune(u)) {
+			return "", 0
+		}
+		return string(rune(u)), end + 1
+	}
+
+	switch ref {
+	case "amp":
+		return "&", end + 1
+	case "lt":
+		return "<", end + 1
+	case "gt":
+		return ">", end + 1
+	case "quot":
+		return "\"", end + 1
+	case "apos":
+		return "'", end + 1
+	}
+	if s, ok := xml.HTMLEntity[ref]; ok {
+		return s, end + 1
+	}
+	return "", 0
+}
@@ -0,0 +1,196 @@
+package tripn
+
+import (
+	"errors"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var htmlTriples = []struct {
+	html    string
+	triples []Triple
+}{
+	{"<!DOCTYPE html><title>no data</title>", []Triple{}},
+
+	// RDFa 1.1 Primer, with vocab, typeof and nested property chains
+	{`<!DOCTYPE html>
+<html>
+<body vocab="http://schema.org/">
+  <div resource="/alice/posts/trouble_with_bob" typeof="BlogPosting">
+    <h2 property="headline">The trouble with Bob</h2>
+    <p>Date: <span property="datePublished">2011-09-10</span>
+    <div property="author" typeof="Person">
+      <span property="name">Alice &amp; Eve</span>
+    </div>
+  </div>
+</body>
+</html>`,
+		[]Triple{
+			{"http://example.org/page", rdfaUsesVocabulary, "http://schema.org/", "", "", ""},
+			{"http://example.org/alice/posts/trouble_with_bob", rdfType, "http://schema.org/BlogPosting", "", "", ""},
+			{"http://example.org/alice/posts/trouble_with_bob", "http://schema.org/headline", "The trouble with Bob", XSDString, "", ""},
+			{"http://example.org/alice/posts/trouble_with_bob", "http://schema.org/datePublished", "2011-09-10", XSDString, "", ""},
+			{"http://example.com/skolem-stub/anon#1", rdfType, "http://schema.org/Person", "", "", ""},
+			{"http://example.org/alice/posts/trouble_with_bob", "http://schema.org/author", "http://example.com/skolem-stub/anon#1", "", "", ""},
+			{"http://example.com/skolem-stub/anon#1", "http://schema.org/name", "Alice & Eve", XSDString, "", ""},
+		},
+	},
+
+	// prefixes, languages, datatypes, incomplete triples and rev
+	{`<html prefix="ex: http://example.com/ns#" lang="EN">
+<head><base href="http://example.com/doc"><title property="dc:title">Doc</title></head>
+<body>
+  <p about="#me" rel="foaf:knows"><a href="#you">you</a> and
+    <span about="_:x" property="ex:age" datatype="xsd:integer" content="42" lang="nl">veertig</span></p>
+  <p about="#me" rev="ex:parentOf" resource="#kid"></p>
+  <p about="#me" property="ex:note" datatype="rdf:XMLLiteral">a <b>bold</b> move</p>
+  <p about="#me" property="ex:plain" datatype="">x<br/>y</p>
+  <link about="#me" rel="license" href="http://example.com/license">
+</body>
+</html>`,
+		[]Triple{
+			{"http://example.com/doc", "http://purl.org/dc/terms/title", "Doc", rdfLangString, "en", ""},
+			{"http://example.com/doc#me", "http://xmlns.com/foaf/0.1/knows", "http://example.com/doc#you", "", "", ""},
+			{"http://example.com/skolem-stub/blank#x", "http://example.com/ns#age", "42", XSDInteger, "", ""},
+			{"http://example.com/doc#me", "http://xmlns.com/foaf/0.1/knows", "http://example.com/skolem-stub/blank#x", "", "", ""},
+			{"http://example.com/doc#kid", "http://example.com/ns#parentOf", "http://example.com/doc#me", "", "", ""},
+			{"http://example.com/doc#me", "http://example.com/ns#note", "a <b>bold</b> move", rdfXMLLiteral, "", ""},
+			{"http://example.com/doc#me", "http://example.com/ns#plain", "xy", rdfLangString, "en", ""},
+			{"http://example.com/doc#me", xhvNS + "license", "http://example.com/license", "", "", ""},
+		},
+	},
+
+	// lists with inlist
+	{`<div vocab="http://example.com/" about="#book">
+  <span property="author" inlist>A</span>
+  <span property="author" inlist>B</span>
+  <span rel="cites" inlist resource="#other"></span>
+  <ul rel="tags" inlist><li resource="#t1"></li></ul>
+  <span property="none" inlist content="" datatype=""></span>
+</div>`,
+		[]Triple{
+			{"http://example.org/page", rdfaUsesVocabulary, "http://example.com/", "", "", ""},
+			{"http://example.com/skolem-stub/anon#2", rdfFirst, "A", XSDString, "", ""},
+			{"http://example.com/skolem-stub/anon#2", rdfRest, "http://example.com/skolem-stub/anon#3", "", "", ""},
+			{"http://example.com/skolem-stub/anon#3", rdfFirst, "B", XSDString, "", ""},
+			{"http://example.com/skolem-stub/anon#3", rdfRest, rdfNil, "", "", ""},
+			{"http://example.org/page#book", "http://example.com/author", "http://example.com/skolem-stub/anon#2", "", "", ""},
+			{"http://example.com/skolem-stub/anon#4", rdfFirst, "http://example.org/page#other", "", "", ""},
+			{"http://example.com/skolem-stub/anon#4", rdfRest, rdfNil, "", "", ""},
+			{"http://example.org/page#book", "http://example.com/cites", "http://example.com/skolem-stub/anon#4", "", "", ""},
+			{"http://example.com/skolem-stub/anon#5", rdfFirst, "", XSDString, "", ""},
+			{"http://example.com/skolem-stub/anon#5", rdfRest, rdfNil, "", "", ""},
+			{"http://example.org/page#book", "http://example.com/none", "http://example.com/skolem-stub/anon#5", "", "", ""},
+			{"http://example.com/skolem-stub/anon#6", rdfFirst, "http://example.org/page#t1", "", "", ""},
+			{"http://example.com/skolem-stub/anon#6", rdfRest, rdfNil, "", "", ""},
+			{"http://example.org/page#book", "http://example.com/tags", "http://example.com/skolem-stub/anon#6", "", "", ""},
+		},
+	},
+
+	// list on the document
+	{`<body><span property="http://example.com/p" inlist>x</span></body>`,
+		[]Triple{
+			{"http://example.com/skolem-stub/anon#1", rdfFirst, "x", XSDString, "", ""},
+			{"http://example.com/skolem-stub/anon#1", rdfRest, rdfNil, "", "", ""},
+			{"http://example.org/page", "http://example.com/p", "http://example.com/skolem-stub/anon#1", "", "", ""},
+		},
+	},
+
+	// script elements, with blank node labels local to each
+	{`<html><head>
+<script type="text/turtle">
+  @prefix : <http://example.com/> .
+  <> :p _:b ; :q "</p>" .
+</script>
+<script type="text/javascript">if (a < b) { document.write("<p>") }</script>
+<script type="application/ld+json; charset=utf-8">
+  {"@id": "", "http://example.com/p": {"@id": "_:b"}}
+</script>
+<script type="text/turtle">[] <http://example.com/r> _:b .</script>
+<script type="application/n-triples">
+  <http://example.com/s> <http://example.com/p> _:b .
+</script>
+</head></html>`,
+		[]Triple{
+			{"http://example.org/page", "http://example.com/p", "http://example.com/skolem-stub/script1/blank#b", "", "", ""},
+			{"http://example.org/page", "http://example.com/q", "</p>", XSDString, "", ""},
+			{"http://example.org/page", "http://example.com/p", "http://example.com/skolem-stub/script3/blank#b", "", "", ""},
+			{"http://example.com/skolem-stub/script4/anon#1", "http://example.com/r", "http://example.com/skolem-stub/script4/blank#b", "", "", ""},
+			{"http://example.com/s", "http://example.com/p", "http://example.com/skolem-stub/script5/blank#b", "", "", ""},
+		},
+	},
+}
+
+func TestHTMLReader(t *testing.T) {
+	base, err := url.Parse("http://example.org/page")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range htmlTriples {
+		r := HTMLReader{
+			R:          strings.NewReader(test.html),
+			BaseIRI:    base,
+			skolemizer: skolemStub,
+		}
+
+		got := []Triple{}
+		var err error
+		for err == nil {
+			got, err = r.ReadAppend(got)
+		}
+		if err != io.EOF {
+			t.Errorf("got error %v, for HTML:\n%s", err, test.html)
+			continue
+		}
+		if !slices.Equal(got, test.triples) {
+			msg := "got triples:"
+			for _, t := range got {
+				msg += "\n\t" + t.String()
+			}
+			msg += "\nwant triples:"
+			for _, t := range test.triples {
+				msg += "\n\t" + t.String()
+			}
+			t.Error(msg, "\nfor HTML:\n", test.html)
+		}
+	}
+}
+
+var htmlSyntaxErrors = []struct {
+	html   string
+	reason string
+	lineNo int
+	err    error
+}{
+	{"<p>\n<span about=\"rel\" property=\"http://example.com/p\">x</span>",
+		`relative reference "rel" without base IRI`, 2, ErrNoBaseIRI},
+	{`<p prefix="ex http://example.com/">`,
+		`malformed prefix mapping "ex"`, 1, ErrUnexpectedToken},
+	{"<html>\n<script type=\"text/turtle\">\n<http://example.com/s> <http://example.com/p> } .\n</script>",
+		"illegal object token", 3, ErrUnexpectedToken},
+	{"<html>\n<script type=\"application/n-triples\">\n@prefix : <http://example.com/> .\n</script>",
+		"subject is not an IRI reference nor a blank node label", 3, ErrUnexpectedToken},
+}
+
+func TestHTMLReaderSyntaxErrors(t *testing.T) {
+	for _, test := range htmlSyntaxErrors {
+		r := HTMLReader{R: strings.NewReader(test.html)}
+
+		var err error
+		for err == nil {
+			_, err = r.ReadAppend(nil)
+		}
+		var e *SyntaxError
+		if !errors.As(err, &e) {
+			t.Errorf("got error %v, want a *SyntaxError, for HTML:\n%s", err, test.html)
+			continue
+		}
+		if e.Reason != test.reason || e.LineNo != test.lineNo || !errors.Is(err, test.err) {
+			t.Errorf("got %v, want %q on line %d, for HTML:\n%s", err, test.reason, test.lineNo, test.html)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package tripn
+
+import (
+	"errors"
+	"io"
+	"iter"
+)
+
+// All returns an iterator over the triples in the input stream, up to EOF.
+// Nested nodes, if any, are yielded before their enclosing statement. Errors
+// end the iteration, with a zero Triple, as documented by ReadAppend. Triples
+// are yielded as soon as they are parsed, without any buffering. A statement
+// with a syntax error may thus have some of its triples yielded already.
+func (r *Reader) All() iter.Seq2[Triple, error] {
+	return func(yield func(Triple, error) bool) {
+		stopped := false
+		r.emit = func(t Triple) error {
+			if !yield(t, nil) {
+				stopped = true
+				return errStopped
+			}
+			return nil
+		}
+		defer func() { r.emit = nil }()
+
+		for {
+			_, err := r.ReadAppend(nil)
+			if err != nil {
+				if !stopped && !errors.Is(err, io.EOF) {
+					yield(Triple{}, err)
+				}
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package tripn
+
+import (
+	"bufio"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestReaderAll(t *testing.T) {
+	const turtle = `@prefix : <http://example.com/> .
+:s :p ( :a [ :q :b ] ) .
+:s :p :c .
+:s :p no:d .
+:s :p :e .`
+
+	r := Reader{
+		R:          bufio.NewReader(strings.NewReader(turtle)),
+		skolemizer: skolemStub,
+	}
+	var got []string
+	var err error
+	for triple, e := range r.All() {
+		if e != nil {
+			err = e
+			continue
+		}
+		got = append(got, triple.String())
+	}
+	want := []string{
+		"<http://example.com/skolem-stub/anon#1> <http://www.w3.org/1999/02/22-rdf-syntax-ns#first> <http://example.com/a> .",
+		"<http://example.com/skolem-stub/anon#1> <http://www.w3.org/1999/02/22-rdf-syntax-ns#rest> <http://example.com/skolem-stub/anon#2> .",
+		"<http://example.com/skolem-stub/anon#3> <http://example.com/q> <http://example.com/b> .",
+		"<http://example.com/skolem-stub/anon#2> <http://www.w3.org/1999/02/22-rdf-syntax-ns#first> <http://example.com/skolem-stub/anon#3> .",
+		"<http://example.com/skolem-stub/anon#2> <http://www.w3.org/1999/02/22-rdf-syntax-ns#rest> <http://www.w3.org/1999/02/22-rdf-syntax-ns#nil> .",
+		"<http://example.com/s> <http://example.com/p> <http://example.com/skolem-stub/anon#1> .",
+		"<http://example.com/s> <http://example.com/p> <http://example.com/c> .",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got triples:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if !errors.Is(err, ErrUndefinedPrefix) {
+		t.Errorf("got error %v, want ErrUndefinedPrefix", err)
+	}
+
+	// break out of the loop early
+	r = Reader{R: bufio.NewReader(strings.NewReader(turtle))}
+	for triple, err := range r.All() {
+		if err != nil {
+			t.Fatal("read error:", err)
+		}
+		if triple.PredicateIRI != "http://www.w3.org/1999/02/22-rdf-syntax-ns#first" {
+			t.Errorf("got first triple %s, want rdf:first of collection", triple)
+		}
+		break
+	}
+}
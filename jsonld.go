@@ -0,0 +1,1479 @@
+package tripn
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// RDF vocabulary for JSON-LD.
+const rdfJSON = "http://www.w3.org/1999/02/22-rdf-syntax-ns#JSON"
+
+// JSONLDReader converts JSON-LD to RDF, as specified by the expansion and the
+// deserialization algorithms of JSON-LD 1.1. The document is read as a whole.
+// Duplicate statements are omitted.
+//
+// Blank node identifiers, as in "_:b0", get a Skolem IRI each, which is the same
+// for all of their occurrences in the document. Node objects without an @id get
+// a new Skolem IRI. Nodes with relative IRIs, for which no base applies, get
+// omitted like the specification demands.
+type JSONLDReader struct {
+	R io.Reader
+
+	// BaseIRI is the document base, which @base in a context may replace.
+	// Users may initialize the base IRI to the data location.
+	BaseIRI *url.URL
+
+	// Remote contexts are retrieved with Loader. Documents which refer to
+	// a remote context get an error when Loader is nil.
+	Loader DocumentLoader
+
+	done  bool              // read completed
+	quads []Quad            // conversion result
+	seen  map[Quad]struct{} // duplicate detection
+
+	remoteCache map[string]any // parsed documents per IRI
+
+	skolemizer // blank node IRIs
+}
+
+// JSONLDContext is an active context.
+type jsonLDContext struct {
+	base      *url.URL // @base in effect, if any
+	vocab     string   // @vocab in effect, if any
+	language  string   // @language in effect, if any
+	direction string   // @direction in effect, if any
+
+	terms map[string]*jsonLDTerm // definitions per term
+
+	// Type-scoped contexts do not apply to nested nodes.
+	previous *jsonLDContext
+}
+
+// JSONLDTerm is a term definition.
+type jsonLDTerm struct {
+	IRI     string // zero for a null mapping
+	reverse bool   // reverse property
+	prefix  bool   // usable in compact IRIs
+	typ     string // type mapping, if any
+
+	language    string // language mapping, if any
+	hasLanguage bool   // language mapping, possibly null
+
+	direction    string // direction mapping, if any
+	hasDirection bool   // direction mapping, possibly null
+
+	container []string // container mapping, if any
+
+	context    any      // scoped context, if any
+	hasContext bool     // scoped context, possibly null
+	baseURL    *url.URL // location of the scoped context
+}
+
+// HasContainer returns whether the container mapping of t includes keyword.
+// The nil term has no containers.
+func (t *jsonLDTerm) hasContainer(keyword string) bool {
+	return t != nil && slices.Contains(t.container, keyword)
+}
+
+// IsJSONLDKeyword returns whether s is a keyword from JSON-LD 1.1.
+func isJSONLDKeyword(s string) bool {
+	switch s {
+	case "@base", "@container", "@context", "@direction", "@graph",
+		"@id", "@import", "@included", "@index", "@json", "@language",
+		"@list", "@nest", "@none", "@prefix", "@propagate", "@protected",
+		"@reverse", "@set", "@type", "@value", "@version", "@vocab":
+		return true
+	}
+	return false
+}
+
+// HasKeywordForm returns whether s is an "@" followed by letters only. Such
+// terms are reserved for future use, and they are ignored as such.
+func hasKeywordForm(s string) bool {
+	if len(s) < 2 || s[0] != '@' {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		if c := s[i]; !(c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z') {
+			return false
+		}
+	}
+	return true
+}
+
+// IsAbsIRI returns whether s starts with a scheme.
+func isAbsIRI(s string) bool {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z':
+			continue
+		case i != 0 && (c >= '0' && c <= '9' || c == '+' || c == '-' || c == '.'):
+			continue
+		case i != 0 && c == ':':
+			return true
+		}
+		return false
+	}
+	return false
+}
+
+// AsArray returns v as an array, with nil for null.
+func asArray(v any) []any {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case []any:
+		return v
+	default:
+		return []any{v}
+	}
+}
+
+// SyntaxErr is a convenience constructor. The position is unknown, as the
+// algorithms operate on the parsed document. The reason starts with an error
+// code from the JSON-LD specification.
+func (r *JSONLDReader) syntaxErr(kind error, reason string) error {
+	return &SyntaxError{
+		Offset:  -1,
+		Reason:  reason,
+		Err:     kind,
+		grammar: jsonLDGrammar,
+	}
+}
+
+// ReadAppend adds all quads from the input stream to dst on the first call, and
+// it returns the extended buffer. Any following calls get io.EOF.
+//
+// SyntaxError is used for malformed JSON-LD exclusively, which includes
+// malformed JSON. Stream errors pass as is, and so do errors from Loader.
+func (r *JSONLDReader) ReadAppend(dst []Quad) ([]Quad, error) {
+	if r.done {
+		return dst, io.EOF
+	}
+	r.done = true
+
+	doc, err := r.decode(r.R)
+	if err != nil {
+		return dst, err
+	}
+	err = r.toRDF(doc)
+	if err != nil {
+		return dst, err
+	}
+	dst = append(dst, r.quads...)
+	r.quads = nil
+	r.seen = nil
+	return dst, nil
+}
+
+// Decode parses a JSON document in full.
+func (r *JSONLDReader) decode(in io.Reader) (any, error) {
+	dec := json.NewDecoder(in)
+	dec.UseNumber()
+	var doc any
+	err := dec.Decode(&doc)
+	if err == nil {
+		// nothing but whitespace may follow
+		_, err = dec.Token()
+		if err == nil {
+			return nil, r.syntaxErr(ErrMalformedJSON, "data after top-level value")
+		}
+		if errors.Is(err, io.EOF) {
+			return doc, nil
+		}
+	}
+
+	var e *json.SyntaxError
+	switch {
+	case errors.As(err, &e):
+		return nil, &SyntaxError{
+			Offset:  e.Offset,
+			Reason:  e.Error(),
+			Err:     ErrMalformedJSON,
+			grammar: jsonLDGrammar,
+		}
+	case errors.Is(err, io.EOF):
+		return nil, fmt.Errorf("%w: no JSON document", io.ErrUnexpectedEOF)
+	}
+	return nil, err
+}
+
+// LoadRemote returns the document of IRI with caching.
+func (r *JSONLDReader) loadRemote(IRI string) (any, error) {
+	if doc, ok := r.remoteCache[IRI]; ok {
+		return doc, nil
+	}
+	if r.Loader == nil {
+		return nil, fmt.Errorf("JSON-LD remote context %q: no Loader", IRI)
+	}
+	data, err := r.Loader.LoadDocument(IRI)
+	if err != nil {
+		return nil, fmt.Errorf("JSON-LD remote context %q: %w", IRI, err)
+	}
+	doc, err := r.decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("JSON-LD remote context %q: %w", IRI, err)
+	}
+
+	if r.remoteCache == nil {
+		r.remoteCache = make(map[string]any)
+	}
+	r.remoteCache[IRI] = doc
+	return doc, nil
+}
+
+// InitialContext returns a new active context.
+func (r *JSONLDReader) initialContext() *jsonLDContext {
+	return &jsonLDContext{
+		base:  r.BaseIRI,
+		terms: make(map[string]*jsonLDTerm),
+	}
+}
+
+// MaxRemoteContexts limits the nesting of remote contexts.
+const maxRemoteContexts = 32
+
+// ProcessContext applies local on active, with base as the location of local.
+// The remote contexts in progress are in remotes.
+func (r *JSONLDReader) processContext(active *jsonLDContext, local any, base *url.URL, remotes []string, propagate bool) (*jsonLDContext, error) {
+	result := *active
+	result.terms = maps.Clone(active.terms)
+	if m, ok := local.(map[string]any); ok {
+		if p, ok := m["@propagate"]; ok {
+			b, ok := p.(bool)
+			if !ok {
+				return nil, r.syntaxErr(ErrUnexpectedToken, "invalid @propagate value")
+			}
+			propagate = b
+		}
+	}
+	if !propagate && result.previous == nil {
+		result.previous = active
+	}
+
+	for _, context := range asArray(local) {
+		switch context := context.(type) {
+		case nil:
+			previous := result.previous
+			result = *r.initialContext()
+			if !propagate {
+				result.previous = previous
+			}
+			continue
+
+		case string:
+			ref, err := url.Parse(context)
+			if err != nil {
+				return nil, r.syntaxErr(ErrIllegalIRI, fmt.Sprintf("loading remote context failed: malformed IRI %q", context))
+			}
+			if base != nil {
+				ref = base.ResolveReference(ref)
+			} else if !ref.IsAbs() {
+				return nil, r.syntaxErr(ErrNoBaseIRI, fmt.Sprintf("loading remote context failed: relative reference %q without base IRI", context))
+			}
+			IRI := ref.String()
+			if len(remotes) >= maxRemoteContexts {
+				return nil, r.syntaxErr(ErrUnexpectedToken, fmt.Sprintf("context overflow at %q", IRI))
+			}
+
+			doc, err := r.loadRemote(IRI)
+			if err != nil {
+				return nil, err
+			}
+			m, ok := doc.(map[string]any)
+			if !ok {
+				return nil, r.syntaxErr(ErrUnexpectedToken, fmt.Sprintf("invalid remote context %q", IRI))
+			}
+			c, ok := m["@context"]
+			if !ok {
+				return nil, r.syntaxErr(ErrUnexpectedToken, fmt.Sprintf("invalid remote context %q", IRI))
+			}
+			p, err := r.processContext(&result, c, ref, append(remotes, IRI), true)
+			if err != nil {
+				return nil, err
+			}
+			result = *p
+			continue
+
+		case map[string]any:
+			err := r.processContextDefinition(&result, context, base, remotes)
+			if err != nil {
+				return nil, err
+			}
+
+		default:
+			return nil, r.syntaxErr(ErrUnexpectedToken, "invalid local context")
+		}
+	}
+	return &result, nil
+}
+
+// ProcessContextDefinition applies a context definition on result.
+func (r *JSONLDReader) processContextDefinition(result *jsonLDContext, context map[string]any, base *url.URL, remotes []string) error {
+	if v, ok := context["@version"]; ok {
+		if n, ok := v.(json.Number); !ok || n.String() != "1.1" {
+			return r.syntaxErr(ErrUnexpectedToken, "invalid @version value")
+		}
+	}
+
+	if v, ok := context["@import"]; ok {
+		s, ok := v.(string)
+		if !ok {
+			return r.syntaxErr(ErrUnexpectedToken, "invalid @import value")
+		}
+		ref, err := url.Parse(s)
+		if err != nil {
+			return r.syntaxErr(ErrIllegalIRI, fmt.Sprintf("invalid @import value %q", s))
+		}
+		if base != nil {
+			ref = base.ResolveReference(ref)
+		} else if !ref.IsAbs() {
+			return r.syntaxErr(ErrNoBaseIRI, fmt.Sprintf("invalid @import value: relative reference %q without base IRI", s))
+		}
+		doc, err := r.loadRemote(ref.String())
+		if err != nil {
+			return err
+		}
+		m, ok := doc.(map[string]any)
+		if !ok {
+			return r.syntaxErr(ErrUnexpectedToken, "invalid remote context for @import")
+		}
+		imported, ok := m["@context"].(map[string]any)
+		if !ok {
+			return r.syntaxErr(ErrUnexpectedToken, "invalid remote context for @import")
+		}
+		if _, ok := imported["@import"]; ok {
+			return r.syntaxErr(ErrUnexpectedToken, "invalid context entry @import in imported context")
+		}
+		merged := maps.Clone(imported)
+		maps.Copy(merged, context)
+		delete(merged, "@import")
+		context = merged
+	}
+
+	if v, ok := context["@base"]; ok && len(remotes) == 0 {
+		switch v := v.(type) {
+		case nil:
+			result.base = nil
+		case string:
+			ref, err := url.Parse(v)
+			if err != nil {
+				return r.syntaxErr(ErrIllegalIRI, fmt.Sprintf("invalid base IRI %q", v))
+			}
+			switch {
+			case ref.IsAbs():
+				result.base = ref
+			case result.base != nil:
+				result.base = result.base.ResolveReference(ref)
+			default:
+				return r.syntaxErr(ErrNoBaseIRI, fmt.Sprintf("invalid base IRI: relative reference %q without base IRI", v))
+			}
+		default:
+			return r.syntaxErr(ErrUnexpectedToken, "invalid base IRI")
+		}
+	}
+
+	if v, ok := context["@vocab"]; ok {
+		switch v := v.(type) {
+		case nil:
+			result.vocab = ""
+		case string:
+			IRI, err := r.expandIRI(result, v, true, true, nil, nil)
+			if err != nil {
+				return err
+			}
+			if IRI != "" && !isAbsIRI(IRI) && !strings.HasPrefix(IRI, "_:") {
+				return r.syntaxErr(ErrIllegalIRI, fmt.Sprintf("invalid vocab mapping %q", v))
+			}
+			result.vocab = IRI
+		default:
+			return r.syntaxErr(ErrUnexpectedToken, "invalid vocab mapping")
+		}
+	}
+
+	if v, ok := context["@language"]; ok {
+		switch v := v.(type) {
+		case nil:
+			result.language = ""
+		case string:
+			result.language = v
+		default:
+			return r.syntaxErr(ErrUnexpectedToken, "invalid default language")
+		}
+	}
+
+	if v, ok := context["@direction"]; ok {
+		switch v {
+		case nil:
+			result.direction = ""
+		case "ltr", "rtl":
+			result.direction = v.(string)
+		default:
+			return r.syntaxErr(ErrUnexpectedToken, "invalid base direction")
+		}
+	}
+
+	defined := make(map[string]bool)
+	for _, term := range slices.Sorted(maps.Keys(context)) {
+		switch term {
+		case "@base", "@direction", "@import", "@language", "@propagate", "@protected", "@version", "@vocab":
+			continue
+		}
+		err := r.createTermDef(result, context, term, defined, base)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateTermDef adds the definition of term from local to active. The terms in
+// progress are false in defined, and the terms done are true.
+func (r *JSONLDReader) createTermDef(active *jsonLDContext, local map[string]any, term string, defined map[string]bool, base *url.URL) error {
+	if done, ok := defined[term]; ok {
+		if done {
+			return nil
+		}
+		return r.syntaxErr(ErrUnexpectedToken, fmt.Sprintf("cyclic IRI mapping of term %q", term))
+	}
+	if term == "" {
+		return r.syntaxErr(ErrUnexpectedToken, "invalid term definition of empty term")
+	}
+	defined[term] = false
+	defer func() { defined[term] = true }()
+
+	value := local[term]
+	if term == "@type" {
+		return nil // only @container @set and @protected permitted
+	}
+	if isJSONLDKeyword(term) {
+		return r.syntaxErr(ErrUnexpectedToken, fmt.Sprintf("keyword redefinition of %q", term))
+	}
+	if hasKeywordForm(term) {
+		return nil // ignored
+	}
+	delete(active.terms, term)
+
+	def := new(jsonLDTerm)
+	var m map[string]any
+	switch value := value.(type) {
+	case nil:
+		active.terms[term] = def // null mapping
+		return nil
+	case string:
+		m = map[string]any{"@id": value}
+	case map[string]any:
+		m = value
+	default:
+		return r.syntaxErr(ErrUnexpectedToken, fmt.Sprintf("invalid term definition of %q", term))
+	}
+	simpleTerm := false
+	if _, ok := value.(string); ok {
+		simpleTerm = true
+	}
+
+	if v, ok := m["@type"]; ok {
+		s, ok := v.(string)
+		if !ok {
+			return r.syntaxErr(ErrUnexpectedToken, fmt.Sprintf("invalid type mapping of term %q", term))
+		}
+		IRI, err := r.expandIRI(active, s, false, true, local, defined)
+		if err != nil {
+			return err
+		}
+		switch {
+		case IRI == "@id", IRI == "@vocab", IRI == "@json", IRI == "@none", isAbsIRI(IRI):
+			def.typ = IRI
+		default:
+			return r.syntaxErr(ErrUnexpectedToken, fmt.Sprintf("invalid type mapping %q of term %q", s, term))
+		}
+	}
+
+	if v, ok := m["@reverse"]; ok {
+		if _, ok := m["@id"]; ok {
+			return r.syntaxErr(ErrUnexpectedToken, fmt.Sprintf("invalid reverse property of term %q", term))
+		}
+		s, ok := v.(string)
+		if !ok {
+			return r.syntaxErr(ErrUnexpectedToken, fmt.Sprintf("invalid IRI mapping of term %q", term))
+		}
+		if hasKeywordForm(s) {
+			return nil // ignored
+		}
+		IRI, err := r.expandIRI(active, s, false, true, local, defined)
+		if err != nil {
+			return err
+		}
+		if !isAbsIRI(IRI) && !strings.HasPrefix(IRI, "_:") {
+			return r.syntaxErr(ErrIllegalIRI, fmt.Sprintf("invalid IRI mapping %q of term %q", s, term))
+		}
+		def.IRI = IRI
+		def.reverse = true
+	} else if v, ok := m["@id"]; ok && v != term {
+		switch v := v.(type) {
+		case nil:
+			break // null mapping
+		case string:
+			if !isJSONLDKeyword(v) && hasKeywordForm(v) {
+				return nil // ignored
+			}
+			IRI, err := r.expandIRI(active, v, false, true, local, defined)
+			if err != nil {
+				return err
+			}
+			if IRI == "@context" {
+				return r.syntaxErr(ErrUnexpectedToken, fmt.Sprintf("invalid keyword alias of term %q", term))
+			}
+			if !isJSONLDKeyword(IRI) && !isAbsIRI(IRI) && !strings.HasPrefix(IRI, "_:") {
+				return r.syntaxErr(ErrIllegalIRI, fmt.Sprintf("invalid IRI mapping %q of term %q", v, term))
+			}
+			def.IRI = IRI
+			if simpleTerm && !strings.ContainsAny(term, ":/") && IRI != "" {
+				def.prefix = strings.ContainsRune(":/?#[]@", rune(IRI[len(IRI)-1]))
+			}
+		default:
+			return r.syntaxErr(ErrUnexpectedToken, fmt.Sprintf("invalid IRI mapping of term %q", term))
+		}
+	} else if i := strings.IndexByte(term[1:], ':'); i >= 0 {
+		// compact IRI or absolute IRI as term
+		prefix, suffix := term[:i+1], term[i+2:]
+		if _, ok := local[prefix]; ok {
+			err := r.createTermDef(active, local, prefix, defined, base)
+			if err != nil {
+				return err
+			}
+		}
+		if t := active.terms[prefix]; t != nil && t.IRI != "" {
+			def.IRI = t.IRI + suffix
+		} else {
+			def.IRI = term
+		}
+	} else if strings.ContainsRune(term, '/') {
+		IRI, err := r.expandIRI(active, term, true, false, nil, nil)
+		if err != nil {
+			return err
+		}
+		if !isAbsIRI(IRI) {
+			return r.syntaxErr(ErrIllegalIRI, fmt.Sprintf("invalid IRI mapping of term %q", term))
+		}
+		def.IRI = IRI
+	} else if active.vocab != "" {
+		def.IRI = active.vocab + term
+	} else {
+		return r.syntaxErr(ErrNoBaseIRI, fmt.Sprintf("invalid IRI mapping of term %q without vocabulary mapping", term))
+	}
+
+	if v, ok := m["@container"]; ok {
+		for _, c := range asArray(v) {
+			s, ok := c.(string)
+			switch s {
+			case "@graph", "@id", "@index", "@language", "@list", "@set", "@type":
+				break
+			default:
+				ok = false
+			}
+			if !ok {
+				return r.syntaxErr(ErrUnexpectedToken, fmt.Sprintf("invalid container mapping of term %q", term))
+			}
+			def.container = append(def.container, s)
+		}
+		if def.reverse && (def.hasContainer("@list") || def.hasContainer("@language") || def.hasContainer("@graph")) {
+			return r.syntaxErr(ErrUnexpectedToken, fmt.Sprintf("invalid reverse property of term %q", term))
+		}
+	}
+
+	if v, ok := m["@context"]; ok {
+		def.context, def.hasContext = v, true
+		def.baseURL = base
+	}
+
+	if v, ok := m["@language"]; ok {
+		switch v := v.(type) {
+		case nil:
+			break // explicit no language
+		case string:
+			def.language = v
+		default:
+			return r.syntaxErr(ErrUnexpectedToken, fmt.Sprintf("invalid language mapping of term %q", term))
+		}
+		def.hasLanguage = true
+	}
+
+	if v, ok := m["@direction"]; ok {
+		switch v {
+		case nil:
+			break // explicit no direction
+		case "ltr", "rtl":
+			def.direction = v.(string)
+		default:
+			return r.syntaxErr(ErrUnexpectedToken, fmt.Sprintf("invalid base direction mapping of term %q", term))
+		}
+		def.hasDirection = true
+	}
+
+	if v, ok := m["@prefix"]; ok {
+		b, ok := v.(bool)
+		if !ok || strings.ContainsAny(term, ":/") {
+			return r.syntaxErr(ErrUnexpectedToken, fmt.Sprintf("invalid @prefix value of term %q", term))
+		}
+		def.prefix = b
+	}
+
+	active.terms[term] = def
+	return nil
+}
+
+// ExpandIRI resolves value in the active context, with zero for null. The local
+// context and its defined state are used for term definitions in progress.
+func (r *JSONLDReader) expandIRI(active *jsonLDContext, value string, documentRelative, vocab bool, local map[string]any, defined map[string]bool) (string, error) {
+	if isJSONLDKeyword(value) {
+		return value, nil
+	}
+	if hasKeywordForm(value) {
+		return "", nil // ignored
+	}
+
+	if _, ok := local[value]; ok && !defined[value] {
+		err := r.createTermDef(active, local, value, defined, nil)
+		if err != nil {
+			return "", err
+		}
+	}
+	if t, ok := active.terms[value]; ok && (vocab || isJSONLDKeyword(t.IRI)) {
+		return t.IRI, nil
+	}
+
+	if len(value) > 1 {
+		if i := strings.IndexByte(value[1:], ':'); i >= 0 {
+			prefix, suffix := value[:i+1], value[i+2:]
+			if prefix == "_" || strings.HasPrefix(suffix, "//") {
+				return value, nil // blank node or absolute IRI
+			}
+			if _, ok := local[prefix]; ok && !defined[prefix] {
+				err := r.createTermDef(active, local, prefix, defined, nil)
+				if err != nil {
+					return "", err
+				}
+			}
+			if t := active.terms[prefix]; t != nil && t.IRI != "" && t.prefix {
+				return t.IRI + suffix, nil
+			}
+			if isAbsIRI(value) {
+				return value, nil
+			}
+		}
+	}
+
+	if vocab && active.vocab != "" {
+		return active.vocab + value, nil
+	}
+	if documentRelative && active.base != nil {
+		ref, err := url.Parse(value)
+		if err != nil {
+			return value, nil // not well-formed; dropped on conversion
+		}
+		IRI := active.base.ResolveReference(ref).String()
+		if strings.HasSuffix(value, "#") && !strings.HasSuffix(IRI, "#") {
+			IRI += "#" // empty fragment lost in URL
+		}
+		return IRI, nil
+	}
+	return value, nil
+}
+
+// Expand applies the expansion algorithm on element, with zero for no active
+// property. The return is nil for null.
+func (r *JSONLDReader) expand(active *jsonLDContext, activeProp string, element any) (any, error) {
+	def := active.terms[activeProp]
+
+	switch element := element.(type) {
+	case nil:
+		return nil, nil
+
+	case []any:
+		var result []any
+		for _, item := range element {
+			e, err := r.expand(active, activeProp, item)
+			if err != nil {
+				return nil, err
+			}
+			if a, ok := e.([]any); ok && def.hasContainer("@list") {
+				e = map[string]any{"@list": a}
+			}
+			switch e := e.(type) {
+			case nil:
+				break
+			case []any:
+				result = append(result, e...)
+			default:
+				result = append(result, e)
+			}
+		}
+		if result == nil {
+			result = []any{}
+		}
+		return result, nil
+
+	case map[string]any:
+		return r.expandMap(active, activeProp, def, element)
+
+	default: // scalar
+		if activeProp == "" || activeProp == "@graph" {
+			return nil, nil // free-floating
+		}
+		if def != nil && def.hasContext {
+			var err error
+			active, err = r.processContext(active, def.context, def.baseURL, nil, true)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return r.expandValue(active, activeProp, element)
+	}
+}
+
+// ExpandMap is the part of expand for JSON objects.
+func (r *JSONLDReader) expandMap(active *jsonLDContext, activeProp string, def *jsonLDTerm, element map[string]any) (any, error) {
+	if active.previous != nil {
+		// revert type-scoped context, except for value objects and
+		// node references
+		revert := true
+		for key := range element {
+			IRI, err := r.expandIRI(active, key, false, true, nil, nil)
+			if err != nil {
+				return nil, err
+			}
+			if IRI == "@value" || IRI == "@id" && len(element) == 1 {
+				revert = false
+				break
+			}
+		}
+		if revert {
+			active = active.previous
+		}
+	}
+
+	var err error
+	if def != nil && def.hasContext {
+		active, err = r.processContext(active, def.context, def.baseURL, nil, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if c, ok := element["@context"]; ok {
+		active, err = r.processContext(active, c, r.BaseIRI, nil, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// type-scoped contexts apply in lexicographical order
+	typeScoped := active
+	var inputType string
+	for _, key := range slices.Sorted(maps.Keys(element)) {
+		IRI, err := r.expandIRI(active, key, false, true, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		if IRI != "@type" {
+			continue
+		}
+		var terms []string
+		for _, v := range asArray(element[key]) {
+			if s, ok := v.(string); ok {
+				terms = append(terms, s)
+			}
+		}
+		slices.Sort(terms)
+		for _, term := range terms {
+			if t := typeScoped.terms[term]; t != nil && t.hasContext {
+				active, err = r.processContext(active, t.context, t.baseURL, nil, false)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+		if len(terms) != 0 {
+			inputType, err = r.expandIRI(active, terms[len(terms)-1], true, true, nil, nil)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	result := make(map[string]any)
+	err = r.expandObject(active, typeScoped, activeProp, element, result, inputType)
+	if err != nil {
+		return nil, err
+	}
+
+	if v, ok := result["@value"]; ok {
+		for key := range result {
+			switch key {
+			case "@direction", "@index", "@language", "@type", "@value":
+				continue
+			}
+			return nil, r.syntaxErr(ErrUnexpectedToken, fmt.Sprintf("invalid value object with %s", key))
+		}
+		typ, hasType := result["@type"]
+		if typ == "@json" {
+			return result, nil
+		}
+		if v == nil {
+			return nil, nil
+		}
+		_, hasLanguage := result["@language"]
+		_, hasDirection := result["@direction"]
+		if _, ok := v.(string); (hasLanguage || hasDirection) && !ok {
+			return nil, r.syntaxErr(ErrUnexpectedToken, "invalid language-tagged value")
+		}
+		if hasType {
+			s, ok := typ.(string)
+			if !ok || !isAbsIRI(s) || hasLanguage || hasDirection {
+				return nil, r.syntaxErr(ErrUnexpectedToken, "invalid typed value")
+			}
+		}
+	} else if typ, ok := result["@type"]; ok {
+		result["@type"] = asArray(typ)
+	}
+
+	_, hasSet := result["@set"]
+	_, hasList := result["@list"]
+	if hasSet || hasList {
+		_, hasIndex := result["@index"]
+		if len(result) > 2 || len(result) == 2 && !hasIndex {
+			return nil, r.syntaxErr(ErrUnexpectedToken, "invalid set or list object")
+		}
+		if hasSet {
+			return result["@set"], nil
+		}
+	}
+
+	if _, ok := result["@language"]; ok && len(result) == 1 {
+		return nil, nil
+	}
+	if activeProp == "" || activeProp == "@graph" {
+		_, hasValue := result["@value"]
+		_, hasID := result["@id"]
+		if len(result) == 0 || hasValue || hasList || len(result) == 1 && hasID {
+			return nil, nil // free-floating
+		}
+	}
+	return result, nil
+}
+
+// ExpandObject adds the expansion of each entry in element to result.
+func (r *JSONLDReader) expandObject(active, typeScoped *jsonLDContext, activeProp string, element, result map[string]any, inputType string) error {
+	var nests []string
+	for _, key := range slices.Sorted(maps.Keys(element)) {
+		value := element[key]
+		if key == "@context" {
+			continue
+		}
+		prop, err := r.expandIRI(active, key, false, true, nil, nil)
+		if err != nil {
+			return err
+		}
+		if prop == "" || !strings.ContainsRune(prop, ':') && !isJSONLDKeyword(prop) {
+			continue // dropped
+		}
+
+		if isJSONLDKeyword(prop) {
+			if activeProp == "@reverse" {
+				return r.syntaxErr(ErrUnexpectedToken, "invalid reverse property map")
+			}
+			if _, ok := result[prop]; ok && prop != "@included" && prop != "@type" {
+				return r.syntaxErr(ErrUnexpectedToken, fmt.Sprintf("colliding keywords %s", prop))
+			}
+
+			var expanded any
+			switch prop {
+			case "@id":
+				s, ok := value.(string)
+				if !ok {
+					return r.syntaxErr(ErrUnexpectedToken, "invalid @id value")
+				}
+				expanded, err = r.expandIRI(active, s, true, false, nil, nil)
+
+			case "@type":
+				var types []any
+				for _, v := range asArray(value) {
+					s, ok := v.(string)
+					if !ok {
+						return r.syntaxErr(ErrUnexpectedToken, "invalid type value")
+					}
+					IRI, err := r.expandIRI(typeScoped, s, true, true, nil, nil)
+					if err != nil {
+						return err
+					}
+					types = append(types, IRI)
+				}
+				if _, ok := value.(string); ok {
+					expanded = types[0]
+				} else {
+					expanded = types
+				}
+				if existing, ok := result["@type"]; ok {
+					expanded = append(asArray(existing), types...)
+				}
+
+			case "@graph":
+				e, err := r.expand(active, "@graph", value)
+				if err != nil {
+					return err
+				}
+				expanded = asArray(e)
+
+			case "@included":
+				e, err := r.expand(active, "", value)
+				if err != nil {
+					return err
+				}
+				for _, item := range asArray(e) {
+					m, ok := item.(map[string]any)
+					if !ok || m["@value"] != nil || m["@list"] != nil {
+						return r.syntaxErr(ErrUnexpectedToken, "invalid @included value")
+					}
+				}
+				expanded = append(asArray(result["@included"]), asArray(e)...)
+
+			case "@value":
+				switch value.(type) {
+				case nil, string, bool, json.Number:
+					break
+				default:
+					if inputType != "@json" {
+						return r.syntaxErr(ErrUnexpectedToken, "invalid value object value")
+					}
+				}
+				result["@value"] = value
+				continue
+
+			case "@language":
+				s, ok := value.(string)
+				if !ok {
+					return r.syntaxErr(ErrUnexpectedToken, "invalid language-tagged string")
+				}
+				expanded = s
+
+			case "@direction":
+				if value != "ltr" && value != "rtl" {
+					return r.syntaxErr(ErrUnexpectedToken, "invalid base direction")
+				}
+				expanded = value
+
+			case "@index":
+				s, ok := value.(string)
+				if !ok {
+					return r.syntaxErr(ErrUnexpectedToken, "invalid @index value")
+				}
+				expanded = s
+
+			case "@list":
+				if activeProp == "" || activeProp == "@graph" {
+					continue
+				}
+				e, err := r.expand(active, activeProp, value)
+				if err != nil {
+					return err
+				}
+				expanded = asArray(e)
+				if expanded == nil {
+					expanded = []any{}
+				}
+
+			case "@set":
+				expanded, err = r.expand(active, activeProp, value)
+
+			case "@reverse":
+				m, ok := value.(map[string]any)
+				if !ok {
+					return r.syntaxErr(ErrUnexpectedToken, "invalid @reverse value")
+				}
+				e, err := r.expand(active, "@reverse", m)
+				if err != nil {
+					return err
+				}
+				em, _ := e.(map[string]any)
+				if rev, ok := em["@reverse"].(map[string]any); ok {
+					for p, items := range rev {
+						result[p] = append(asArray(result[p]), asArray(items)...)
+					}
+				}
+				for p, items := range em {
+					if p == "@reverse" {
+						continue
+					}
+					err = r.addReverse(result, p, items)
+					if err != nil {
+						return err
+					}
+				}
+				continue
+
+			case "@nest":
+				nests = append(nests, key)
+				continue
+
+			default:
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			result[prop] = expanded
+			continue
+		}
+
+		def := active.terms[key]
+		var expanded any
+		m, isMap := value.(map[string]any)
+		switch {
+		case def != nil && def.typ == "@json":
+			expanded = map[string]any{"@value": value, "@type": "@json"}
+
+		case def.hasContainer("@language") && isMap:
+			direction := active.direction
+			if def.hasDirection {
+				direction = def.direction
+			}
+			var items []any
+			for _, lang := range slices.Sorted(maps.Keys(m)) {
+				IRI, err := r.expandIRI(active, lang, false, true, nil, nil)
+				if err != nil {
+					return err
+				}
+				for _, v := range asArray(m[lang]) {
+					s, ok := v.(string)
+					if !ok {
+						return r.syntaxErr(ErrUnexpectedToken, "invalid language map value")
+					}
+					item := map[string]any{"@value": s}
+					if lang != "@none" && IRI != "@none" {
+						item["@language"] = lang
+					}
+					if direction != "" {
+						item["@direction"] = direction
+					}
+					items = append(items, item)
+				}
+			}
+			expanded = items
+
+		case isMap && (def.hasContainer("@index") || def.hasContainer("@id") || def.hasContainer("@type")):
+			expanded, err = r.expandIndexMap(active, key, def, m)
+
+		default:
+			expanded, err = r.expand(active, key, value)
+		}
+		if err != nil {
+			return err
+		}
+		if expanded == nil {
+			continue
+		}
+
+		if def.hasContainer("@list") {
+			if m, ok := expanded.(map[string]any); !ok || m["@list"] == nil {
+				expanded = map[string]any{"@list": asArray(expanded)}
+			}
+		}
+		if def.hasContainer("@graph") && !def.hasContainer("@id") && !def.hasContainer("@index") {
+			var graphs []any
+			for _, item := range asArray(expanded) {
+				graphs = append(graphs, map[string]any{"@graph": asArray(item)})
+			}
+			expanded = graphs
+		}
+
+		if def != nil && def.reverse {
+			err = r.addReverse(result, prop, expanded)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		result[prop] = append(asArray(result[prop]), asArray(expanded)...)
+	}
+
+	for _, key := range nests {
+		for _, v := range asArray(element[key]) {
+			m, ok := v.(map[string]any)
+			if !ok {
+				return r.syntaxErr(ErrUnexpectedToken, "invalid @nest value")
+			}
+			for k := range m {
+				IRI, err := r.expandIRI(active, k, false, true, nil, nil)
+				if err != nil {
+					return err
+				}
+				if IRI == "@value" {
+					return r.syntaxErr(ErrUnexpectedToken, "invalid @nest value")
+				}
+			}
+			err := r.expandObject(active, typeScoped, activeProp, m, result, inputType)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ExpandIndexMap expands the value of key with an index, an id or a type map
+// as container.
+func (r *JSONLDReader) expandIndexMap(active *jsonLDContext, key string, def *jsonLDTerm, m map[string]any) (any, error) {
+	var items []any
+	for _, index := range slices.Sorted(maps.Keys(m)) {
+		mapContext := active
+		if def.hasContainer("@type") {
+			if t := active.terms[index]; t != nil && t.hasContext {
+				var err error
+				mapContext, err = r.processContext(active, t.context, t.baseURL, nil, true)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+		expandedIndex, err := r.expandIRI(active, index, false, true, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := r.expand(mapContext, key, m[index])
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range asArray(e) {
+			if def.hasContainer("@graph") {
+				if im, ok := item.(map[string]any); !ok || im["@graph"] == nil {
+					item = map[string]any{"@graph": asArray(item)}
+				}
+			}
+			im, ok := item.(map[string]any)
+			if !ok {
+				return nil, r.syntaxErr(ErrUnexpectedToken, "invalid index map value")
+			}
+			if expandedIndex != "@none" {
+				switch {
+				case def.hasContainer("@index"):
+					if _, ok := im["@index"]; !ok {
+						im["@index"] = index
+					}
+				case def.hasContainer("@id"):
+					if _, ok := im["@id"]; !ok {
+						im["@id"], err = r.expandIRI(active, index, true, false, nil, nil)
+						if err != nil {
+							return nil, err
+						}
+					}
+				case def.hasContainer("@type"):
+					typ, err := r.expandIRI(active, index, true, true, nil, nil)
+					if err != nil {
+						return nil, err
+					}
+					im["@type"] = append([]any{typ}, asArray(im["@type"])...)
+				}
+			}
+			items = append(items, im)
+		}
+	}
+	return items, nil
+}
+
+// AddReverse adds the expanded items as reverse property prop to result.
+func (r *JSONLDReader) addReverse(result map[string]any, prop string, items any) error {
+	reverseMap, _ := result["@reverse"].(map[string]any)
+	if reverseMap == nil {
+		reverseMap = make(map[string]any)
+		result["@reverse"] = reverseMap
+	}
+	for _, item := range asArray(items) {
+		m, ok := item.(map[string]any)
+		if !ok || m["@value"] != nil || m["@list"] != nil {
+			return r.syntaxErr(ErrUnexpectedToken, "invalid reverse property value")
+		}
+		reverseMap[prop] = append(asArray(reverseMap[prop]), m)
+	}
+	return nil
+}
+
+// ExpandValue applies the value expansion algorithm on a scalar.
+func (r *JSONLDReader) expandValue(active *jsonLDContext, activeProp string, value any) (any, error) {
+	def := active.terms[activeProp]
+	if s, ok := value.(string); ok && def != nil {
+		switch def.typ {
+		case "@id":
+			IRI, err := r.expandIRI(active, s, true, false, nil, nil)
+			return map[string]any{"@id": IRI}, err
+		case "@vocab":
+			IRI, err := r.expandIRI(active, s, true, true, nil, nil)
+			return map[string]any{"@id": IRI}, err
+		}
+	}
+
+	result := map[string]any{"@value": value}
+	switch {
+	case def != nil && def.typ != "" && def.typ != "@id" && def.typ != "@vocab" && def.typ != "@none":
+		result["@type"] = def.typ
+	case isString(value):
+		language := active.language
+		if def != nil && def.hasLanguage {
+			language = def.language
+		}
+		if language != "" {
+			result["@language"] = language
+		}
+		direction := active.direction
+		if def != nil && def.hasDirection {
+			direction = def.direction
+		}
+		if direction != "" {
+			result["@direction"] = direction
+		}
+	}
+	return result, nil
+}
+
+// IsString returns whether v is a JSON string.
+func isString(v any) bool {
+	_, ok := v.(string)
+	return ok
+}
+
+// ToRDF applies the expansion algorithm, and it converts the result.
+func (r *JSONLDReader) toRDF(doc any) error {
+	expanded, err := r.expand(r.initialContext(), "", doc)
+	if err != nil {
+		return err
+	}
+	if m, ok := expanded.(map[string]any); ok && len(m) == 1 && m["@graph"] != nil {
+		expanded = m["@graph"]
+	}
+
+	for _, item := range asArray(expanded) {
+		if node, ok := item.(map[string]any); ok {
+			r.nodeToRDF(node, "")
+		}
+	}
+	return nil
+}
+
+// AddQuad registers a statement, with duplicate omission.
+func (r *JSONLDReader) addQuad(t Triple, graph string) {
+	q := Quad{Triple: t, GraphIRI: graph}
+	if _, ok := r.seen[q]; ok {
+		return
+	}
+	if r.seen == nil {
+		r.seen = make(map[Quad]struct{})
+	}
+	r.seen[q] = struct{}{}
+	r.quads = append(r.quads, q)
+}
+
+// NodeIRI returns the IRI of an @id value, with Skolem IRIs for blank nodes.
+// The return is false for relative IRI references.
+func (r *JSONLDReader) nodeIRI(id string) (IRI string, ok bool) {
+	if label, ok := strings.CutPrefix(id, "_:"); ok {
+		return r.blankIRI(label), true
+	}
+	return id, isAbsIRI(id)
+}
+
+// NodeToRDF adds the statements of node in graph, with nested nodes before the
+// enclosing node. The return is false when node has no usable IRI.
+func (r *JSONLDReader) nodeToRDF(node map[string]any, graph string) (subject string, ok bool) {
+	if id, isString := node["@id"].(string); isString {
+		subject, ok = r.nodeIRI(id)
+		if !ok {
+			return "", false
+		}
+	} else {
+		subject = r.newAnonIRI()
+	}
+
+	for _, v := range asArray(node["@type"]) {
+		s, _ := v.(string)
+		if typ, ok := r.nodeIRI(s); ok {
+			r.addQuad(Triple{SubjectIRI: subject, PredicateIRI: rdfType, Object: typ}, graph)
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(node)) {
+		switch key {
+		case "@graph":
+			for _, item := range asArray(node[key]) {
+				if m, ok := item.(map[string]any); ok {
+					r.nodeToRDF(m, subject)
+				}
+			}
+
+		case "@included":
+			for _, item := range asArray(node[key]) {
+				if m, ok := item.(map[string]any); ok {
+					r.nodeToRDF(m, graph)
+				}
+			}
+
+		case "@reverse":
+			reverseMap, _ := node[key].(map[string]any)
+			for _, prop := range slices.Sorted(maps.Keys(reverseMap)) {
+				if !isAbsIRI(prop) {
+					continue
+				}
+				for _, item := range asArray(reverseMap[prop]) {
+					m, _ := item.(map[string]any)
+					if IRI, ok := r.nodeToRDF(m, graph); ok {
+						r.addQuad(Triple{SubjectIRI: IRI, PredicateIRI: prop, Object: subject}, graph)
+					}
+				}
+			}
+
+		default:
+			if !isAbsIRI(key) {
+				continue // keyword, or generalized RDF
+			}
+			for _, item := range asArray(node[key]) {
+				t := Triple{SubjectIRI: subject, PredicateIRI: key}
+				if r.objectToRDF(item, &t, graph) {
+					r.addQuad(t, graph)
+				}
+			}
+		}
+	}
+	return subject, true
+}
+
+// ObjectToRDF sets the object of t to item. The return is false when item has
+// no RDF representation.
+func (r *JSONLDReader) objectToRDF(item any, t *Triple, graph string) bool {
+	m, ok := item.(map[string]any)
+	if !ok {
+		return false
+	}
+	if v, ok := m["@value"]; ok {
+		return r.literalToRDF(v, m, t)
+	}
+	if l, ok := m["@list"]; ok {
+		t.Object = r.listToRDF(asArray(l), graph)
+		return true
+	}
+	t.Object, ok = r.nodeToRDF(m, graph)
+	return ok
+}
+
+// ListToRDF adds the statements of a list, and it returns the IRI of the first
+// cell, or rdf:nil for the empty list.
+func (r *JSONLDReader) listToRDF(items []any, graph string) (firstIRI string) {
+	if len(items) == 0 {
+		return rdfNil
+	}
+	firstIRI = r.newAnonIRI()
+	cellIRI := firstIRI
+	for i, item := range items {
+		t := Triple{SubjectIRI: cellIRI, PredicateIRI: rdfFirst}
+		if r.objectToRDF(item, &t, graph) {
+			r.addQuad(t, graph)
+		}
+
+		nextIRI := rdfNil
+		if i+1 < len(items) {
+			nextIRI = r.newAnonIRI()
+		}
+		r.addQuad(Triple{SubjectIRI: cellIRI, PredicateIRI: rdfRest, Object: nextIRI}, graph)
+		cellIRI = nextIRI
+	}
+	return firstIRI
+}
+
+// LiteralToRDF sets the object of t to the value object m, with v as its
+// @value.
+func (r *JSONLDReader) literalToRDF(v any, m map[string]any, t *Triple) bool {
+	datatype, _ := m["@type"].(string)
+	if datatype == "@json" {
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		if enc.Encode(v) != nil {
+			return false
+		}
+		t.Object = strings.TrimSuffix(buf.String(), "\n")
+		t.DatatypeIRI = rdfJSON
+		return true
+	}
+
+	switch v := v.(type) {
+	case bool:
+		t.Object = strconv.FormatBool(v)
+		if datatype == "" {
+			datatype = XSDBoolean
+		}
+
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil && !math.IsInf(f, 0) {
+			return false
+		}
+		if f != math.Trunc(f) || datatype == XSDDouble || math.Abs(f) >= 1e21 {
+			t.Object = canonicalDouble(f)
+			if datatype == "" {
+				datatype = XSDDouble
+			}
+		} else {
+			if i, err := v.Int64(); err == nil {
+				t.Object = strconv.FormatInt(i, 10)
+			} else {
+				t.Object = strconv.FormatFloat(f, 'f', 0, 64)
+			}
+			if datatype == "" {
+				datatype = XSDInteger
+			}
+		}
+
+	case string:
+		t.Object = v
+		if lang, ok := m["@language"].(string); ok {
+			t.DatatypeIRI = rdfLangString
+			t.LangTag = strings.ToLower(lang)
+			if dir, ok := m["@direction"].(string); ok {
+				t.DatatypeIRI = rdfDirLangString
+				t.BaseDir = dir
+			}
+			return true
+		}
+		if datatype == "" {
+			datatype = XSDString
+		}
+
+	default:
+		return false
+	}
+
+	if _, ok := r.nodeIRI(datatype); !ok {
+		return false
+	}
+	t.DatatypeIRI = datatype
+	return true
+}
+
+// CanonicalDouble returns the canonical lexical representation of xsd:double,
+// as in "1.0E0".
+func canonicalDouble(f float64) string {
+	s := strconv.FormatFloat(f, 'E', -1, 64)
+	mantissa, exp, _ := strings.Cut(s, "E")
+	if !strings.ContainsRune(mantissa, '.') {
+		mantissa += ".0"
+	}
+	n, _ := strconv.Atoi(exp)
+	return mantissa + "E" + strconv.Itoa(n)
+}
@@ -0,0 +1,283 @@
+package tripn
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+var jsonLDQuads = []struct {
+	jsonLD string
+	quads  []Quad
+}{
+	{`{}`, []Quad{}},
+	{`[]`, []Quad{}},
+
+	// JSON-LD 1.1 Recommendation, example 2 with inline context
+	{`{
+  "@context": {
+    "name": "http://schema.org/name",
+    "image": {
+      "@id": "http://schema.org/image",
+      "@type": "@id"
+    },
+    "homepage": {
+      "@id": "http://schema.org/url",
+      "@type": "@id"
+    }
+  },
+  "@id": "http://me.markus-lanthaler.com/",
+  "name": "Manu Sporny",
+  "homepage": "http://manu.sporny.org/",
+  "image": "http://manu.sporny.org/images/manu.png",
+  "undefined": "dropped"
+}`,
+		[]Quad{
+			{Triple{"http://me.markus-lanthaler.com/", "http://schema.org/image", "http://manu.sporny.org/images/manu.png", "", "", ""}, ""},
+			{Triple{"http://me.markus-lanthaler.com/", "http://schema.org/name", "Manu Sporny", XSDString, "", ""}, ""},
+			{Triple{"http://me.markus-lanthaler.com/", "http://schema.org/url", "http://manu.sporny.org/", "", "", ""}, ""},
+		},
+	},
+
+	// vocabulary mapping, compact IRIs, types and native literals
+	{`{
+  "@context": {
+    "@vocab": "http://example.com/vocab#",
+    "xsd": "http://www.w3.org/2001/XMLSchema#",
+    "born": {"@type": "xsd:date"}
+  },
+  "@id": "_:b0",
+  "@type": ["Person", "xsd:anyType"],
+  "born": "1970-01-01",
+  "age": 42,
+  "height": 1.8,
+  "big": 1e21,
+  "alive": true,
+  "nothing": null
+}`,
+		[]Quad{
+			{Triple{"http://example.com/skolem-stub/blank#b0", rdfType, "http://example.com/vocab#Person", "", "", ""}, ""},
+			{Triple{"http://example.com/skolem-stub/blank#b0", rdfType, "http://www.w3.org/2001/XMLSchema#anyType", "", "", ""}, ""},
+			{Triple{"http://example.com/skolem-stub/blank#b0", "http://example.com/vocab#age", "42", XSDInteger, "", ""}, ""},
+			{Triple{"http://example.com/skolem-stub/blank#b0", "http://example.com/vocab#alive", "true", XSDBoolean, "", ""}, ""},
+			{Triple{"http://example.com/skolem-stub/blank#b0", "http://example.com/vocab#big", "1.0E21", XSDDouble, "", ""}, ""},
+			{Triple{"http://example.com/skolem-stub/blank#b0", "http://example.com/vocab#born", "1970-01-01", "http://www.w3.org/2001/XMLSchema#date", "", ""}, ""},
+			{Triple{"http://example.com/skolem-stub/blank#b0", "http://example.com/vocab#height", "1.8E0", XSDDouble, "", ""}, ""},
+		},
+	},
+
+	// language, language maps, lists, reverse properties and relative IRIs
+	{`{
+  "@context": {
+    "@base": "http://example.com/dir/",
+    "@language": "EN",
+    "ex": "http://example.com/ns#",
+    "label": {"@id": "ex:label", "@container": "@language"},
+    "parts": {"@id": "ex:parts", "@container": "@list"},
+    "children": {"@reverse": "ex:parent"},
+    "code": {"@id": "ex:code", "@language": null}
+  },
+  "@id": "node",
+  "ex:title": "Hello",
+  "code": "x1",
+  "label": {"nl": "Hallo", "@none": "Hi"},
+  "parts": ["a", {"@id": "#b"}],
+  "ex:empty": {"@list": []},
+  "children": {"@id": "../kid"}
+}`,
+		[]Quad{
+			{Triple{"http://example.com/kid", "http://example.com/ns#parent", "http://example.com/dir/node", "", "", ""}, ""},
+			{Triple{"http://example.com/dir/node", "http://example.com/ns#code", "x1", XSDString, "", ""}, ""},
+			{Triple{"http://example.com/dir/node", "http://example.com/ns#empty", rdfNil, "", "", ""}, ""},
+			{Triple{"http://example.com/dir/node", "http://example.com/ns#label", "Hi", XSDString, "", ""}, ""},
+			{Triple{"http://example.com/dir/node", "http://example.com/ns#label", "Hallo", rdfLangString, "nl", ""}, ""},
+			{Triple{"http://example.com/skolem-stub/anon#1", rdfFirst, "a", rdfLangString, "en", ""}, ""},
+			{Triple{"http://example.com/skolem-stub/anon#1", rdfRest, "http://example.com/skolem-stub/anon#2", "", "", ""}, ""},
+			{Triple{"http://example.com/skolem-stub/anon#2", rdfFirst, "http://example.com/dir/#b", "", "", ""}, ""},
+			{Triple{"http://example.com/skolem-stub/anon#2", rdfRest, rdfNil, "", "", ""}, ""},
+			{Triple{"http://example.com/dir/node", "http://example.com/ns#parts", "http://example.com/skolem-stub/anon#1", "", "", ""}, ""},
+			{Triple{"http://example.com/dir/node", "http://example.com/ns#title", "Hello", rdfLangString, "en", ""}, ""},
+		},
+	},
+
+	// base direction
+	{`{
+  "@context": {
+    "@vocab": "http://example.com/",
+    "@language": "ar",
+    "@direction": "rtl",
+    "name": {"@direction": null},
+    "title": {"@container": "@language", "@direction": "ltr"}
+  },
+  "@id": "http://example.com/s",
+  "label": "مرحبا",
+  "name": "Ahmad",
+  "title": {"en": "Hello"},
+  "note": {"@value": "x", "@direction": "ltr"}
+}`,
+		[]Quad{
+			{Triple{"http://example.com/s", "http://example.com/label", "مرحبا", rdfDirLangString, "ar", "rtl"}, ""},
+			{Triple{"http://example.com/s", "http://example.com/name", "Ahmad", rdfLangString, "ar", ""}, ""},
+			{Triple{"http://example.com/s", "http://example.com/note", "x", XSDString, "", ""}, ""},
+			{Triple{"http://example.com/s", "http://example.com/title", "Hello", rdfDirLangString, "en", "ltr"}, ""},
+		},
+	},
+
+	// named graphs, nested nodes, JSON literals and duplicates
+	{`{
+  "@context": {
+    "ex": "http://example.com/",
+    "data": {"@id": "ex:data", "@type": "@json"}
+  },
+  "@graph": [
+    {"@id": "ex:s", "ex:p": {"ex:q": "nested"}},
+    {"@id": "ex:s", "ex:p": {"@id": "ex:o"}},
+    {"@id": "ex:s", "ex:p": {"@id": "ex:o"}},
+    {
+      "@id": "ex:g",
+      "@graph": {"@id": "ex:s", "data": {"b": [1, true], "a": null}}
+    }
+  ]
+}`,
+		[]Quad{
+			{Triple{"http://example.com/skolem-stub/anon#1", "http://example.com/q", "nested", XSDString, "", ""}, ""},
+			{Triple{"http://example.com/s", "http://example.com/p", "http://example.com/skolem-stub/anon#1", "", "", ""}, ""},
+			{Triple{"http://example.com/s", "http://example.com/p", "http://example.com/o", "", "", ""}, ""},
+			{Triple{"http://example.com/s", "http://example.com/data", `{"a":null,"b":[1,true]}`, rdfJSON, "", ""}, "http://example.com/g"},
+		},
+	},
+}
+
+func TestJSONLDReader(t *testing.T) {
+	for _, test := range jsonLDQuads {
+		r := JSONLDReader{
+			R:          strings.NewReader(test.jsonLD),
+			skolemizer: skolemStub,
+		}
+
+		got := []Quad{}
+		var err error
+		for err == nil {
+			got, err = r.ReadAppend(got)
+		}
+		if err != io.EOF {
+			t.Errorf("got error %v, for JSON-LD:\n%s", err, test.jsonLD)
+			continue
+		}
+		if !slices.Equal(got, test.quads) {
+			msg := "got quads:"
+			for _, q := range got {
+				msg += "\n\t" + q.String()
+			}
+			msg += "\nwant quads:"
+			for _, q := range test.quads {
+				msg += "\n\t" + q.String()
+			}
+			t.Error(msg, "\nfor JSON-LD:\n", test.jsonLD)
+		}
+	}
+}
+
+func TestJSONLDReaderFSLoader(t *testing.T) {
+	loader := FSLoader{fstest.MapFS{
+		"example.com/context.jsonld": {Data: []byte(`{"@context": {
+			"@vocab": "http://example.com/vocab#",
+			"knows": {"@type": "@id"}
+		}}`)},
+		"example.com/index.jsonld": {Data: []byte(`{"@context": {"@import": "context.jsonld", "@language": "nl"}}`)},
+	}}
+	base, err := url.Parse("http://example.com/data")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := JSONLDReader{
+		R:       strings.NewReader(`{"@context": ["http://example.com", "context.jsonld"], "@id": "me", "knows": "you", "name": "ik"}`),
+		BaseIRI: base,
+		Loader:  loader,
+	}
+	got, err := r.ReadAppend(nil)
+	if err != nil {
+		t.Fatal("read error:", err)
+	}
+	want := []Quad{
+		{Triple{"http://example.com/me", "http://example.com/vocab#knows", "http://example.com/you", "", "", ""}, ""},
+		{Triple{"http://example.com/me", "http://example.com/vocab#name", "ik", rdfLangString, "nl", ""}, ""},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("got quads %q, want %q", got, want)
+	}
+
+	r = JSONLDReader{
+		R:       strings.NewReader(`{"@context": "http://example.com/absent.jsonld"}`),
+		BaseIRI: base,
+		Loader:  loader,
+	}
+	_, err = r.ReadAppend(nil)
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("got error %v, want fs.ErrNotExist", err)
+	}
+}
+
+var jsonLDSyntaxErrors = []struct {
+	jsonLD string
+	reason string
+	kind   error
+}{
+	{`{"@context": {"a": "b:x", "b": "a:y"}}`,
+		`cyclic IRI mapping of term "a"`, ErrUnexpectedToken},
+	{`{"@context": {"@id": "http://example.com/"}}`,
+		`keyword redefinition of "@id"`, ErrUnexpectedToken},
+	{`{"@context": {"p": "relative"}}`,
+		`invalid IRI mapping "relative" of term "p"`, ErrIllegalIRI},
+	{`{"@context": {"p": {"@id": "http://example.com/p", "@container": "@bag"}}}`,
+		`invalid container mapping of term "p"`, ErrUnexpectedToken},
+	{`{"@id": 42}`,
+		"invalid @id value", ErrUnexpectedToken},
+	{`{"http://example.com/p": {"@value": "x", "@language": "en", "@type": "http://example.com/t"}}`,
+		"invalid typed value", ErrUnexpectedToken},
+	{`{"http://example.com/p": {"@value": "x", "http://example.com/q": "y"}}`,
+		"invalid value object with http://example.com/q", ErrUnexpectedToken},
+	{`{"@context": 42}`,
+		"invalid local context", ErrUnexpectedToken},
+	{`{"a": }`,
+		"invalid character '}' looking for beginning of value", ErrMalformedJSON},
+	{`{} {}`,
+		"data after top-level value", ErrMalformedJSON},
+}
+
+func TestJSONLDReaderSyntaxErrors(t *testing.T) {
+	for _, test := range jsonLDSyntaxErrors {
+		r := JSONLDReader{R: strings.NewReader(test.jsonLD)}
+
+		_, err := r.ReadAppend(nil)
+		var e *SyntaxError
+		if !errors.As(err, &e) {
+			t.Errorf("got error %v, want a *SyntaxError, for JSON-LD:\n%s", err, test.jsonLD)
+			continue
+		}
+		if e.Reason != test.reason {
+			t.Errorf("got reason %q, want %q, for JSON-LD:\n%s", e.Reason, test.reason, test.jsonLD)
+		}
+		if !errors.Is(err, test.kind) {
+			t.Errorf("got error %v, want kind %q, for JSON-LD:\n%s", err, test.kind, test.jsonLD)
+		}
+		if !strings.HasPrefix(e.Error(), "JSON-LD syntax violation: ") {
+			t.Errorf("got error message %q, want JSON-LD without line", e.Error())
+		}
+	}
+}
+
+func TestJSONLDReaderNoLoader(t *testing.T) {
+	r := JSONLDReader{R: strings.NewReader(`{"@context": "http://example.com/context.jsonld"}`)}
+	_, err := r.ReadAppend(nil)
+	const want = `JSON-LD remote context "http://example.com/context.jsonld": no Loader`
+	if err == nil || err.Error() != want {
+		t.Errorf("got error %v, want %q", err, want)
+	}
+}
@@ -5,326 +5,197 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"unicode/utf8"
 )
 
 // InNumberWithSign continues from a "+" or "-" in the buffer iff signOffset is 1.
-// Otherwise a the start must be a decimal ("0".."9") instead.
+// Otherwise a the start must be a decimal ("0".."9") or a "." instead.
 func (r *Reader) inNumberWithSign(line []byte, signOffset int, t *Triple) (remainder []byte, err error) {
-	i := 1
-	for {
+	i := decimalsEnd(line, signOffset)
+	intDigits := i - signOffset
+	t.DatatypeIRI = XSDInteger
+
+	if i < len(line) && line[i] == '.' {
+		end := decimalsEnd(line, i+1)
 		switch {
-		case i >= len(line):
-			return nil, io.ErrUnexpectedEOF
-		case line[i] >= '0' && line[i] <= '9':
-			continue
+		case end > i+1:
+			t.DatatypeIRI = XSDDecimal
+			i = end
+		case intDigits != 0 && end < len(line) && (line[end] == 'E' || line[end] == 'e'):
+			i = end // double like "1.E3"
 		}
-		break // not a decimal
 	}
-
-	switch line[i] {
-	case ' ', '\t', '\r', '\n': // xsd:integer ended on WS
-		if i-signOffset == 0 {
-			return nil, r.syntaxErr("sign without number")
-		}
-		t.DatatypeIRI = XSDInteger
-		t.Object = string(line[:i])
-		return line[i+1:], nil
-
-	case '.':
-		for {
-			i++
-			if i >= len(line) {
-				return nil, io.ErrUnexpectedEOF
-			}
-
-			switch line[i] {
-			case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
-				continue
-
-			default:
-				return nil, r.syntaxErr("illegal character in fraction")
-
-			case ' ', '\t', '\r', '\n': // xsd:decimal ended on WS
-				if line[i-1] == '.' {
-					return nil, r.syntaxErr("decimal with empty fraction")
-				}
-				t.DatatypeIRI = XSDDecimal
-				t.Object = string(line[:i])
-				return line[i+1:], nil
-
-			case 'E', 'e':
-				break
-			}
-			break
+	if intDigits == 0 && t.DatatypeIRI == XSDInteger {
+		if signOffset != 0 {
+			return nil, r.syntaxErr(ErrUnexpectedToken, line, "sign without number")
 		}
-
-	case 'E', 'e':
-		break
-
-	default:
-		return nil, r.syntaxErr("illegal character in number")
+		return nil, r.syntaxErr(ErrUnexpectedToken, line, "number without decimals")
 	}
 
-	if i-signOffset < 3 { // ".E" or ".e"
-		return nil, r.syntaxErr("fraction of double without decimals")
+	if i < len(line) && (line[i] == 'E' || line[i] == 'e') {
+		i++ // pass 'E' or 'e'
+		if i < len(line) && (line[i] == '+' || line[i] == '-') {
+			i++ // pass sign
+		}
+		end := decimalsEnd(line, i)
+		if end == i {
+			return nil, r.syntaxErr(ErrIllegalChar, line[i:], "no decimals in double exponent")
+		}
+		t.DatatypeIRI = XSDDouble
+		i = end
 	}
 
-	i++ // pass 'E' or 'e'
-	if i < len(line) && (line[i] == '+' || line[i] == '-') {
-		i++ // pass sign
+	// numbers terminate on anything but a name continuation
+	if i < len(line) && (line[i] == ':' || pnCharsLen(line[i:]) != 0) {
+		return nil, r.syntaxErr(ErrIllegalChar, line[i:], "illegal character in number")
 	}
-	offset := i
-
-	for ; i < len(line); i++ {
-		switch line[i] {
-		case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
-			continue
-		case ' ', '\t', '\r', '\n': // xsd:decimal ended on WS
-			if i == offset {
-				return nil, r.syntaxErr("no decimals in double exponent")
-			}
-			t.DatatypeIRI = XSDDouble
-			t.Object = string(line[:i])
-			return line[i+1:], nil
-		}
-		return nil, r.syntaxErr("illegal charater in exponent of double")
-	}
-	return nil, io.ErrUnexpectedEOF
+	t.Object = string(line[:i])
+	return line[i:], nil
 }
 
-// InDoubleQuote continues from '"' in the buffer.
-func (r *Reader) inDoubleQuote(line []byte, t *Triple) (remainder []byte, err error) {
-	// long quote (`"""`) option
-	if len(line) > 2 && line[1] == '"' && line[2] == '"' {
-		for i := 3; i < len(line); i++ {
-			switch line[i] {
-			case '"': // may terminate
-				switch {
-				case i+2 >= len(line), line[i+1] != '"':
-					continue
-				case line[i+2] != '"':
-					i++
-					continue
-				}
-				t.Object = string(line[3:i])
-				return r.afterQuotedLiteral(line[i+3:], t)
-			case '\\': // is escape
-				return r.longSingleQuote(line[3:i], line[i:], t)
-			}
-		}
-		return r.longSingleQuote(line[3:], nil, t)
+// DecimalsEnd returns the index after any "0".."9" in line from offset.
+func decimalsEnd(line []byte, offset int) int {
+	for offset < len(line) && line[offset] >= '0' && line[offset] <= '9' {
+		offset++
 	}
-
-	for i := 1; i < len(line); i++ {
-		switch line[i] {
-		case '"':
-			t.Object = string(line[1:i])
-			return r.afterQuotedLiteral(line[i+1:], t)
-
-		case '\\':
-			return r.inDoubleQuoteEscape(line[1:i], line[i:], t)
-		case '\r':
-			return nil, r.syntaxErr("new line in quoted literal")
-		case '\n':
-			return nil, r.syntaxErr("carriage return in quoted literal")
-		}
-	}
-	return nil, fmt.Errorf("%w: quoted literal not closed", io.ErrUnexpectedEOF)
+	return offset
 }
 
-// InSingleQuote continues from "'" in the buffer.
-func (r *Reader) inSingleQuote(line []byte, t *Triple) (remainder []byte, err error) {
-	// long quote ("'''") option
-	if len(line) > 2 && line[1] == '\'' && line[2] == '\'' {
-		for i := 3; i < len(line); i++ {
-			switch line[i] {
-			case '\'': // may terminate
-				switch {
-				case i+2 >= len(line), line[i+1] != '\'':
-					continue
-				case line[i+2] != '\'':
-					i++
-					continue
-				}
-				t.Object = string(line[3:i])
-				return r.afterQuotedLiteral(line[i+3:], t)
-			case '\\': // is escape
-				return r.longSingleQuote(line[3:i], line[i:], t)
-			}
-		}
-		return r.longSingleQuote(line[3:], nil, t)
+// InQuote continues from '"' or "'" in the buffer.
+func (r *Reader) inQuote(line []byte, t *Triple) (remainder []byte, err error) {
+	q := line[0] // quote character
+	r.literalQuote = q
+
+	// long quote (`"""` or "'''") option
+	r.literalLong = len(line) > 2 && line[1] == q && line[2] == q
+	if r.literalLong {
+		return r.longQuote(q, nil, line[3:], t)
 	}
 
 	for i := 1; i < len(line); i++ {
 		switch line[i] {
-		case '\'':
+		case q:
 			t.Object = string(line[1:i])
 			return r.afterQuotedLiteral(line[i+1:], t)
 
 		case '\\':
-			return r.inSingleQuoteEscape(line[1:i], line[i:], t)
-		case '\r':
-			return nil, r.syntaxErr("new line in quoted literal")
+			return r.quoteContinue(q, line[1:i], line[i:], t)
 		case '\n':
-			return nil, r.syntaxErr("carriage return in quoted literal")
-		}
-	}
-	return nil, fmt.Errorf("%w: quoted literal not closed", io.ErrUnexpectedEOF)
-}
-
-func (r *Reader) inDoubleQuoteEscape(copyAsIs, line []byte, t *Triple) (remainder []byte, err error) {
-	var b strings.Builder
-	// oversized allocation is better than resizes later on
-	b.Grow(len(copyAsIs) + len(line) - 4)
-	b.Write(copyAsIs)
-
-Escape:
-	for {
-		line, err := r.inEscape(line, &b)
-		if err != nil {
-			return nil, err
-		}
-
-		for i := 0; i < len(line); i++ {
-			switch line[i] {
-			case '\\':
-				b.Write(line[:i])
-				line = line[i:]
-				continue Escape
-
-			case '"':
-				b.Write(line[:i])
-				t.Object = b.String()
-				return r.afterQuotedLiteral(line[i+1:], t)
-
-			case '\r':
-				return nil, r.syntaxErr("new line in quoted literal")
-			case '\n':
-				return nil, r.syntaxErr("carriage return in quoted literal")
-			}
+			return nil, r.syntaxErr(ErrUnterminatedLiteral, line[i:], "new line in quoted literal")
+		case '\r':
+			return nil, r.syntaxErr(ErrUnterminatedLiteral, line[i:], "carriage return in quoted literal")
 		}
-		return nil, fmt.Errorf("%w: quoted literal not closed", io.ErrUnexpectedEOF)
 	}
+	// long line split in chunks
+	return r.quoteContinue(q, nil, line[1:], t)
 }
 
-func (r *Reader) inSingleQuoteEscape(copyAsIs, line []byte, t *Triple) (remainder []byte, err error) {
+// QuoteContinue continues in a quoted literal with copyAsIs as the content read
+// before, and with q as the quote character.
+func (r *Reader) quoteContinue(q byte, copyAsIs, line []byte, t *Triple) (remainder []byte, err error) {
 	var b strings.Builder
 	// oversized allocation is better than resizes later on
-	b.Grow(len(copyAsIs) + len(line) - 4)
+	b.Grow(len(copyAsIs) + len(line))
 	b.Write(copyAsIs)
 
-Escape:
 	for {
-		line, err := r.inEscape(line, &b)
-		if err != nil {
-			return nil, err
-		}
-
 		for i := 0; i < len(line); i++ {
 			switch line[i] {
-			case '\\':
-				b.Write(line[:i])
-				line = line[i:]
-				continue Escape
-
-			case '\'':
+			case q:
 				b.Write(line[:i])
 				t.Object = b.String()
 				return r.afterQuotedLiteral(line[i+1:], t)
 
-			case '\r':
-				return nil, r.syntaxErr("new line in quoted literal")
-			case '\n':
-				return nil, r.syntaxErr("carriage return in quoted literal")
-			}
-		}
-		return nil, fmt.Errorf("%w: quoted literal not closed", io.ErrUnexpectedEOF)
-	}
-}
-
-func (r *Reader) longDoubleQuote(copyAsIs, line []byte, t *Triple) (remainder []byte, err error) {
-	var b strings.Builder
-	b.Write(copyAsIs)
-
-	for {
-		for i := 0; i < len(line); i++ {
-			switch line[i] {
 			case '\\':
 				b.Write(line[:i])
-				line, err = r.inEscape(line[i:], &b)
+				line, err = r.inEscape(line[i+1:], &b)
 				if err != nil {
 					return nil, err
 				}
-				i = 0
+				i = -1 // continue at start of remainder
 
-			case '"':
-				switch {
-				case i+2 >= len(line), line[i+1] != '"':
-					continue
-				case line[i+2] != '"':
-					i++
-					continue
-				}
-				b.Write(line[:i])
-				t.Object = b.String()
-				return r.afterQuotedLiteral(line[i+3:], t)
+			case '\n':
+				return nil, r.syntaxErr(ErrUnterminatedLiteral, line[i:], "new line in quoted literal")
+			case '\r':
+				return nil, r.syntaxErr(ErrUnterminatedLiteral, line[i:], "carriage return in quoted literal")
 			}
 		}
 
+		if !r.midLine {
+			return nil, fmt.Errorf("%w: quoted literal not closed", io.ErrUnexpectedEOF)
+		}
 		b.Write(line)
-		line, err = r.line()
+		line, err = r.readLiteralContinue(&b)
 		if err != nil {
-			if errors.Is(err, io.EOF) {
-				err = fmt.Errorf("%w: long quoted literal not closed", io.ErrUnexpectedEOF)
-			}
 			return nil, err
 		}
 	}
 }
 
-func (r *Reader) longSingleQuote(copyAsIs, line []byte, t *Triple) (remainder []byte, err error) {
+// LongQuote continues in a long quoted literal with copyAsIs as the content
+// read before, and with q as the quote character.
+func (r *Reader) longQuote(q byte, copyAsIs, line []byte, t *Triple) (remainder []byte, err error) {
 	var b strings.Builder
 	b.Write(copyAsIs)
 
 	for {
 		for i := 0; i < len(line); i++ {
 			switch line[i] {
+			case q: // may terminate
+				n := 1 // number of sequential quotes
+				for i+n < len(line) && line[i+n] == q {
+					n++
+				}
+				if n < 3 {
+					i += n - 1
+					continue
+				}
+				// up to two quotes before the terminator
+				if n > 5 {
+					return nil, r.syntaxErr(ErrUnexpectedToken, line[i:], "too many quotes in long quoted literal")
+				}
+				b.Write(line[:i+n-3])
+				t.Object = b.String()
+				return r.afterQuotedLiteral(line[i+n:], t)
+
 			case '\\':
 				b.Write(line[:i])
-				line, err = r.inEscape(line[i:], &b)
+				line, err = r.inEscape(line[i+1:], &b)
 				if err != nil {
 					return nil, err
 				}
-				i = 0
-
-			case '\'':
-				switch {
-				case i+2 >= len(line), line[i+1] != '\'':
-					continue
-				case line[i+2] != '\'':
-					i++
-					continue
-				}
-				b.Write(line[:i])
-				t.Object = b.String()
-				return r.afterQuotedLiteral(line[i+3:], t)
+				i = -1 // continue at start of remainder
 			}
 		}
 
 		b.Write(line)
-		line, err = r.line()
+		line, err = r.readLiteralContinue(&b)
 		if err != nil {
-			if errors.Is(err, io.EOF) {
-				err = fmt.Errorf("%w: long quoted literal not closed", io.ErrUnexpectedEOF)
-			}
 			return nil, err
 		}
 	}
 }
 
+// ReadLiteralContinue reads the next chunk of a quoted literal, with b as the
+// content read before.
+func (r *Reader) readLiteralContinue(b *strings.Builder) (line []byte, err error) {
+	if b.Len() > r.maxTokenSize() {
+		return nil, r.syntaxErr(ErrTokenSize, nil, "quoted literal exceeds maximum token size")
+	}
+	line, err = r.read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			err = fmt.Errorf("%w: quoted literal not closed", io.ErrUnexpectedEOF)
+		}
+		return nil, err
+	}
+	return line, nil
+}
+
+// InEscape continues after a "\" in the buffer.
 func (r *Reader) inEscape(line []byte, b *strings.Builder) (remainder []byte, err error) {
 	if len(line) == 0 {
-		return nil, io.ErrUnexpectedEOF
+		return nil, fmt.Errorf("%w: escape interrupted", io.ErrUnexpectedEOF)
 	}
 	c := line[0]
 	switch c {
@@ -345,6 +216,8 @@ func (r *Reader) inEscape(line []byte, b *strings.Builder) (remainder []byte, er
 		c = '\f'
 	case '"', '\'', '\\':
 		break // as is
+	default:
+		return nil, r.syntaxErr(ErrIllegalEscape, line, "illegal escape in quoted literal")
 	}
 	b.WriteByte(c)
 	return line[1:], nil
@@ -352,10 +225,21 @@ func (r *Reader) inEscape(line []byte, b *strings.Builder) (remainder []byte, er
 
 // NHex decodes a Unicode character of n digits.
 func (r *Reader) nHex(line []byte, n int, b *strings.Builder) (remainder []byte, err error) {
+	c, line, err := r.hexRune(line, n)
+	if err != nil {
+		return nil, err
+	}
+	b.WriteRune(c)
+	return line, nil
+}
+
+// HexRune decodes a Unicode character of n digits.
+func (r *Reader) hexRune(line []byte, n int) (c rune, remainder []byte, err error) {
+	start := line
 	var u uint
 	for ; n != 0; n-- {
 		if len(line) == 0 {
-			return nil, io.ErrUnexpectedEOF
+			return 0, nil, io.ErrUnexpectedEOF
 		}
 
 		u <<= 4 // next nible
@@ -367,26 +251,34 @@ func (r *Reader) nHex(line []byte, n int, b *strings.Builder) (remainder []byte,
 		case c >= 'a' && c <= 'f':
 			u |= (uint)(c - 'a' + 10)
 		default:
-			return nil, r.syntaxErr("illegal hex in Unicode escape")
+			return 0, nil, r.syntaxErr(ErrIllegalEscape, line, "illegal hex in Unicode escape")
 		}
 
 		line = line[1:]
 	}
-	b.WriteRune((rune)(u))
-	return line, nil
+	if u > utf8.MaxRune || !utf8.ValidRune((rune)(u)) {
+		return 0, nil, r.syntaxErr(ErrIllegalEscape, start, "Unicode escape of invalid code point")
+	}
+	return (rune)(u), line, nil
 }
 
 // AfterQuotedLiteral continues with line after a quoted literal was passed.
+// Field literalTyped reports whether a datatype or a language tag followed.
 func (r *Reader) afterQuotedLiteral(line []byte, t *Triple) (remainder []byte, err error) {
+	r.literalQuote = 0 // closed
+	r.literalTyped = false
+
 	if len(line) != 0 {
 		switch line[0] {
 		case ' ', '\t', '\r', '\n': // WS
 			line = line[1:]
 
 		case '^':
+			r.literalTyped = true
 			return r.inDatatype(line, t)
 
 		case '@':
+			r.literalTyped = true
 			return r.inLangTag(line, t)
 		}
 	}
@@ -405,89 +297,91 @@ func (r *Reader) inLangTag(line []byte, t *Triple) (remainder []byte, err error)
 	t.DatatypeIRI = "http://www.w3.org/1999/02/22-rdf-syntax-ns#langString"
 
 	offset := 1 // pass '@'
-	for i := offset; i < len(line); i++ {
+	i := offset
+	for ; i < len(line); i++ {
 		c := line[i]
 		if c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' {
 			continue
 		}
 		if c >= '0' && c <= '9' {
 			if offset == 1 {
-				return nil, r.syntaxErr("decimal in first code of language tag")
+				return nil, r.syntaxErr(ErrIllegalChar, line[i:], "decimal in first code of language tag")
 			}
 			continue
 		}
-
-		switch c {
-		case '-':
-			if offset == i {
-				return nil, r.syntaxErr("empty code in language tag")
-			}
-			offset = i + 1
-
-		case ' ', '\t', '\r', '\n': // WS
-			if offset == i {
-				return nil, r.syntaxErr("empty code in language tag")
+		if c != '-' {
+			break
+		}
+		if offset == i {
+			if i > 1 && r.Version != RDF11 {
+				// “--” separates the base direction
+				return r.inBaseDir(line, i-1, t)
 			}
-			t.LangTag = string(line[1:i])
-			return line[i+1:], nil // ✅
-
-		default:
-			return nil, r.syntaxErr("illegal character in language tag")
+			return nil, r.syntaxErr(ErrUnexpectedToken, line[i:], "empty code in language tag")
 		}
+		offset = i + 1
 	}
-	return nil, io.ErrUnexpectedEOF
+	if offset == i {
+		return nil, r.syntaxErr(ErrUnexpectedToken, line[i:], "empty code in language tag")
+	}
+	t.LangTag = strings.ToLower(string(line[1:i]))
+	return line[i:], nil // ✅
 }
 
-// InDatatype continues from "^" in the buffer.
-func (r *Reader) inDatatype(line []byte, t *Triple) (remainder []byte, err error) {
-	if len(line) < 3 {
-		if len(line) < 2 || len(line) < 3 && line[1] == '^' {
-			return nil, io.ErrUnexpectedEOF
-		}
+// InBaseDir continues from "--" at index i in the buffer, with the language tag
+// before i, as in "@ar--rtl".
+func (r *Reader) inBaseDir(line []byte, i int, t *Triple) (remainder []byte, err error) {
+	end := i + 2 // pass "--"
+	for end < len(line) && (line[end] >= 'A' && line[end] <= 'Z' || line[end] >= 'a' && line[end] <= 'z') {
+		end++
 	}
-	if line[1] != '^' {
-		return nil, r.syntaxErr(`single "^" after quoted string`)
+	switch string(line[i+2 : end]) {
+	case "ltr", "rtl":
+		break
+	default:
+		return nil, r.syntaxErr(ErrUnexpectedToken, line[i:], `base direction is neither "ltr" nor "rtl"`)
 	}
-	if len(line) < 4 {
+
+	// “If the LANG_DIR rule matched, the datatype is rdf:dirLangString …”
+	t.DatatypeIRI = rdfDirLangString
+	t.LangTag = strings.ToLower(string(line[1:i]))
+	t.BaseDir = string(line[i+2 : end])
+	return line[end:], nil // ✅
+}
+
+// InDatatype continues from "^" in the buffer.
+func (r *Reader) inDatatype(line []byte, t *Triple) (remainder []byte, err error) {
+	if len(line) < 2 {
 		return nil, io.ErrUnexpectedEOF
 	}
-	if line[2] == '<' {
-		t.DatatypeIRI, remainder, err = r.inIRI(line[2:])
-		return
+	if line[1] != '^' {
+		return nil, r.syntaxErr(ErrUnexpectedToken, line, `single "^" after quoted string`)
 	}
+	line = line[2:] // pass "^^"
 
-	var prefixLabel []byte
-ReadPrefix:
-	for i := 2; ; i++ {
-		if i >= len(line) {
-			return nil, io.ErrUnexpectedEOF
+	if r.grammar == nTriplesGrammar {
+		// whitespace may surround terminals
+		line, err = r.nTripleContinue(line)
+		if err != nil {
+			return nil, err
 		}
-		switch line[i] {
-		case ' ', '\t', '\r', '\n': // WS
-			return nil, r.syntaxErr("datatype missing prefix")
-
-		case ':':
-			prefixLabel = line[2:i]
-			line = line[i+1:]
-			break ReadPrefix
-
-		default:
-			// TODO: validation
+		if line[0] != '<' {
+			return nil, r.syntaxErr(ErrUnexpectedToken, line, `datatype IRI reference does not start with "<"`)
 		}
+		t.DatatypeIRI, remainder, err = r.inIRI(line)
+		return remainder, err
 	}
 
-	// allocation omitted by compiler
-	prefix, ok := r.prefixPerLabel[string(prefixLabel)]
-	if !ok {
-		return nil, r.syntaxErr("undefined prefix on datatype")
+	if len(line) == 0 {
+		return nil, io.ErrUnexpectedEOF
 	}
-	for i := 0; i < len(line); i++ {
-		switch line[i] {
-		case ' ', '\t', '\r', '\n': // WS
-			t.DatatypeIRI = prefix + string(line[:i])
-			return line[i+1:], nil
-		}
-		// TODO: validation
+	if line[0] == '<' {
+		t.DatatypeIRI, remainder, err = r.inIRI(line)
+		return remainder, err
+	}
+	t.DatatypeIRI, _, remainder, err = r.inPrefixedName(line)
+	if err == nil && t.DatatypeIRI == "" {
+		return nil, r.syntaxErr(ErrUnexpectedToken, line, "datatype missing prefix")
 	}
-	return nil, io.ErrUnexpectedEOF
+	return remainder, err
 }
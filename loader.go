@@ -0,0 +1,87 @@
+package tripn
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DocumentLoader retrieves remote documents, such as the JSON-LD contexts which
+// are referenced by IRI.
+type DocumentLoader interface {
+	LoadDocument(IRI string) ([]byte, error)
+}
+
+// FSLoader is a DocumentLoader which serves from a file system instead of the
+// network. The file name of an IRI is its host followed by its path, as in
+// "www.w3.org/ns/activitystreams". Paths which are empty or which end with a
+// slash get "index.jsonld" appended. Query and fragment have no effect.
+type FSLoader struct {
+	FS fs.FS
+}
+
+// LoadDocument implements the DocumentLoader interface.
+func (l FSLoader) LoadDocument(IRI string) ([]byte, error) {
+	u, err := url.Parse(IRI)
+	if err != nil {
+		return nil, err
+	}
+	name := u.Host + u.Path
+	if u.Path == "" {
+		name += "/"
+	}
+	if strings.HasSuffix(name, "/") {
+		name += "index.jsonld"
+	}
+	return fs.ReadFile(l.FS, name)
+}
+
+// DefaultMaxDocumentSize is the MaxSize of HTTPLoader when zero.
+const DefaultMaxDocumentSize = 4 << 20
+
+// HTTPLoader is a DocumentLoader which retrieves documents with HTTP GET.
+type HTTPLoader struct {
+	// Client defaults to http.DefaultClient when nil.
+	Client *http.Client
+
+	// Documents larger than MaxSize in bytes cause an error. Zero defaults
+	// to DefaultMaxDocumentSize.
+	MaxSize int
+}
+
+// LoadDocument implements the DocumentLoader interface.
+func (l HTTPLoader) LoadDocument(IRI string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, IRI, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/ld+json, application/json;q=0.9")
+
+	client := l.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: HTTP status %q", IRI, resp.Status)
+	}
+	maxSize := l.MaxSize
+	if maxSize == 0 {
+		maxSize = DefaultMaxDocumentSize
+	}
+	doc, err := io.ReadAll(io.LimitReader(resp.Body, int64(maxSize)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(doc) > maxSize {
+		return nil, fmt.Errorf("GET %s: document exceeds %d bytes", IRI, maxSize)
+	}
+	return doc, nil
+}
@@ -0,0 +1,30 @@
+package tripn
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPLoaderMaxSize(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"@context": {}}`)) // 16 bytes
+	}))
+	defer srv.Close()
+
+	l := HTTPLoader{Client: srv.Client(), MaxSize: 16}
+	doc, err := l.LoadDocument(srv.URL)
+	if err != nil {
+		t.Fatal("load error:", err)
+	}
+	if string(doc) != `{"@context": {}}` {
+		t.Errorf("got document %q", doc)
+	}
+
+	l.MaxSize = 15
+	_, err = l.LoadDocument(srv.URL)
+	if err == nil || !strings.Contains(err.Error(), "document exceeds 15 bytes") {
+		t.Errorf("got error %v, want document size exceeded", err)
+	}
+}
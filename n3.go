@@ -0,0 +1,317 @@
+package tripn
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// N3Reader parses Notation3, which extends Turtle with quoted formulae, i.e.,
+// "{ … }" as a node, with variables, and with rule shorthands. The triples of
+// a formula are read as a graph, named with a new Skolem IRI. The formula node
+// uses that same IRI in the enclosing graph. Formulae are never flattened into
+// the default graph.
+//
+// Variables read as an IRI in a namespace of their own, which is unique to the
+// scope of the variable. IsVariable recognizes such IRIs, and IsSkolemIRI does
+// not. IRIs declared with "@forAll" read as such variable within their scope
+// too, and IRIs declared with "@forSome" read as a new Skolem IRI within their
+// scope. Verb "=>" reads as log:implies, "<=" reads as log:implies with the
+// subject and object swapped, and "=" reads as owl:sameAs.
+//
+// Each blank node label gets a Skolem IRI, which is the same for all of its
+// occurrences in the document, within formulae and outside of them.
+type N3Reader struct {
+	// Formulae may span any number of lines, regardless of the buffer
+	// size. MaxTokenSize limits the tokens instead.
+	R *bufio.Reader
+
+	// Tokens larger than MaxTokenSize in bytes cause a *SyntaxError. Zero
+	// defaults to DefaultMaxTokenSize.
+	MaxTokenSize int
+
+	// BaseIRI is the document base, which "@base" and "BASE" directives
+	// replace as they are read, also for the formulae that follow. Users
+	// may initialize the base IRI to the data location.
+	BaseIRI *url.URL
+
+	r   Reader   // parser state
+	buf []Triple // statement reuse
+}
+
+// ReadAppend adds quads from the input stream to dst, and it returns the
+// extended buffer. Reads match the order of appearance with the nested nodes,
+// if any, before their enclosing statement. The triples of formulae go before
+// their enclosing statement too.
+//
+// SyntaxError is used for malformed N3 exclusively. Stream errors pass as is,
+// with the exception of io.EOF. Incomplete records at the end of stream, which
+// includes formulae without a closing "}", are addressed with
+// io.ErrUnexpectedEOF instead.
+func (r *N3Reader) ReadAppend(dst []Quad) ([]Quad, error) {
+	r.r.R = r.R
+	r.r.MaxTokenSize = r.MaxTokenSize
+	r.r.BaseIRI = r.BaseIRI
+	r.r.grammar = n3Grammar
+
+	var err error
+	r.buf, err = r.r.ReadAppend(r.buf[:0])
+	r.BaseIRI = r.r.BaseIRI
+	if err != nil {
+		r.r.formulaQuads = r.r.formulaQuads[:0]
+		return dst, err
+	}
+
+	dst = append(dst, r.r.formulaQuads...)
+	r.r.formulaQuads = r.r.formulaQuads[:0]
+	for _, t := range r.buf {
+		dst = append(dst, Quad{Triple: t})
+	}
+	return dst, nil
+}
+
+// VariableIRIRoot is the namespace of variables from N3Reader.
+const variableIRIRoot = "web+variable://quies.net/"
+
+// IsVariable returns whether s is a IRI minted by N3Reader (for variables).
+func IsVariable(s string) bool {
+	return strings.HasPrefix(s, variableIRIRoot)
+}
+
+func (r *Reader) variableIRIRoot() string {
+	if r.variableIRICache == "" {
+		r.variableIRICache = fmt.Sprintf(variableIRIRoot+"%x%x/", time.Now().UnixNano(), rand.Uint32())
+	}
+	return r.variableIRICache
+}
+
+// VariableIRI returns the IRI of a variable named name, in the scope numbered
+// scopeNo, with zero for the document.
+func (r *Reader) variableIRI(scopeNo int, name string) string {
+	return fmt.Sprintf("%sscope%d#%s", r.variableIRIRoot(), scopeNo, name)
+}
+
+// Notation3 vocabulary for the verb shorthands.
+const (
+	logImplies = "http://www.w3.org/2000/10/swap/log#implies"
+	owlSameAs  = "http://www.w3.org/2002/07/owl#sameAs"
+)
+
+// InFormula continues from "{" in the buffer. The statements within go into
+// formulaQuads, with a new Skolem IRI as the graph.
+func (r *Reader) inFormula(line []byte) (IRI string, remainder []byte, err error) {
+	IRI = r.newAnonIRI()
+
+	// nesting starts over within
+	propListLevel := r.propListLevel
+	collectionLevel := r.collectionLevel
+	annotationLevel := r.annotationLevel
+	r.propListLevel = 0
+	r.collectionLevel = 0
+	r.annotationLevel = 0
+	r.formulaLevel++
+	r.formulaCount++
+	r.formulaScopes = append(r.formulaScopes, r.formulaCount)
+
+	var statements []Triple
+	r.pending = line[1:]
+	for {
+		line, err = r.line()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = fmt.Errorf("%w: formula not closed", io.ErrUnexpectedEOF)
+			}
+			return "", nil, err
+		}
+		if line[0] == '}' {
+			break
+		}
+
+		r.pending = line
+		statements, err = r.readStatement(statements)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	for _, t := range statements {
+		r.formulaQuads = append(r.formulaQuads, Quad{Triple: t, GraphIRI: IRI})
+	}
+
+	// quantifiers end with their formula
+	if len(r.quantifiedScopes) > r.formulaLevel {
+		r.quantifiedScopes = r.quantifiedScopes[:r.formulaLevel]
+	}
+	r.formulaLevel--
+	r.formulaScopes = r.formulaScopes[:r.formulaLevel]
+	r.propListLevel = propListLevel
+	r.collectionLevel = collectionLevel
+	r.annotationLevel = annotationLevel
+	return IRI, line[1:], nil
+}
+
+// InVariable continues from "?" in the buffer. Variables are scoped to the
+// parent of the formula in which they occur, i.e., the same name refers to the
+// same variable in sibling formulae.
+func (r *Reader) inVariable(line []byte) (IRI string, remainder []byte, err error) {
+	if len(line) < 2 {
+		return "", nil, fmt.Errorf("%w: variable interrupted", io.ErrUnexpectedEOF)
+	}
+
+	// first character may be a decimal, yet no '-', U+00B7, etc.
+	i := 1
+	c, size := utf8.DecodeRune(line[i:])
+	if !isPNCharsBase(c) && c != '_' && !(c >= '0' && c <= '9') {
+		return "", nil, r.syntaxErr(ErrIllegalChar, line[1:], "illegal first character in variable")
+	}
+	i += size
+
+	// no dots nor '-' in variables
+	for i < len(line) && line[i] != '-' {
+		n := pnCharsLen(line[i:])
+		if n == 0 {
+			break
+		}
+		i += n
+	}
+	scopeNo := 0
+	if r.formulaLevel > 1 {
+		scopeNo = r.formulaScopes[r.formulaLevel-2]
+	}
+	return r.variableIRI(scopeNo, string(line[1:i])), line[i:], nil
+}
+
+// InQuantifier continues from "@" in the buffer, with either an "@forAll" or a
+// "@forSome" declaration. The IRIs listed get a replacement in the scope of the
+// current formula, or in the scope of the document outside formulae.
+func (r *Reader) inQuantifier(line []byte) (remainder []byte, err error) {
+	if len(line) < 5 {
+		return nil, fmt.Errorf("%w: directive interrupted", io.ErrUnexpectedEOF)
+	}
+	universal := line[4] != 'S'
+	if universal {
+		line, err = r.inToken(line[1:], "forAll")
+	} else {
+		line, err = r.inToken(line[1:], "forSome")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for len(r.quantifiedScopes) <= r.formulaLevel {
+		r.quantifiedScopes = append(r.quantifiedScopes, nil)
+	}
+	scope := r.quantifiedScopes[r.formulaLevel]
+	if scope == nil {
+		scope = make(map[string]string)
+		r.quantifiedScopes[r.formulaLevel] = scope
+	}
+
+	for {
+		line, err = r.lineContinue(line)
+		if err != nil {
+			return nil, err
+		}
+
+		var IRI string
+		if line[0] == '<' {
+			IRI, line, err = r.inIRI(line)
+		} else {
+			var rest []byte
+			IRI, _, rest, err = r.inPrefixedName(line)
+			if err == nil && IRI == "" {
+				err = r.syntaxErr(ErrUnexpectedToken, line, "quantified token is not an IRI")
+			}
+			line = rest
+		}
+		if err != nil {
+			return nil, err
+		}
+		if universal {
+			scopeNo := 0
+			if r.formulaLevel != 0 {
+				scopeNo = r.formulaScopes[r.formulaLevel-1]
+			}
+			scope[IRI] = r.variableIRI(scopeNo, url.QueryEscape(IRI))
+		} else {
+			scope[IRI] = r.newAnonIRI()
+		}
+
+		line, err = r.lineContinue(line)
+		if err != nil {
+			return nil, err
+		}
+		switch line[0] {
+		case ',':
+			line = line[1:]
+		case '.':
+			return line[1:], nil
+		default:
+			return nil, r.syntaxErr(ErrUnexpectedToken, line, `quantifier not terminated with "."`)
+		}
+	}
+}
+
+// QuantifiedTerm returns the replacement of IRI in the current scope, if any,
+// or IRI as is otherwise.
+func (r *Reader) quantifiedTerm(IRI string) string {
+	for i := min(len(r.quantifiedScopes)-1, r.formulaLevel); i >= 0; i-- {
+		if s, ok := r.quantifiedScopes[i][IRI]; ok {
+			return s
+		}
+	}
+	return IRI
+}
+
+// ReadVerb is like readPredicate, yet it accepts the shorthands of N3. Inverse
+// is true for "<=", in which case the subject and object swap places.
+func (r *Reader) readVerb(line []byte) (IRI string, inverse bool, remainder []byte, err error) {
+	if r.grammar != n3Grammar {
+		IRI, remainder, err = r.readPredicate(line)
+		return IRI, false, remainder, err
+	}
+
+	line, err = r.lineContinue(line)
+	if err != nil {
+		return "", false, nil, err
+	}
+	switch line[0] {
+	case '=':
+		if len(line) > 1 && line[1] == '>' {
+			return logImplies, false, line[2:], nil
+		}
+		return owlSameAs, false, line[1:], nil
+	case '<':
+		if len(line) > 1 && line[1] == '=' && !isIRIRef(line) {
+			return logImplies, true, line[2:], nil
+		}
+	case '?':
+		IRI, remainder, err = r.inVariable(line)
+		return IRI, false, remainder, err
+	}
+	IRI, remainder, err = r.readPredicate(line)
+	if err != nil {
+		return "", false, nil, err
+	}
+	return r.quantifiedTerm(IRI), false, remainder, nil
+}
+
+// IsIRIRef returns whether line, which starts with "<", has a ">" before any
+// whitespace.
+func isIRIRef(line []byte) bool {
+	for _, c := range line[1:] {
+		switch c {
+		case '>':
+			return true
+		case ' ', '\t', '\r', '\n':
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+package tripn
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var n3Quads = []struct {
+	n3    string
+	quads []Quad
+}{
+	{"", []Quad{}},
+
+	// rule with variables
+	{`@prefix : <http://example.com/> .
+{ ?x a :Cat } => { ?x a :Animal } .`,
+		[]Quad{
+			{Triple{"http://example.com/variable-stub/scope0#x", rdfType, "http://example.com/Cat", "", "", ""}, "http://example.com/skolem-stub/anon#1"},
+			{Triple{"http://example.com/variable-stub/scope0#x", rdfType, "http://example.com/Animal", "", "", ""}, "http://example.com/skolem-stub/anon#2"},
+			{Triple{"http://example.com/skolem-stub/anon#1", logImplies, "http://example.com/skolem-stub/anon#2", "", "", ""}, ""},
+		},
+	},
+
+	// inverse implication, same-as and nested formulae, with directives in effect
+	// beyond their formula
+	{`@prefix : <http://example.com/> .
+{ ?y :q ?z . } <= { ?y :p { @prefix : <http://example.org/> . :a = :b } } .
+:s :says {} ; = :t .`,
+		[]Quad{
+			{Triple{"http://example.com/variable-stub/scope0#y", "http://example.com/q", "http://example.com/variable-stub/scope0#z", "", "", ""}, "http://example.com/skolem-stub/anon#1"},
+			{Triple{"http://example.org/a", owlSameAs, "http://example.org/b", "", "", ""}, "http://example.com/skolem-stub/anon#3"},
+			{Triple{"http://example.com/variable-stub/scope0#y", "http://example.com/p", "http://example.com/skolem-stub/anon#3", "", "", ""}, "http://example.com/skolem-stub/anon#2"},
+			{Triple{"http://example.com/skolem-stub/anon#2", logImplies, "http://example.com/skolem-stub/anon#1", "", "", ""}, ""},
+			{Triple{"http://example.org/s", "http://example.org/says", "http://example.com/skolem-stub/anon#4", "", "", ""}, ""},
+			{Triple{"http://example.org/s", owlSameAs, "http://example.org/t", "", "", ""}, ""},
+		},
+	},
+
+	// variables with the same name in distinct formulae
+	{`@prefix : <http://example.com/> .
+{ :a :b { ?x :p :o } } => { :c :d { ?x :q :o } } .`,
+		[]Quad{
+			{Triple{"http://example.com/variable-stub/scope1#x", "http://example.com/p", "http://example.com/o", "", "", ""}, "http://example.com/skolem-stub/anon#2"},
+			{Triple{"http://example.com/a", "http://example.com/b", "http://example.com/skolem-stub/anon#2", "", "", ""}, "http://example.com/skolem-stub/anon#1"},
+			{Triple{"http://example.com/variable-stub/scope3#x", "http://example.com/q", "http://example.com/o", "", "", ""}, "http://example.com/skolem-stub/anon#4"},
+			{Triple{"http://example.com/c", "http://example.com/d", "http://example.com/skolem-stub/anon#4", "", "", ""}, "http://example.com/skolem-stub/anon#3"},
+			{Triple{"http://example.com/skolem-stub/anon#1", logImplies, "http://example.com/skolem-stub/anon#3", "", "", ""}, ""},
+		},
+	},
+
+	// quantifiers apply within their scope only
+	{`@prefix : <http://example.com/> .
+@forAll :x .
+{ @forSome :y, <http://example.com/z> . :x :p :y , :z } => { :x :q :y } .
+:x :r [ :p { :a :b :c } ] .`,
+		[]Quad{
+			{Triple{"http://example.com/variable-stub/scope0#http%3A%2F%2Fexample.com%2Fx", "http://example.com/p", "http://example.com/skolem-stub/anon#2", "", "", ""}, "http://example.com/skolem-stub/anon#1"},
+			{Triple{"http://example.com/variable-stub/scope0#http%3A%2F%2Fexample.com%2Fx", "http://example.com/p", "http://example.com/skolem-stub/anon#3", "", "", ""}, "http://example.com/skolem-stub/anon#1"},
+			{Triple{"http://example.com/variable-stub/scope0#http%3A%2F%2Fexample.com%2Fx", "http://example.com/q", "http://example.com/y", "", "", ""}, "http://example.com/skolem-stub/anon#4"},
+			{Triple{"http://example.com/skolem-stub/anon#1", logImplies, "http://example.com/skolem-stub/anon#4", "", "", ""}, ""},
+			{Triple{"http://example.com/a", "http://example.com/b", "http://example.com/c", "", "", ""}, "http://example.com/skolem-stub/anon#6"},
+			{Triple{"http://example.com/skolem-stub/anon#5", "http://example.com/p", "http://example.com/skolem-stub/anon#6", "", "", ""}, ""},
+			{Triple{"http://example.com/variable-stub/scope0#http%3A%2F%2Fexample.com%2Fx", "http://example.com/r", "http://example.com/skolem-stub/anon#5", "", "", ""}, ""},
+		},
+	},
+}
+
+func TestN3Reader(t *testing.T) {
+	for _, test := range n3Quads {
+		for _, bufSize := range []int{16, 4096} {
+			r := N3Reader{R: bufio.NewReaderSize(strings.NewReader(test.n3), bufSize)}
+			r.r.skolemIRICache = "http://example.com/skolem-stub/"
+			r.r.variableIRICache = "http://example.com/variable-stub/"
+
+			got := []Quad{}
+			var err error
+			for err == nil {
+				got, err = r.ReadAppend(got)
+			}
+			if err != io.EOF {
+				t.Errorf("got error %v, with buffer size %d, for N3:\n%s", err, bufSize, test.n3)
+				continue
+			}
+			if !slices.Equal(got, test.quads) {
+				t.Errorf("got quads %q, want %q, with buffer size %d, for N3:\n%s", got, test.quads, bufSize, test.n3)
+			}
+		}
+	}
+}
+
+var n3SyntaxErrors = []struct {
+	n3     string
+	reason string
+	column int
+}{
+	{`}`,
+		"illegal subject token", 1},
+	{`{ } } .`,
+		"illegal predicate token", 5},
+	{`<http://example.com/s> <= "literal" .`,
+		`literal as subject of "<="`, 27},
+	{`?-x <http://example.com/p> <http://example.com/o> .`,
+		"illegal first character in variable", 2},
+	{`@forAll "x" .`,
+		"quantified token is not an IRI", 9},
+	{`@forSome <http://example.com/x> <http://example.com/y> .`,
+		`quantifier not terminated with "."`, 33},
+	{`@forEach <http://example.com/x> .`,
+		`unknown token; expected "forAll"`, 2},
+}
+
+func TestN3ReaderSyntaxErrors(t *testing.T) {
+	for _, test := range n3SyntaxErrors {
+		r := N3Reader{R: bufio.NewReader(strings.NewReader(test.n3))}
+
+		var err error
+		for err == nil {
+			_, err = r.ReadAppend(nil)
+		}
+		var e *SyntaxError
+		if !errors.As(err, &e) {
+			t.Errorf("got error %v, want a *SyntaxError, for N3:\n%s", err, test.n3)
+			continue
+		}
+		if e.Reason != test.reason || e.Column != test.column {
+			t.Errorf("got reason %q at column %d, want %q at column %d, for N3:\n%s", e.Reason, e.Column, test.reason, test.column, test.n3)
+		}
+		if !strings.HasPrefix(e.Error(), "N3 syntax violation") {
+			t.Errorf("got error message %q, want N3", e.Error())
+		}
+	}
+}
+
+func TestN3ReaderFormulaNotClosed(t *testing.T) {
+	r := N3Reader{R: bufio.NewReader(strings.NewReader(`{ <http://example.com/s> <http://example.com/p> <http://example.com/o> .`))}
+
+	_, err := r.ReadAppend(nil)
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("got error %v, want io.ErrUnexpectedEOF", err)
+	}
+}
+
+func TestIsVariable(t *testing.T) {
+	r := N3Reader{R: bufio.NewReader(strings.NewReader(`{ ?x a <http://example.com/Cat> } => { ?x a <http://example.com/Animal> } .`))}
+	quads, err := r.ReadAppend(nil)
+	if err != nil {
+		t.Fatal("read error:", err)
+	}
+	q := quads[0]
+	if !IsVariable(q.SubjectIRI) {
+		t.Errorf("variable %q not recognized", q.SubjectIRI)
+	}
+	if IsSkolemIRI(q.SubjectIRI) {
+		t.Errorf("variable %q recognized as Skolem IRI", q.SubjectIRI)
+	}
+	if IsVariable(q.GraphIRI) || !IsSkolemIRI(q.GraphIRI) {
+		t.Errorf("formula %q recognized as variable", q.GraphIRI)
+	}
+
+	var b strings.Builder
+	w := TurtleWriter{W: &b}
+	if err := w.WriteTriple(q.Triple); err != nil {
+		t.Fatal("write error:", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal("close error:", err)
+	}
+	if got := b.String(); !strings.HasPrefix(got, "<"+q.SubjectIRI+">") {
+		t.Errorf("got Turtle %q, want variable IRI as is", got)
+	}
+}
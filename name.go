@@ -0,0 +1,224 @@
+package tripn
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// IsPNCharsBase returns whether c matches PN_CHARS_BASE from the Turtle grammar.
+func isPNCharsBase(c rune) bool {
+	switch {
+	case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z':
+		return true
+	case c < 0xC0:
+		return false
+	case c <= 0xD6, c >= 0xD8 && c <= 0xF6, c >= 0xF8 && c <= 0x2FF,
+		c >= 0x370 && c <= 0x37D, c >= 0x37F && c <= 0x1FFF,
+		c >= 0x200C && c <= 0x200D, c >= 0x2070 && c <= 0x218F,
+		c >= 0x2C00 && c <= 0x2FEF, c >= 0x3001 && c <= 0xD7FF,
+		c >= 0xF900 && c <= 0xFDCF, c >= 0xFDF0 && c <= 0xFFFD,
+		c >= 0x10000 && c <= 0xEFFFF:
+		return true
+	}
+	return false
+}
+
+// IsPNChars returns whether c matches PN_CHARS from the Turtle grammar, which
+// includes PN_CHARS_U.
+func isPNChars(c rune) bool {
+	switch {
+	case c >= '0' && c <= '9', c == '_', c == '-', c == 0xB7:
+		return true
+	case c >= 0x300 && c <= 0x36F, c >= 0x203F && c <= 0x2040:
+		return true
+	}
+	return isPNCharsBase(c)
+}
+
+// IsPNLocalEsc returns whether c may follow a backslash in PN_LOCAL_ESC.
+func isPNLocalEsc(c byte) bool {
+	switch c {
+	case '_', '~', '.', '-', '!', '$', '&', '\'', '(', ')', '*', '+', ',', ';', '=', '/', '?', '#', '@', '%':
+		return true
+	}
+	return false
+}
+
+// IsHex returns whether c is a hexadecimal digit.
+func isHex(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'A' && c <= 'F' || c >= 'a' && c <= 'f'
+}
+
+// PNCharsLen returns the number of bytes of the PN_CHARS at the start of line,
+// with zero for none.
+func pnCharsLen(line []byte) int {
+	c, size := utf8.DecodeRune(line)
+	if size == 0 || !isPNChars(c) {
+		return 0
+	}
+	return size
+}
+
+// DotsContinue returns whether the dots at the start of line are followed by
+// more of the name. Names can not end with a dot.
+func dotsContinue(line []byte, inLocal bool) bool {
+	for i := range line {
+		switch c := line[i]; {
+		case c == '.':
+			continue
+		case inLocal && (c == ':' || c == '%' || c == '\\'):
+			return true
+		default:
+			return pnCharsLen(line[i:]) != 0
+		}
+	}
+	return false
+}
+
+// ScanPrefixLabel returns the length of the PN_PREFIX at the start of line,
+// which may be zero.
+func scanPrefixLabel(line []byte) int {
+	c, size := utf8.DecodeRune(line)
+	if size == 0 || !isPNCharsBase(c) {
+		return 0
+	}
+	i := size
+	for i < len(line) {
+		if line[i] == '.' {
+			if !dotsContinue(line[i:], false) {
+				break
+			}
+			i++
+			continue
+		}
+		n := pnCharsLen(line[i:])
+		if n == 0 {
+			break
+		}
+		i += n
+	}
+	return i
+}
+
+// InPrefixedName continues from the start of a prefixed name in the buffer.
+// Line could also start with a bare word, i.e., a name without colon, which is
+// returned as keyword instead, for the caller to match (as in "a", "true", or
+// "PREFIX"). Both the IRI and the keyword are zero for anything else.
+func (r *Reader) inPrefixedName(line []byte) (IRI string, keyword, remainder []byte, err error) {
+	i := scanPrefixLabel(line)
+	if i >= len(line) || line[i] != ':' {
+		return "", line[:i], line[i:], nil
+	}
+
+	// allocation omitted by compiler
+	prefix, ok := r.prefixPerLabel[string(line[:i])]
+	if !ok {
+		return "", nil, nil, r.syntaxErr(ErrUndefinedPrefix, line, fmt.Sprintf("undefined prefix %q", line[:i]))
+	}
+	local, remainder, err := r.inLocalName(line[i+1:])
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return prefix + local, nil, remainder, nil
+}
+
+// InLocalName continues after the colon of a prefixed name in the buffer. The
+// PN_LOCAL may be empty. Percent-encodings stay as is, while escapes with a
+// backslash are decoded.
+func (r *Reader) inLocalName(line []byte) (local string, remainder []byte, err error) {
+	var b strings.Builder // lazy use on escapes only
+	offset := 0           // start of pending copy
+
+	i := 0
+Scan:
+	for i < len(line) {
+		switch line[i] {
+		case ':':
+			i++
+
+		case '.':
+			if i == 0 || !dotsContinue(line[i:], true) {
+				break Scan
+			}
+			i++
+
+		case '%':
+			if i+2 >= len(line) {
+				return "", nil, fmt.Errorf("%w: percent-encoding interrupted", io.ErrUnexpectedEOF)
+			}
+			if !isHex(line[i+1]) || !isHex(line[i+2]) {
+				return "", nil, r.syntaxErr(ErrIllegalEscape, line[i:], "illegal percent-encoding in local name")
+			}
+			i += 3
+
+		case '\\':
+			if i+1 >= len(line) {
+				return "", nil, fmt.Errorf("%w: local name escape interrupted", io.ErrUnexpectedEOF)
+			}
+			if !isPNLocalEsc(line[i+1]) {
+				return "", nil, r.syntaxErr(ErrIllegalEscape, line[i:], "illegal escape in local name")
+			}
+			b.Write(line[offset:i])
+			b.WriteByte(line[i+1])
+			i += 2
+			offset = i
+
+		default:
+			c, size := utf8.DecodeRune(line[i:])
+			if !isPNChars(c) {
+				break Scan
+			}
+			// first character can not be '-' nor U+00B7, nor
+			// in ranges U+0300–U+036F and U+203F–U+2040
+			if i == 0 && !isPNCharsBase(c) && c != '_' && !(c >= '0' && c <= '9') {
+				break Scan
+			}
+			i += size
+		}
+	}
+
+	if offset == 0 {
+		return string(line[:i]), line[i:], nil
+	}
+	b.Write(line[offset:i])
+	return b.String(), line[i:], nil
+}
+
+// InBlankLabel continues from "_" in the buffer.
+func (r *Reader) inBlankLabel(line []byte) (IRI string, remainder []byte, err error) {
+	if len(line) < 3 {
+		if len(line) > 1 && line[1] != ':' {
+			return "", nil, r.syntaxErr(ErrUnexpectedToken, line, `prefixed name starts with underscore ("_")`)
+		}
+		return "", nil, fmt.Errorf("%w: blank node label interrupted", io.ErrUnexpectedEOF)
+	}
+	if line[1] != ':' {
+		return "", nil, r.syntaxErr(ErrUnexpectedToken, line, `prefixed name starts with underscore ("_")`)
+	}
+
+	// first character may be a decimal, yet no '-', U+00B7, etc.
+	i := 2
+	c, size := utf8.DecodeRune(line[i:])
+	if !isPNCharsBase(c) && c != '_' && !(c >= '0' && c <= '9') {
+		return "", nil, r.syntaxErr(ErrIllegalChar, line[2:], "illegal first character in blank node label")
+	}
+	i += size
+
+	for i < len(line) {
+		if line[i] == '.' {
+			if !dotsContinue(line[i:], false) {
+				break
+			}
+			i++
+			continue
+		}
+		n := pnCharsLen(line[i:])
+		if n == 0 {
+			break
+		}
+		i += n
+	}
+	return r.blankIRI(string(line[2:i])), line[i:], nil
+}
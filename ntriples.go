@@ -0,0 +1,605 @@
+package tripn
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// NTriplesReader parses N-Triples in a strict manner. The input is standard
+// compliant when read completes without error and vise versa. Each statement
+// must be on a line of its own. Turtle constructs such as directives, prefixed
+// names, relative IRI references and multi-line statements all get rejected
+// with a *SyntaxError. Quoted triples from N-Triples-star, and triple terms
+// from RDF 1.2 are accepted.
+//
+// Each blank node label gets a Skolem IRI, which is the same for all of its
+// occurrences in the input.
+type NTriplesReader struct {
+	// A line may exceed the buffer size. Only MaxTokenSize limits the
+	// length of its terms.
+	R *bufio.Reader
+
+	// Tokens larger than MaxTokenSize in bytes cause a *SyntaxError. Zero
+	// defaults to DefaultMaxTokenSize.
+	MaxTokenSize int
+
+	r Reader // parser state
+}
+
+// ReadAppend adds the triple from the next line with a statement to dst, and it
+// returns the extended buffer.
+//
+// SyntaxError is used for malformed N-Triples exclusively. Stream errors pass as
+// is, with the exception of io.EOF. Incomplete records at the end of stream are
+// addressed with io.ErrUnexpectedEOF instead.
+func (r *NTriplesReader) ReadAppend(dst []Triple) ([]Triple, error) {
+	r.r.R = r.R
+	r.r.MaxTokenSize = r.MaxTokenSize
+	r.r.grammar = nTriplesGrammar
+
+	q, err := r.r.readNQuad()
+	if err != nil {
+		return dst, err
+	}
+	return append(dst, q.Triple), nil
+}
+
+// NQuadsReader parses N-Quads in a strict manner, like NTriplesReader does with
+// N-Triples. Blank node labels of graphs get the same Skolem IRIs as the blank
+// node labels of subjects and objects.
+type NQuadsReader struct {
+	// The buffer size has no effect on the maximum line length, nor on
+	// the maximum token size.
+	R *bufio.Reader
+
+	// Tokens larger than MaxTokenSize in bytes cause a *SyntaxError. Zero
+	// defaults to DefaultMaxTokenSize.
+	MaxTokenSize int
+
+	r Reader // parser state
+}
+
+// ReadAppend adds the quad from the next line with a statement to dst, and it
+// returns the extended buffer.
+//
+// SyntaxError is used for malformed N-Quads exclusively. Stream errors pass as
+// is, with the exception of io.EOF. Incomplete records at the end of stream are
+// addressed with io.ErrUnexpectedEOF instead.
+func (r *NQuadsReader) ReadAppend(dst []Quad) ([]Quad, error) {
+	r.r.R = r.R
+	r.r.MaxTokenSize = r.MaxTokenSize
+	r.r.grammar = nQuadsGrammar
+
+	q, err := r.r.readNQuad()
+	if err != nil {
+		return dst, err
+	}
+	return append(dst, q), nil
+}
+
+// ReadNQuad reads the next statement in either N-Triples or N-Quads grammar.
+// The graph remains zero for N-Triples.
+func (r *Reader) readNQuad() (q Quad, err error) {
+	line, err := r.line()
+	if err != nil {
+		return Quad{}, err
+	}
+	line, err = r.nTriple(line, &q.Triple)
+	if err != nil {
+		return Quad{}, err
+	}
+
+	line, err = r.nTripleContinue(line)
+	if err != nil {
+		return Quad{}, err
+	}
+	if r.grammar == nQuadsGrammar && line[0] != '.' {
+		switch line[0] {
+		case '<':
+			q.GraphIRI, line, err = r.inIRI(line)
+		case '_':
+			q.GraphIRI, line, err = r.inBlankLabel(line)
+		default:
+			err = r.syntaxErr(ErrUnexpectedToken, line, "graph label is not an IRI reference nor a blank node label")
+		}
+		if err != nil {
+			return Quad{}, err
+		}
+
+		line, err = r.nTripleContinue(line)
+		if err != nil {
+			return Quad{}, err
+		}
+	}
+	if line[0] != '.' {
+		return Quad{}, r.syntaxErr(ErrUnexpectedToken, line, `statement not terminated with "."`)
+	}
+	err = r.nTripleEnd(line[1:])
+	if err != nil {
+		return Quad{}, err
+	}
+	return q, nil // ✅
+}
+
+// NTriple reads the subject, the predicate and the object of t.
+func (r *Reader) nTriple(line []byte, t *Triple) (remainder []byte, err error) {
+	switch line[0] {
+	case '<':
+		if len(line) > 1 && line[1] == '<' {
+			if len(line) > 2 && line[2] == '(' {
+				err = r.syntaxErr(ErrUnexpectedToken, line, "triple term as subject")
+				break
+			}
+			var quoted Triple
+			quoted, _, line, err = r.inNQuotedTriple(line)
+			t.SubjectIRI = quoted.Quoted()
+		} else {
+			t.SubjectIRI, line, err = r.inIRI(line)
+		}
+	case '_':
+		t.SubjectIRI, line, err = r.inBlankLabel(line)
+	default:
+		err = r.syntaxErr(ErrUnexpectedToken, line, "subject is not an IRI reference nor a blank node label")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	line, err = r.nTripleContinue(line)
+	if err != nil {
+		return nil, err
+	}
+	if line[0] != '<' || len(line) > 1 && line[1] == '<' {
+		return nil, r.syntaxErr(ErrUnexpectedToken, line, "predicate is not an IRI reference")
+	}
+	t.PredicateIRI, line, err = r.inIRI(line)
+	if err != nil {
+		return nil, err
+	}
+
+	line, err = r.nTripleContinue(line)
+	if err != nil {
+		return nil, err
+	}
+	switch line[0] {
+	case '<':
+		if len(line) > 1 && line[1] == '<' {
+			var quoted Triple
+			var isTerm bool
+			quoted, isTerm, line, err = r.inNQuotedTriple(line)
+			if isTerm {
+				t.Object = quoted.TripleTerm()
+			} else {
+				t.Object = quoted.Quoted()
+			}
+		} else {
+			t.Object, line, err = r.inIRI(line)
+		}
+	case '_':
+		t.Object, line, err = r.inBlankLabel(line)
+	case '"':
+		if len(line) > 2 && line[1] == '"' && line[2] == '"' {
+			err = r.syntaxErr(ErrUnexpectedToken, line, "long quoted literal not permitted")
+			break
+		}
+		line, err = r.inQuote(line, t)
+		if err != nil || r.literalTyped {
+			break
+		}
+		// whitespace may surround "^^" and the language tag
+		line, err = r.nTripleContinue(line)
+		if err != nil {
+			break
+		}
+		switch line[0] {
+		case '^':
+			line, err = r.inDatatype(line, t)
+		case '@':
+			line, err = r.inLangTag(line, t)
+		}
+	default:
+		err = r.syntaxErr(ErrUnexpectedToken, line, "object is not an IRI reference, nor a blank node label, nor a quoted literal")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return line, nil
+}
+
+// InNQuotedTriple continues from "<<" in the buffer, with either a quoted triple
+// from N-Triples-star, or a triple term "<<( … )>>" from RDF 1.2.
+func (r *Reader) inNQuotedTriple(line []byte) (t Triple, isTerm bool, remainder []byte, err error) {
+	isTerm = len(line) > 2 && line[2] == '('
+	if isTerm {
+		line = line[3:]
+	} else {
+		line = line[2:]
+	}
+
+	line, err = r.nTripleContinue(line)
+	if err != nil {
+		return Triple{}, false, nil, err
+	}
+	line, err = r.nTriple(line, &t)
+	if err != nil {
+		return Triple{}, false, nil, err
+	}
+	line, err = r.nTripleContinue(line)
+	if err != nil {
+		return Triple{}, false, nil, err
+	}
+
+	if isTerm {
+		if len(line) < 3 || line[0] != ')' || line[1] != '>' || line[2] != '>' {
+			return Triple{}, false, nil, r.syntaxErr(ErrUnexpectedToken, line, `triple term not closed with ")>>"`)
+		}
+		return t, true, line[3:], nil
+	}
+	if len(line) < 2 || line[0] != '>' || line[1] != '>' {
+		return Triple{}, false, nil, r.syntaxErr(ErrUnexpectedToken, line, `quoted triple not closed with ">>"`)
+	}
+	return t, false, line[2:], nil
+}
+
+// ParseQuotedTriple returns the triple of either a Triple.Quoted notation, which
+// is N-Triples-star, or a Triple.TripleTerm notation. Blank node labels get new
+// Skolem IRIs.
+func ParseQuotedTriple(s string) (Triple, error) {
+	r := Reader{
+		R:       bufio.NewReader(strings.NewReader(s)),
+		grammar: nTriplesGrammar,
+	}
+	line, err := r.line()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return Triple{}, err
+	}
+	if len(line) < 2 || line[0] != '<' || line[1] != '<' {
+		return Triple{}, r.syntaxErr(ErrUnexpectedToken, line, `quoted triple does not start with "<<"`)
+	}
+	t, _, line, err := r.inNQuotedTriple(line)
+	if err != nil {
+		return Triple{}, err
+	}
+	err = r.nTripleEnd(line)
+	if err != nil {
+		return Triple{}, err
+	}
+	return t, nil
+}
+
+// NTripleContinue is like lineContinue, yet the statement must continue on the
+// same line.
+func (r *Reader) nTripleContinue(line []byte) ([]byte, error) {
+	for {
+		var err error
+		line, err = r.lead(line)
+		if err != nil {
+			return nil, err
+		}
+		if len(line) != 0 {
+			return line, nil
+		}
+
+		if !r.midLine || r.inComment {
+			n := len(r.chunk)
+			if n == 0 || r.chunk[n-1] != '\n' {
+				return nil, fmt.Errorf("%w: statement not terminated", io.ErrUnexpectedEOF)
+			}
+			return nil, r.syntaxErr(ErrUnexpectedToken, r.chunk[n-1:], "statement interrupted by end of line")
+		}
+		line, err = r.read()
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// NTripleEnd verifies that nothing but whitespace and comments follow line up
+// to the end of the line.
+func (r *Reader) nTripleEnd(line []byte) error {
+	for {
+		var err error
+		line, err = r.lead(line)
+		if err != nil {
+			return err
+		}
+		if len(line) != 0 {
+			return r.syntaxErr(ErrUnexpectedToken, line, "more than one statement on a line")
+		}
+		if !r.midLine || r.inComment {
+			return nil
+		}
+		line, err = r.read()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// NTriplesWriter encodes N-Triples in canonical form. Output is buffered.
+// Flush must be called after the last write.
+type NTriplesWriter struct {
+	W io.Writer
+
+	buf []byte // pending output
+	err error  // sticky write error
+}
+
+// NTriplesWriterBufSize is the amount of pending output which triggers a write.
+const nTriplesWriterBufSize = 4096
+
+// WriteTriple encodes t as a line. Triples with a relative IRI reference, or
+// with a malformed language tag, can not be expressed in N-Triples. Such
+// triples get an error without any output written.
+func (w *NTriplesWriter) WriteTriple(t Triple) error {
+	if w.err != nil {
+		return w.err
+	}
+
+	t, err := checkNTriple(t)
+	if err != nil {
+		return err
+	}
+	w.buf = appendNTriple(w.buf, t)
+	w.buf = append(w.buf, " .\n"...)
+
+	if len(w.buf) >= nTriplesWriterBufSize {
+		return w.Flush()
+	}
+	return nil
+}
+
+// Flush writes any pending output to W.
+func (w *NTriplesWriter) Flush() error {
+	if w.err != nil {
+		return w.err
+	}
+	if len(w.buf) != 0 {
+		_, w.err = w.W.Write(w.buf)
+		w.buf = w.buf[:0]
+	}
+	return w.err
+}
+
+// NQuadsWriter encodes N-Quads in canonical form, like NTriplesWriter does
+// with N-Triples. Output is buffered. Flush must be called after the last write.
+type NQuadsWriter struct {
+	W io.Writer
+
+	buf []byte // pending output
+	err error  // sticky write error
+}
+
+// WriteQuad encodes q as a line. Quads which can not be expressed in N-Quads
+// get an error without any output written.
+func (w *NQuadsWriter) WriteQuad(q Quad) error {
+	if w.err != nil {
+		return w.err
+	}
+
+	t, err := checkNTriple(q.Triple)
+	if err != nil {
+		return err
+	}
+	if q.GraphIRI != "" {
+		if err := checkNTriplesIRI(q.GraphIRI); err != nil {
+			return err
+		}
+	}
+	w.buf = appendNTriple(w.buf, t)
+	if q.GraphIRI != "" {
+		w.buf = append(w.buf, ' ')
+		w.buf = appendNTriplesIRI(w.buf, q.GraphIRI)
+	}
+	w.buf = append(w.buf, " .\n"...)
+
+	if len(w.buf) >= nTriplesWriterBufSize {
+		return w.Flush()
+	}
+	return nil
+}
+
+// WriteTriple encodes t as a line in the default graph.
+func (w *NQuadsWriter) WriteTriple(t Triple) error {
+	return w.WriteQuad(Quad{Triple: t})
+}
+
+// Flush writes any pending output to W.
+func (w *NQuadsWriter) Flush() error {
+	if w.err != nil {
+		return w.err
+	}
+	if len(w.buf) != 0 {
+		_, w.err = w.W.Write(w.buf)
+		w.buf = w.buf[:0]
+	}
+	return w.err
+}
+
+// CheckNTriple verifies that t can be expressed in N-Triples. Quoted triples
+// get parsed, and the returned copy has them in canonical notation.
+func checkNTriple(t Triple) (Triple, error) {
+	var err error
+	t.SubjectIRI, err = checkNTriplesNode(t.SubjectIRI)
+	if err != nil {
+		return Triple{}, err
+	}
+	if err := checkNTriplesIRI(t.PredicateIRI); err != nil {
+		return Triple{}, err
+	}
+	switch {
+	case t.BaseDir != "" && t.LangTag == "":
+		return Triple{}, fmt.Errorf("N-Triples can not express base direction %q without language tag", t.BaseDir)
+	case t.DatatypeIRI == "":
+		t.Object, err = checkNTriplesNode(t.Object)
+		if err != nil {
+			return Triple{}, err
+		}
+	case t.LangTag == "":
+		if err := checkNTriplesIRI(t.DatatypeIRI); err != nil {
+			return Triple{}, err
+		}
+	case !isNTriplesLangTag(t.LangTag):
+		return Triple{}, fmt.Errorf("N-Triples can not express language tag %q", t.LangTag)
+	case t.BaseDir != "" && t.BaseDir != "ltr" && t.BaseDir != "rtl":
+		return Triple{}, fmt.Errorf("N-Triples can not express base direction %q", t.BaseDir)
+	}
+	return t, nil
+}
+
+// CheckNTriplesNode returns the canonical notation of a quoted triple, or s as
+// is for an IRI reference.
+func checkNTriplesNode(s string) (string, error) {
+	if !IsQuotedTriple(s) {
+		return s, checkNTriplesIRI(s)
+	}
+	t, err := ParseQuotedTriple(s)
+	if err != nil {
+		return "", fmt.Errorf("N-Triples can not express quoted triple %q: %w", s, err)
+	}
+	if strings.HasPrefix(s, "<<(") {
+		return t.TripleTerm(), nil
+	}
+	return t.Quoted(), nil
+}
+
+// CheckNTriplesIRI verifies that s is an absolute IRI without any characters
+// excluded from IRIREF, because UCHAR can not express those either.
+func checkNTriplesIRI(s string) error {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '<', '>', '"', '{', '}', '|', '^', '`', '\\':
+			return fmt.Errorf("N-Triples can not express IRI %q with character %q", s, c)
+		default:
+			if c <= 0x20 {
+				return fmt.Errorf("N-Triples can not express IRI %q with control character %q", s, c)
+			}
+		}
+	}
+	if l, err := url.Parse(s); err != nil || l.Scheme == "" {
+		return fmt.Errorf("N-Triples can not express relative IRI reference %q", s)
+	}
+	return nil
+}
+
+// IsNTriplesLangTag returns whether s matches LANG_DIR, without the "@" and
+// without any base direction.
+func isNTriplesLangTag(s string) bool {
+	for i, subtag := range strings.Split(s, "-") {
+		if subtag == "" {
+			return false
+		}
+		for j := 0; j < len(subtag); j++ {
+			c := subtag[j] | 0x20 // lower case
+			if !(c >= 'a' && c <= 'z' || i != 0 && subtag[j] >= '0' && subtag[j] <= '9') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// AppendNTriple appends the N-Triples notation of t to dst, excluding the " ."
+// terminator. Literals and IRI references get the escapes of the canonical
+// form. Characters excluded from IRIREF get a UCHAR nonetheless.
+func appendNTriple(dst []byte, t Triple) []byte {
+	dst = appendNTriplesNode(dst, t.SubjectIRI)
+	dst = append(dst, ' ')
+	dst = appendNTriplesIRI(dst, t.PredicateIRI)
+	dst = append(dst, ' ')
+
+	if t.DatatypeIRI == "" {
+		return appendNTriplesNode(dst, t.Object)
+	}
+	dst = appendNTriplesString(dst, t.Object)
+	switch {
+	case t.LangTag != "":
+		dst = append(dst, '@')
+		dst = append(dst, t.LangTag...)
+		if t.BaseDir != "" {
+			dst = append(dst, "--"...)
+			dst = append(dst, t.BaseDir...)
+		}
+	case t.DatatypeIRI != XSDString:
+		dst = append(dst, "^^"...)
+		dst = appendNTriplesIRI(dst, t.DatatypeIRI)
+	}
+	return dst
+}
+
+// AppendNTriplesNode appends either a quoted triple as is, or an IRIREF.
+func appendNTriplesNode(dst []byte, s string) []byte {
+	if IsQuotedTriple(s) {
+		return append(dst, s...)
+	}
+	return appendNTriplesIRI(dst, s)
+}
+
+// AppendNTriplesIRI appends s as an IRIREF.
+func appendNTriplesIRI(dst []byte, s string) []byte {
+	dst = append(dst, '<')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '<', '>', '"', '{', '}', '|', '^', '`', '\\':
+			dst = appendUCHAR(dst, c)
+		default:
+			if c <= 0x20 {
+				dst = appendUCHAR(dst, c)
+			} else {
+				dst = append(dst, c)
+			}
+		}
+	}
+	return append(dst, '>')
+}
+
+// AppendNTriplesString appends s as a STRING_LITERAL_QUOTE. “Characters BS
+// (U+0008), HT (U+0009), LF (U+000A), FF (U+000C), CR (U+000D), quotation mark
+// (U+0022), and backslash (U+005C) MUST be encoded using ECHAR. […] Characters
+// in the range from U+0000 to U+0007, VT (U+000B), characters in the range from
+// U+000E to U+001F, and the character DEL (U+007F) MUST be represented by
+// UCHAR”. All other characters are written as is.
+func appendNTriplesString(dst []byte, s string) []byte {
+	dst = append(dst, '"')
+	for i := 0; i < len(s); i++ {
+		dst = appendStringChar(dst, s[i])
+	}
+	return append(dst, '"')
+}
+
+// AppendStringChar appends c with the escapes of appendNTriplesString.
+func appendStringChar(dst []byte, c byte) []byte {
+	switch c {
+	case '\b':
+		return append(dst, `\b`...)
+	case '\t':
+		return append(dst, `\t`...)
+	case '\n':
+		return append(dst, `\n`...)
+	case '\f':
+		return append(dst, `\f`...)
+	case '\r':
+		return append(dst, `\r`...)
+	case '"':
+		return append(dst, `\"`...)
+	case '\\':
+		return append(dst, `\\`...)
+	}
+	if c < 0x20 || c == 0x7f {
+		return appendUCHAR(dst, c)
+	}
+	return append(dst, c)
+}
+
+// AppendUCHAR appends c as a "\u" escape with upper-case hexadecimals.
+func appendUCHAR(dst []byte, c byte) []byte {
+	const hex = "0123456789ABCDEF"
+	return append(dst, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+}
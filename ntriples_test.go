@@ -0,0 +1,390 @@
+package tripn
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var nTriplesTriples = []struct {
+	nTriples string
+	triples  []Triple
+}{
+	// allow empty
+	{"", []Triple{}},
+	{"\n\n", []Triple{}},
+	{"# comment only\n \t\n# EOF at comment end", []Triple{}},
+
+	{`<http://example.com/s> <http://example.com/p> <http://example.com/o> .`,
+		[]Triple{
+			{"http://example.com/s", "http://example.com/p", "http://example.com/o", "", "", ""},
+		},
+	},
+	{"\t<http://example.com/s>\t<http://example.com/p><http://example.com/o>. # trailer\r\n" +
+		"_:b0 <http://example.com/p> _:b1 .\n",
+		[]Triple{
+			{"http://example.com/s", "http://example.com/p", "http://example.com/o", "", "", ""},
+			{"http://example.com/skolem-stub/blank#b0", "http://example.com/p", "http://example.com/skolem-stub/blank#b1", "", "", ""},
+		},
+	},
+	{`<http://example.com/s> <http://example.com/p> "plain" .
+<http://example.com/s> <http://example.com/p> "chat"@EN-gb .
+<http://example.com/s> <http://example.com/p> "chat"@EN-gb--ltr .
+<http://example.com/s> <http://example.com/p> "1"^^<http://www.w3.org/2001/XMLSchema#integer> .
+<http://example.com/s> <http://example.com/p> "tab\there \"quote\" é\U0001F600" .
+<http://example.com/é> <http://example.com/p> "" .`,
+		[]Triple{
+			{"http://example.com/s", "http://example.com/p", "plain", XSDString, "", ""},
+			{"http://example.com/s", "http://example.com/p", "chat", "http://www.w3.org/1999/02/22-rdf-syntax-ns#langString", "en-gb", ""},
+			{"http://example.com/s", "http://example.com/p", "chat", "http://www.w3.org/1999/02/22-rdf-syntax-ns#dirLangString", "en-gb", "ltr"},
+			{"http://example.com/s", "http://example.com/p", "1", XSDInteger, "", ""},
+			{"http://example.com/s", "http://example.com/p", "tab\there \"quote\" é😀", XSDString, "", ""},
+			{"http://example.com/é", "http://example.com/p", "", XSDString, "", ""},
+		},
+	},
+
+	// whitespace surrounds terminals
+	{"<http://example.com/s> <http://example.com/p> \"1\" ^^ <http://www.w3.org/2001/XMLSchema#integer> .\n" +
+		"<http://example.com/s> <http://example.com/p> \"2\"\t^^<http://www.w3.org/2001/XMLSchema#integer>.\n" +
+		"<http://example.com/s> <http://example.com/p> \"chat\" @en .",
+		[]Triple{
+			{"http://example.com/s", "http://example.com/p", "1", XSDInteger, "", ""},
+			{"http://example.com/s", "http://example.com/p", "2", XSDInteger, "", ""},
+			{"http://example.com/s", "http://example.com/p", "chat", "http://www.w3.org/1999/02/22-rdf-syntax-ns#langString", "en", ""},
+		},
+	},
+
+	// triple term from RDF 1.2
+	{`<http://example.com/s> <http://example.com/p> <<( _:s <http://example.com/p> "o"@en--ltr )>> .`,
+		[]Triple{
+			{"http://example.com/s", "http://example.com/p",
+				`<<( <http://example.com/skolem-stub/blank#s> <http://example.com/p> "o"@en--ltr )>>`, "", "", ""},
+		},
+	},
+
+	// N-Triples-star
+	{`<< <http://example.com/s> <http://example.com/p> _:o >> <http://example.com/p> <<<http://example.com/s><http://example.com/p>"o">> .`,
+		[]Triple{
+			{"<< <http://example.com/s> <http://example.com/p> <http://example.com/skolem-stub/blank#o> >>", "http://example.com/p",
+				`<< <http://example.com/s> <http://example.com/p> "o" >>`, "", "", ""},
+		},
+	},
+}
+
+func TestNTriplesReader(t *testing.T) {
+	for _, test := range nTriplesTriples {
+		for _, bufSize := range []int{16, 4096} {
+			r := NTriplesReader{R: bufio.NewReaderSize(strings.NewReader(test.nTriples), bufSize)}
+			r.r.skolemIRICache = "http://example.com/skolem-stub/"
+
+			got := []Triple{}
+			var err error
+			for err == nil {
+				got, err = r.ReadAppend(got)
+			}
+			if err != io.EOF {
+				t.Errorf("got error %v, with buffer size %d, for N-Triples:\n%s", err, bufSize, test.nTriples)
+				continue
+			}
+			if !slices.Equal(got, test.triples) {
+				t.Errorf("got triples %q, want %q, with buffer size %d, for N-Triples:\n%s", got, test.triples, bufSize, test.nTriples)
+			}
+		}
+	}
+}
+
+var nTriplesSyntaxErrors = []struct {
+	nTriples string
+	reason   string
+	kind     error
+	column   int
+}{
+	{`@prefix ex: <http://example.com/> .`,
+		"subject is not an IRI reference nor a blank node label", ErrUnexpectedToken, 1},
+	{`<http://example.com/s> a <http://example.com/o> .`,
+		"predicate is not an IRI reference", ErrUnexpectedToken, 24},
+	{`<s> <http://example.com/p> <http://example.com/o> .`,
+		"relative reference without base IRI", ErrNoBaseIRI, 1},
+	{`<http://example.com/s> <http://example.com/p> 42 .`,
+		"object is not an IRI reference, nor a blank node label, nor a quoted literal", ErrUnexpectedToken, 47},
+	{`<http://example.com/s> <http://example.com/p> 'single' .`,
+		"object is not an IRI reference, nor a blank node label, nor a quoted literal", ErrUnexpectedToken, 47},
+	{`<http://example.com/s> <http://example.com/p> """long""" .`,
+		"long quoted literal not permitted", ErrUnexpectedToken, 47},
+	{`<http://example.com/s> <http://example.com/p> "1"^^xsd:integer .`,
+		`datatype IRI reference does not start with "<"`, ErrUnexpectedToken, 52},
+	{"<http://example.com/s> <http://example.com/p> \"1\" ^^\n<http://www.w3.org/2001/XMLSchema#integer> .",
+		"statement interrupted by end of line", ErrUnexpectedToken, 53},
+	{`<http://example.com/s> <http://example.com/p> "x"^^<http://www.w3.org/2001/XMLSchema#string> @en .`,
+		`statement not terminated with "."`, ErrUnexpectedToken, 94},
+	{`<http://example.com/s> <http://example.com/p> "x"^^<http://www.w3.org/2001/XMLSchema#string> ^^<http://example.com/d> .`,
+		`statement not terminated with "."`, ErrUnexpectedToken, 94},
+	{`<http://example.com/s> <http://example.com/p> <http://example.com/o> ;`,
+		`statement not terminated with "."`, ErrUnexpectedToken, 70},
+	{"<http://example.com/s> <http://example.com/p>\n<http://example.com/o> .",
+		"statement interrupted by end of line", ErrUnexpectedToken, 46},
+	{"<http://example.com/s> # comment\n<http://example.com/p> <http://example.com/o> .",
+		"statement interrupted by end of line", ErrUnexpectedToken, 33},
+	{`<http://example.com/s> <http://example.com/p> <http://example.com/o> . <http://example.com/s> <http://example.com/p> <http://example.com/o> .`,
+		"more than one statement on a line", ErrUnexpectedToken, 72},
+}
+
+func TestNTriplesReaderSyntaxErrors(t *testing.T) {
+	for _, test := range nTriplesSyntaxErrors {
+		r := NTriplesReader{R: bufio.NewReader(strings.NewReader(test.nTriples))}
+
+		var err error
+		for err == nil {
+			_, err = r.ReadAppend(nil)
+		}
+		var e *SyntaxError
+		if !errors.As(err, &e) {
+			t.Errorf("got error %v, want a *SyntaxError, for N-Triples:\n%s", err, test.nTriples)
+			continue
+		}
+		if e.Reason != test.reason {
+			t.Errorf("got reason %q, want %q, for N-Triples:\n%s", e.Reason, test.reason, test.nTriples)
+		}
+		if !errors.Is(err, test.kind) {
+			t.Errorf("got error %v, want kind %q, for N-Triples:\n%s", err, test.kind, test.nTriples)
+		}
+		if e.Column != test.column {
+			t.Errorf("got column %d, want %d, for N-Triples:\n%s", e.Column, test.column, test.nTriples)
+		}
+		if !strings.HasPrefix(e.Error(), "N-Triples syntax violation on line № 1") {
+			t.Errorf("got error message %q, want N-Triples on line 1", e.Error())
+		}
+	}
+}
+
+func TestNTriplesReaderUnexpectedEOF(t *testing.T) {
+	r := NTriplesReader{R: bufio.NewReader(strings.NewReader(`<http://example.com/s> <http://example.com/p>`))}
+	_, err := r.ReadAppend(nil)
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("got error %v, want io.ErrUnexpectedEOF", err)
+	}
+}
+
+func TestNQuadsReader(t *testing.T) {
+	const nQuads = `<http://example.com/s> <http://example.com/p> <http://example.com/o> .
+<http://example.com/s> <http://example.com/p> "v"@en <http://example.com/g> . # named
+_:b0 <http://example.com/p> "1"^^<http://www.w3.org/2001/XMLSchema#integer> _:g .
+`
+	want := []Quad{
+		{Triple{"http://example.com/s", "http://example.com/p", "http://example.com/o", "", "", ""}, ""},
+		{Triple{"http://example.com/s", "http://example.com/p", "v", "http://www.w3.org/1999/02/22-rdf-syntax-ns#langString", "en", ""}, "http://example.com/g"},
+		{Triple{"http://example.com/skolem-stub/blank#b0", "http://example.com/p", "1", XSDInteger, "", ""}, "http://example.com/skolem-stub/blank#g"},
+	}
+
+	r := NQuadsReader{R: bufio.NewReader(strings.NewReader(nQuads))}
+	r.r.skolemIRICache = "http://example.com/skolem-stub/"
+	var got []Quad
+	var err error
+	for err == nil {
+		got, err = r.ReadAppend(got)
+	}
+	if err != io.EOF {
+		t.Fatal("read error:", err)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("got quads %q, want %q", got, want)
+	}
+}
+
+var nQuadsSyntaxErrors = []struct {
+	nQuads string
+	reason string
+	column int
+}{
+	{`<http://example.com/s> <http://example.com/p> <http://example.com/o> "g" .`,
+		"graph label is not an IRI reference nor a blank node label", 70},
+	{`<http://example.com/s> <http://example.com/p> <http://example.com/o> <g> .`,
+		"relative reference without base IRI", 70},
+	{`<http://example.com/s> <http://example.com/p> <http://example.com/o> <http://example.com/g> <http://example.com/x> .`,
+		`statement not terminated with "."`, 93},
+}
+
+func TestNQuadsReaderSyntaxErrors(t *testing.T) {
+	for _, test := range nQuadsSyntaxErrors {
+		r := NQuadsReader{R: bufio.NewReader(strings.NewReader(test.nQuads))}
+
+		_, err := r.ReadAppend(nil)
+		var e *SyntaxError
+		if !errors.As(err, &e) {
+			t.Errorf("got error %v, want a *SyntaxError, for N-Quads:\n%s", err, test.nQuads)
+			continue
+		}
+		if e.Reason != test.reason || e.Column != test.column {
+			t.Errorf("got reason %q at column %d, want %q at column %d, for N-Quads:\n%s", e.Reason, e.Column, test.reason, test.column, test.nQuads)
+		}
+		if !strings.HasPrefix(e.Error(), "N-Quads syntax violation") {
+			t.Errorf("got error message %q, want N-Quads", e.Error())
+		}
+	}
+
+	// graph labels are not part of N-Triples
+	r := NTriplesReader{R: bufio.NewReader(strings.NewReader(nQuadsSyntaxErrors[1].nQuads))}
+	_, err := r.ReadAppend(nil)
+	var e *SyntaxError
+	if !errors.As(err, &e) || e.Reason != `statement not terminated with "."` {
+		t.Errorf("N-Triples reader got error %v, want statement not terminated", err)
+	}
+}
+
+func TestParseQuotedTriple(t *testing.T) {
+	want := Triple{
+		SubjectIRI:   "<< <http://example.com/s> <http://example.com/p> <http://example.com/o> >>",
+		PredicateIRI: "http://example.com/p",
+		Object:       "chat",
+		DatatypeIRI:  "http://www.w3.org/1999/02/22-rdf-syntax-ns#langString",
+		LangTag:      "en-gb",
+	}
+	got, err := ParseQuotedTriple(want.Quoted())
+	if err != nil {
+		t.Fatal("parse error:", err)
+	}
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	_, err = ParseQuotedTriple("<http://example.com/s> <http://example.com/p> <http://example.com/o> .")
+	var e *SyntaxError
+	if !errors.As(err, &e) || e.Reason != `quoted triple does not start with "<<"` {
+		t.Errorf("got error %v for N-Triples, want quoted triple syntax violation", err)
+	}
+}
+
+func TestNTriplesWriter(t *testing.T) {
+	triples := []Triple{
+		{"http://example.com/s", "http://example.com/p", "http://example.com/o", "", "", ""},
+		{"http://example.com/s", "http://example.com/p", "tab\t \"quote\" back\\slash\r\n\x00\x1f\x7f ü", XSDString, "", ""},
+		{"http://example.com/s", "http://example.com/p", "bell\b feed\f vt\v", XSDString, "", ""},
+		{"http://example.com/s", "http://example.com/p", "chat", rdfLangString, "en-gb", ""},
+		{"http://example.com/s", "http://example.com/p", "שלום", rdfDirLangString, "he", "rtl"},
+		{"http://example.com/s", "http://example.com/p", "42", XSDInteger, "", ""},
+		{"http://example.com/skøll", "http://example.com/p", "http://example.com/o#", "", "", ""},
+		{`<< <http://example.com/a> <http://example.com/b> "c\n" >>`, "http://example.com/p", `<<( <http://example.com/a> <http://example.com/b> "d"@en )>>`, "", "", ""},
+	}
+
+	var b strings.Builder
+	w := NTriplesWriter{W: &b}
+	for _, triple := range triples {
+		if err := w.WriteTriple(triple); err != nil {
+			t.Fatal("write error:", err)
+		}
+	}
+	if b.Len() != 0 {
+		t.Errorf("got output %q before flush", b.String())
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal("flush error:", err)
+	}
+
+	const want = `<http://example.com/s> <http://example.com/p> <http://example.com/o> .
+<http://example.com/s> <http://example.com/p> "tab\t \"quote\" back\\slash\r\n\u0000\u001F\u007F ü" .
+<http://example.com/s> <http://example.com/p> "bell\b feed\f vt\u000B" .
+<http://example.com/s> <http://example.com/p> "chat"@en-gb .
+<http://example.com/s> <http://example.com/p> "שלום"@he--rtl .
+<http://example.com/s> <http://example.com/p> "42"^^<http://www.w3.org/2001/XMLSchema#integer> .
+<http://example.com/skøll> <http://example.com/p> <http://example.com/o#> .
+<< <http://example.com/a> <http://example.com/b> "c\n" >> <http://example.com/p> <<( <http://example.com/a> <http://example.com/b> "d"@en )>> .
+`
+	if got := b.String(); got != want {
+		t.Errorf("got N-Triples:\n%s\nwant:\n%s", got, want)
+	}
+
+	// round trip
+	r := NTriplesReader{R: bufio.NewReader(strings.NewReader(b.String()))}
+	got := []Triple{}
+	var err error
+	for err == nil {
+		got, err = r.ReadAppend(got)
+	}
+	if err != io.EOF {
+		t.Fatal("read error:", err)
+	}
+	if !slices.Equal(got, triples) {
+		t.Errorf("got triples %q, want %q", got, triples)
+	}
+}
+
+func TestNTriplesWriterUnsupported(t *testing.T) {
+	for _, triple := range []Triple{
+		{"s", "http://example.com/p", "http://example.com/o", "", "", ""},
+		{"http://example.com/s", "http://example.com/p", "http://example.com/a b", "", "", ""},
+		{"http://example.com/s", "http://example.com/p", "http://example.com/<o>", "", "", ""},
+		{"http://example.com/s", "http://example.com/p", "x", "?dt", "", ""},
+		{"http://example.com/s", "http://example.com/p", "x", rdfLangString, "en gb", ""},
+		{"http://example.com/s", "http://example.com/p", "x", rdfDirLangString, "en", "up"},
+		{"http://example.com/s", "http://example.com/p", "x", XSDString, "", "ltr"},
+		{"<< <http://example.com/a> >>", "http://example.com/p", "http://example.com/o", "", "", ""},
+	} {
+		var b strings.Builder
+		w := NTriplesWriter{W: &b}
+		if err := w.WriteTriple(triple); err == nil {
+			t.Errorf("got no error for %s", triple)
+		}
+		if err := w.Flush(); err != nil {
+			t.Fatal("flush error:", err)
+		}
+		if b.Len() != 0 {
+			t.Errorf("got output %q for %s", b.String(), triple)
+		}
+	}
+}
+
+func TestTripleString(t *testing.T) {
+	got := Triple{"http://example.com/a b", "http://example.com/p", "x\x01", XSDString, "", ""}.String()
+	const want = `<http://example.com/a\u0020b> <http://example.com/p> "x\u0001" .`
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestNQuadsWriter(t *testing.T) {
+	quads := []Quad{
+		{Triple{"http://example.com/s", "http://example.com/p", "http://example.com/o", "", "", ""}, ""},
+		{Triple{"http://example.com/s", "http://example.com/p", "a\tb", XSDString, "", ""}, "http://example.com/g"},
+		{Triple{"http://example.com/s", "http://example.com/p", "chat", rdfLangString, "fr", ""}, "http://example.com/g"},
+	}
+
+	var b strings.Builder
+	w := NQuadsWriter{W: &b}
+	for _, q := range quads {
+		if err := w.WriteQuad(q); err != nil {
+			t.Fatal("write error:", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal("flush error:", err)
+	}
+
+	const want = `<http://example.com/s> <http://example.com/p> <http://example.com/o> .
+<http://example.com/s> <http://example.com/p> "a\tb" <http://example.com/g> .
+<http://example.com/s> <http://example.com/p> "chat"@fr <http://example.com/g> .
+`
+	if got := b.String(); got != want {
+		t.Errorf("got N-Quads:\n%s\nwant:\n%s", got, want)
+	}
+
+	// round trip
+	r := NQuadsReader{R: bufio.NewReader(strings.NewReader(b.String()))}
+	got := []Quad{}
+	var err error
+	for err == nil {
+		got, err = r.ReadAppend(got)
+	}
+	if err != io.EOF {
+		t.Fatal("read error:", err)
+	}
+	if !slices.Equal(got, quads) {
+		t.Errorf("got quads %q, want %q", got, quads)
+	}
+
+	if err := w.WriteQuad(Quad{quads[0].Triple, "_:g"}); err == nil {
+		t.Error("got no error for blank node label as graph")
+	}
+}
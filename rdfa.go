@@ -0,0 +1,720 @@
+package tripn
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// RDFa vocabulary.
+const (
+	rdfaUsesVocabulary = "http://www.w3.org/ns/rdfa#usesVocabulary"
+	rdfHTML            = "http://www.w3.org/1999/02/22-rdf-syntax-ns#HTML"
+	xhvNS              = "http://www.w3.org/1999/xhtml/vocab#"
+)
+
+// RDFaInitialPrefixes has the IRI mappings of the initial context from RDFa 1.1.
+var rdfaInitialPrefixes = map[string]string{
+	"as":      "https://www.w3.org/ns/activitystreams#",
+	"cc":      "http://creativecommons.org/ns#",
+	"csvw":    "http://www.w3.org/ns/csvw#",
+	"ctag":    "http://commontag.org/ns#",
+	"dc":      "http://purl.org/dc/terms/",
+	"dc11":    "http://purl.org/dc/elements/1.1/",
+	"dcat":    "http://www.w3.org/ns/dcat#",
+	"dcterms": "http://purl.org/dc/terms/",
+	"dqv":     "http://www.w3.org/ns/dqv#",
+	"duv":     "https://www.w3.org/TR/vocab-duv#",
+	"foaf":    "http://xmlns.com/foaf/0.1/",
+	"gr":      "http://purl.org/goodrelations/v1#",
+	"grddl":   "http://www.w3.org/2003/g/data-view#",
+	"ical":    "http://www.w3.org/2002/12/cal/icaltzd#",
+	"jsonld":  "http://www.w3.org/ns/json-ld#",
+	"ldp":     "http://www.w3.org/ns/ldp#",
+	"ma":      "http://www.w3.org/ns/ma-ont#",
+	"oa":      "http://www.w3.org/ns/oa#",
+	"odrl":    "http://www.w3.org/ns/odrl/2/",
+	"og":      "http://ogp.me/ns#",
+	"org":     "http://www.w3.org/ns/org#",
+	"owl":     "http://www.w3.org/2002/07/owl#",
+	"prov":    "http://www.w3.org/ns/prov#",
+	"qb":      "http://purl.org/linked-data/cube#",
+	"rdf":     "http://www.w3.org/1999/02/22-rdf-syntax-ns#",
+	"rdfa":    "http://www.w3.org/ns/rdfa#",
+	"rdfs":    "http://www.w3.org/2000/01/rdf-schema#",
+	"rev":     "http://purl.org/stuff/rev#",
+	"rif":     "http://www.w3.org/2007/rif#",
+	"rr":      "http://www.w3.org/ns/r2rml#",
+	"schema":  "http://schema.org/",
+	"sd":      "http://www.w3.org/ns/sparql-service-description#",
+	"sioc":    "http://rdfs.org/sioc/ns#",
+	"skos":    "http://www.w3.org/2004/02/skos/core#",
+	"skosxl":  "http://www.w3.org/2008/05/skos-xl#",
+	"sosa":    "http://www.w3.org/ns/sosa/",
+	"ssn":     "http://www.w3.org/ns/ssn/",
+	"time":    "http://www.w3.org/2006/time#",
+	"v":       "http://rdf.data-vocabulary.org/#",
+	"vcard":   "http://www.w3.org/2006/vcard/ns#",
+	"void":    "http://rdfs.org/ns/void#",
+	"wdr":     "http://www.w3.org/2007/05/powder#",
+	"wdrs":    "http://www.w3.org/2007/05/powder-s#",
+	"xhv":     xhvNS,
+	"xml":     "http://www.w3.org/XML/1998/namespace",
+	"xsd":     "http://www.w3.org/2001/XMLSchema#",
+}
+
+// RDFaInitialTerms has the term mappings of the initial context from RDFa 1.1
+// in lower case.
+var rdfaInitialTerms = map[string]string{
+	"describedby": "http://www.w3.org/2007/05/powder-s#describedby",
+	"license":     xhvNS + "license",
+	"role":        xhvNS + "role",
+}
+
+// RDFaContext is an evaluation context.
+type rdfaContext struct {
+	parentSubject string // zero for none
+	parentObject  string // zero for none
+
+	incomplete []rdfaIncomplete     // pending the next subject
+	lists      map[string]*[]Triple // objects per predicate, shared
+
+	lang     string            // language in effect, in lower case
+	prefixes map[string]string // IRI mappings in effect
+	vocab    string            // default vocabulary, if any
+}
+
+// RDFaIncomplete is a triple without subject or object.
+type rdfaIncomplete struct {
+	predicate string
+	direction byte      // 'f' for forward, 'r' for reverse, 'l' for list
+	list      *[]Triple // objects for the 'l' direction
+}
+
+// RDFa appends the triples of the document node to dst.
+func (r *HTMLReader) rdfa(doc *htmlNode, dst []Triple) ([]Triple, error) {
+	var documentIRI string
+	if r.base != nil {
+		documentIRI = r.base.String()
+	}
+	ctx := &rdfaContext{
+		parentSubject: documentIRI,
+		parentObject:  documentIRI,
+		lists:         make(map[string]*[]Triple),
+		prefixes:      rdfaInitialPrefixes,
+	}
+	var err error
+	for _, n := range doc.children {
+		dst, err = r.rdfaElement(n, ctx, true, dst)
+		if err != nil {
+			return dst, err
+		}
+	}
+
+	// lists of the document itself
+	if documentIRI != "" {
+		for _, predicate := range slices.Sorted(maps.Keys(ctx.lists)) {
+			dst = r.rdfaList(documentIRI, predicate, *ctx.lists[predicate], dst)
+		}
+	}
+	return dst, nil
+}
+
+// RDFaElement appends the triples of n to dst, as specified by the processing
+// sequence of RDFa Core 1.1, section 7.5, with the extensions of HTML+RDFa 1.1.
+func (r *HTMLReader) rdfaElement(n *htmlNode, ctx *rdfaContext, isRoot bool, dst []Triple) ([]Triple, error) {
+	switch n.name {
+	case "":
+		return dst, nil // text
+	case "script":
+		return r.script(n, dst)
+	}
+
+	// step 1
+	var skip bool
+	var newSubject, currentObject, typedResource string
+	local := *ctx
+	local.incomplete = nil
+
+	// step 2
+	if vocab, ok := n.attr("vocab"); ok {
+		vocab = strings.TrimSpace(vocab)
+		if vocab == "" {
+			local.vocab = ""
+		} else {
+			IRI, err := r.rdfaResolve(n, vocab)
+			if err != nil {
+				return dst, err
+			}
+			local.vocab = IRI
+			if r.base != nil {
+				dst = append(dst, Triple{
+					SubjectIRI:   r.base.String(),
+					PredicateIRI: rdfaUsesVocabulary,
+					Object:       IRI,
+				})
+			}
+		}
+	}
+
+	// step 3
+	err := r.rdfaPrefixes(n, &local)
+	if err != nil {
+		return dst, err
+	}
+
+	// step 4
+	if lang, ok := n.attr("xml:lang"); ok {
+		local.lang = strings.ToLower(lang)
+	} else if lang, ok := n.attr("lang"); ok {
+		local.lang = strings.ToLower(lang)
+	}
+
+	about, hasAbout := n.attr("about")
+	typeOf, hasTypeOf := n.attr("typeof")
+	property, hasProperty := n.attr("property")
+	_, hasContent := n.attr("content")
+	datatype, hasDatatype := n.attr("datatype")
+	_, hasInlist := n.attr("inlist")
+	rels, err := r.rdfaRelOrRev(n, &local, "rel", hasProperty)
+	if err != nil {
+		return dst, err
+	}
+	revs, err := r.rdfaRelOrRev(n, &local, "rev", hasProperty)
+	if err != nil {
+		return dst, err
+	}
+	hasRelOrRev := rels != nil || revs != nil
+
+	var aboutIRI string
+	if hasAbout {
+		aboutIRI, err = r.rdfaSafeCURIEOrIRI(n, &local, about)
+		if err != nil {
+			return dst, err
+		}
+	}
+	resourceIRI, err := r.rdfaResource(n, &local)
+	if err != nil {
+		return dst, err
+	}
+	// head and body act like the root element
+	isRootLike := isRoot || n.name == "head" || n.name == "body"
+
+	if !hasRelOrRev {
+		// step 5
+		if hasProperty && !hasContent && !hasDatatype {
+			newSubject = aboutIRI
+			if newSubject == "" {
+				newSubject = ctx.parentObject
+			}
+			if hasTypeOf {
+				if aboutIRI != "" || isRoot {
+					typedResource = newSubject
+				} else {
+					typedResource = resourceIRI
+					if typedResource == "" {
+						typedResource = r.newAnonIRI()
+					}
+					currentObject = typedResource
+				}
+			}
+		} else {
+			switch {
+			case aboutIRI != "":
+				newSubject = aboutIRI
+			case resourceIRI != "":
+				newSubject = resourceIRI
+			case isRootLike:
+				newSubject = ctx.parentObject
+			case hasTypeOf:
+				newSubject = r.newAnonIRI()
+			default:
+				newSubject = ctx.parentObject
+				if !hasProperty {
+					skip = true
+				}
+			}
+			if hasTypeOf {
+				typedResource = newSubject
+			}
+		}
+	} else {
+		// step 6
+		newSubject = aboutIRI
+		if newSubject == "" {
+			newSubject = ctx.parentObject
+		} else if hasTypeOf {
+			typedResource = newSubject
+		}
+		currentObject = resourceIRI
+		if currentObject == "" && hasTypeOf && aboutIRI == "" {
+			currentObject = r.newAnonIRI()
+		}
+		if hasTypeOf && aboutIRI == "" {
+			typedResource = currentObject
+		}
+	}
+
+	// step 7
+	if typedResource != "" {
+		types, err := r.rdfaTerms(n, &local, typeOf)
+		if err != nil {
+			return dst, err
+		}
+		for _, typ := range types {
+			dst = append(dst, Triple{
+				SubjectIRI:   typedResource,
+				PredicateIRI: rdfType,
+				Object:       typ,
+			})
+		}
+	}
+
+	// step 8
+	if newSubject != "" && newSubject != ctx.parentObject {
+		local.lists = make(map[string]*[]Triple)
+	}
+
+	// step 9
+	if currentObject != "" {
+		for _, rel := range rels {
+			if hasInlist {
+				list := local.list(rel)
+				*list = append(*list, Triple{Object: currentObject})
+			} else if newSubject != "" {
+				dst = append(dst, Triple{
+					SubjectIRI:   newSubject,
+					PredicateIRI: rel,
+					Object:       currentObject,
+				})
+			}
+		}
+		for _, rev := range revs {
+			if newSubject != "" {
+				dst = append(dst, Triple{
+					SubjectIRI:   currentObject,
+					PredicateIRI: rev,
+					Object:       newSubject,
+				})
+			}
+		}
+	} else if hasRelOrRev {
+		// step 10
+		currentObject = r.newAnonIRI()
+		for _, rel := range rels {
+			if hasInlist {
+				local.incomplete = append(local.incomplete, rdfaIncomplete{rel, 'l', local.list(rel)})
+			} else {
+				local.incomplete = append(local.incomplete, rdfaIncomplete{rel, 'f', nil})
+			}
+		}
+		for _, rev := range revs {
+			local.incomplete = append(local.incomplete, rdfaIncomplete{rev, 'r', nil})
+		}
+	}
+
+	// step 11
+	if hasProperty {
+		props, err := r.rdfaTerms(n, &local, property)
+		if err != nil {
+			return dst, err
+		}
+		if len(props) != 0 {
+			var value Triple // object fields only
+			content, _ := n.attr("content")
+			datatype = strings.TrimSpace(datatype)
+			switch {
+			case datatype != "":
+				value.DatatypeIRI, err = r.rdfaTerm(n, &local, datatype)
+				if err != nil {
+					return dst, err
+				}
+				switch {
+				case hasContent:
+					value.Object = content
+				case value.DatatypeIRI == rdfXMLLiteral || value.DatatypeIRI == rdfHTML:
+					value.Object = htmlMarkup(n)
+				default:
+					value.Object = htmlText(n)
+				}
+				if value.DatatypeIRI == "" {
+					local.literal(&value) // unknown term
+				}
+
+			case hasContent:
+				value.Object = content
+				local.literal(&value)
+
+			case hasDatatype:
+				value.Object = htmlText(n)
+				local.literal(&value)
+
+			case !hasRelOrRev && resourceIRI != "":
+				value.Object = resourceIRI
+
+			case hasTypeOf && !hasAbout && typedResource != "":
+				value.Object = typedResource
+
+			default:
+				value.Object = htmlText(n)
+				local.literal(&value)
+			}
+
+			for _, prop := range props {
+				if hasInlist {
+					list := local.list(prop)
+					*list = append(*list, value)
+				} else if newSubject != "" {
+					t := value
+					t.SubjectIRI = newSubject
+					t.PredicateIRI = prop
+					dst = append(dst, t)
+				}
+			}
+		}
+	}
+
+	// step 12
+	if !skip && newSubject != "" {
+		for _, inc := range ctx.incomplete {
+			switch inc.direction {
+			case 'l':
+				*inc.list = append(*inc.list, Triple{Object: newSubject})
+			case 'f':
+				if ctx.parentSubject != "" {
+					dst = append(dst, Triple{
+						SubjectIRI:   ctx.parentSubject,
+						PredicateIRI: inc.predicate,
+						Object:       newSubject,
+					})
+				}
+			case 'r':
+				if ctx.parentSubject != "" {
+					dst = append(dst, Triple{
+						SubjectIRI:   newSubject,
+						PredicateIRI: inc.predicate,
+						Object:       ctx.parentSubject,
+					})
+				}
+			}
+		}
+	}
+
+	// step 13
+	next := local
+	if skip {
+		next.parentSubject = ctx.parentSubject
+		next.parentObject = ctx.parentObject
+		next.incomplete = ctx.incomplete
+		next.lists = ctx.lists
+	} else {
+		if newSubject != "" {
+			next.parentSubject = newSubject
+		}
+		switch {
+		case currentObject != "":
+			next.parentObject = currentObject
+		case newSubject != "":
+			next.parentObject = newSubject
+		default:
+			next.parentObject = ctx.parentSubject
+		}
+	}
+	for _, c := range n.children {
+		dst, err = r.rdfaElement(c, &next, false, dst)
+		if err != nil {
+			return dst, err
+		}
+	}
+
+	// step 14
+	if newSubject != "" {
+		for _, predicate := range slices.Sorted(maps.Keys(local.lists)) {
+			list := local.lists[predicate]
+			if ctx.lists[predicate] != list {
+				// instantiated on n
+				dst = r.rdfaList(newSubject, predicate, *list, dst)
+			}
+		}
+	}
+	return dst, nil
+}
+
+// List returns the list mapping of predicate, with lazy initiation.
+func (ctx *rdfaContext) list(predicate string) *[]Triple {
+	list, ok := ctx.lists[predicate]
+	if !ok {
+		list = new([]Triple)
+		ctx.lists[predicate] = list
+	}
+	return list
+}
+
+// Literal sets the datatype of the plain literal in t, with the language in
+// effect, if any.
+func (ctx *rdfaContext) literal(t *Triple) {
+	if ctx.lang == "" {
+		t.DatatypeIRI = XSDString
+	} else {
+		t.DatatypeIRI = rdfLangString
+		t.LangTag = ctx.lang
+	}
+}
+
+// RDFaList appends a collection with the objects of items to dst, with subject
+// and predicate linking to the first cell.
+func (r *HTMLReader) rdfaList(subject, predicate string, items []Triple, dst []Triple) []Triple {
+	if len(items) == 0 {
+		return append(dst, Triple{
+			SubjectIRI:   subject,
+			PredicateIRI: predicate,
+			Object:       rdfNil,
+		})
+	}
+
+	cells := make([]string, len(items))
+	for i := range cells {
+		cells[i] = r.newAnonIRI()
+	}
+	for i, item := range items {
+		item.SubjectIRI = cells[i]
+		item.PredicateIRI = rdfFirst
+		dst = append(dst, item)
+
+		rest := Triple{SubjectIRI: cells[i], PredicateIRI: rdfRest, Object: rdfNil}
+		if i+1 < len(cells) {
+			rest.Object = cells[i+1]
+		}
+		dst = append(dst, rest)
+	}
+	return append(dst, Triple{
+		SubjectIRI:   subject,
+		PredicateIRI: predicate,
+		Object:       cells[0],
+	})
+}
+
+// RDFaPrefixes applies the prefix and xmlns attributes of n on ctx.
+func (r *HTMLReader) rdfaPrefixes(n *htmlNode, ctx *rdfaContext) error {
+	copied := false
+	set := func(prefix, IRI string) {
+		if !copied {
+			copied = true
+			ctx.prefixes = maps.Clone(ctx.prefixes)
+		}
+		ctx.prefixes[strings.ToLower(prefix)] = IRI
+	}
+
+	for _, a := range n.attrs {
+		if prefix, ok := strings.CutPrefix(a.name, "xmlns:"); ok && prefix != "" && prefix != "_" {
+			set(prefix, a.value)
+		}
+	}
+
+	if s, ok := n.attr("prefix"); ok {
+		fields := strings.Fields(s)
+		for i := 0; i+1 < len(fields); i += 2 {
+			prefix, ok := strings.CutSuffix(fields[i], ":")
+			if !ok || prefix == "_" {
+				return r.syntaxErr(n, ErrUnexpectedToken, fmt.Sprintf("malformed prefix mapping %q", fields[i]))
+			}
+			IRI, err := r.rdfaResolve(n, fields[i+1])
+			if err != nil {
+				return err
+			}
+			set(prefix, IRI)
+		}
+		if len(fields)%2 != 0 {
+			return r.syntaxErr(n, ErrUnexpectedToken, fmt.Sprintf("prefix mapping %q without IRI", fields[len(fields)-1]))
+		}
+	}
+	return nil
+}
+
+// RDFaRelOrRev returns the IRIs of the rel or rev attribute of n, with nil for
+// none. When property is present too, then terms are ignored, as specified by
+// HTML+RDFa 1.1.
+func (r *HTMLReader) rdfaRelOrRev(n *htmlNode, ctx *rdfaContext, attr string, hasProperty bool) ([]string, error) {
+	s, ok := n.attr(attr)
+	if !ok {
+		return nil, nil
+	}
+	if hasProperty {
+		var fields []string
+		for _, f := range strings.Fields(s) {
+			if strings.Contains(f, ":") {
+				fields = append(fields, f)
+			}
+		}
+		if len(fields) == 0 {
+			return nil, nil
+		}
+		s = strings.Join(fields, " ")
+	}
+	IRIs, err := r.rdfaTerms(n, ctx, s)
+	if IRIs == nil {
+		IRIs = []string{} // present
+	}
+	return IRIs, err
+}
+
+// RDFaTerms returns the IRIs of whitespace separated TERMorCURIEorAbsIRI
+// values in s. Values which do not map are omitted.
+func (r *HTMLReader) rdfaTerms(n *htmlNode, ctx *rdfaContext, s string) ([]string, error) {
+	var IRIs []string
+	for _, f := range strings.Fields(s) {
+		IRI, err := r.rdfaTerm(n, ctx, f)
+		if err != nil {
+			return nil, err
+		}
+		if IRI != "" {
+			IRIs = append(IRIs, IRI)
+		}
+	}
+	return IRIs, nil
+}
+
+// RDFaTerm returns the IRI of a TERMorCURIEorAbsIRI value, with zero for none.
+func (r *HTMLReader) rdfaTerm(n *htmlNode, ctx *rdfaContext, s string) (IRI string, err error) {
+	if !strings.Contains(s, ":") {
+		if ctx.vocab != "" {
+			return ctx.vocab + s, nil
+		}
+		return rdfaInitialTerms[strings.ToLower(s)], nil
+	}
+	if IRI, ok := r.rdfaCURIE(ctx, s); ok {
+		return IRI, nil
+	}
+	if !isAbsIRI(s) {
+		return "", nil
+	}
+	return s, nil
+}
+
+// RDFaCURIE returns the IRI of a CURIE, if s is one.
+func (r *HTMLReader) rdfaCURIE(ctx *rdfaContext, s string) (IRI string, ok bool) {
+	prefix, reference, ok := strings.Cut(s, ":")
+	if !ok {
+		return "", false
+	}
+	switch prefix {
+	case "_":
+		if reference == "" {
+			reference = "_"
+		}
+		return r.blankIRI(reference), true
+	case "":
+		return xhvNS + reference, true
+	}
+	if strings.HasPrefix(reference, "//") {
+		return "", false // IRI with authority
+	}
+	IRI, ok = ctx.prefixes[strings.ToLower(prefix)]
+	if !ok {
+		return "", false
+	}
+	return IRI + reference, true
+}
+
+// RDFaSafeCURIEOrIRI returns the IRI of a SafeCURIEorCURIEorIRI value, with
+// zero for none.
+func (r *HTMLReader) rdfaSafeCURIEOrIRI(n *htmlNode, ctx *rdfaContext, s string) (IRI string, err error) {
+	s = strings.TrimSpace(s)
+	if len(s) > 1 && s[0] == '[' && s[len(s)-1] == ']' {
+		IRI, _ = r.rdfaCURIE(ctx, s[1:len(s)-1])
+		return IRI, nil // ignored when invalid
+	}
+	if IRI, ok := r.rdfaCURIE(ctx, s); ok {
+		return IRI, nil
+	}
+	return r.rdfaResolve(n, s)
+}
+
+// RDFaResource returns the IRI of the resource, href or src attribute of n, in
+// that order of precedence, with zero for none.
+func (r *HTMLReader) rdfaResource(n *htmlNode, ctx *rdfaContext) (IRI string, err error) {
+	if s, ok := n.attr("resource"); ok {
+		return r.rdfaSafeCURIEOrIRI(n, ctx, s)
+	}
+	if s, ok := n.attr("href"); ok {
+		return r.rdfaResolve(n, strings.TrimSpace(s))
+	}
+	if s, ok := n.attr("src"); ok {
+		return r.rdfaResolve(n, strings.TrimSpace(s))
+	}
+	return "", nil
+}
+
+// RDFaResolve applies the base IRI in effect on relative references.
+func (r *HTMLReader) rdfaResolve(n *htmlNode, ref string) (IRI string, err error) {
+	l, err := url.Parse(ref)
+	if err != nil {
+		return "", r.syntaxErr(n, ErrIllegalIRI, fmt.Sprintf("malformed IRI reference %q", ref))
+	}
+	if l.Scheme != "" {
+		return ref, nil
+	}
+	if r.base == nil {
+		return "", r.syntaxErr(n, ErrNoBaseIRI, fmt.Sprintf("relative reference %q without base IRI", ref))
+	}
+	IRI = r.base.ResolveReference(l).String()
+	if strings.HasSuffix(ref, "#") && !strings.HasSuffix(IRI, "#") {
+		IRI += "#" // empty fragment lost in URL
+	}
+	return IRI, nil
+}
+
+// HTMLText returns the character data in n, including any descendants.
+func htmlText(n *htmlNode) string {
+	var b strings.Builder
+	var walk func(*htmlNode)
+	walk = func(n *htmlNode) {
+		for _, c := range n.children {
+			if c.name == "" {
+				b.WriteString(c.text)
+			} else {
+				walk(c)
+			}
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+// HTMLMarkup returns the content of n as markup.
+func htmlMarkup(n *htmlNode) string {
+	var b strings.Builder
+	var walk func(*htmlNode)
+	walk = func(n *htmlNode) {
+		for _, c := range n.children {
+			if c.name == "" {
+				writeXMLText(&b, []byte(c.text))
+				continue
+			}
+			b.WriteByte('<')
+			b.WriteString(c.name)
+			for _, a := range c.attrs {
+				b.WriteByte(' ')
+				b.WriteString(a.name)
+				b.WriteString(`="`)
+				writeXMLAttrValue(&b, a.value)
+				b.WriteByte('"')
+			}
+			b.WriteByte('>')
+			if c.name == "script" || c.name == "style" {
+				b.WriteString(c.text)
+			} else {
+				walk(c)
+			}
+			if !htmlVoidElements[c.name] {
+				b.WriteString("</")
+				b.WriteString(c.name)
+				b.WriteByte('>')
+			}
+		}
+	}
+	walk(n)
+	return b.String()
+}
@@ -0,0 +1,1171 @@
+package tripn
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// RDF vocabulary for RDF/XML.
+const (
+	rdfNS            = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	rdfType          = "http://www.w3.org/1999/02/22-rdf-syntax-ns#type"
+	rdfLangString    = "http://www.w3.org/1999/02/22-rdf-syntax-ns#langString"
+	rdfDirLangString = "http://www.w3.org/1999/02/22-rdf-syntax-ns#dirLangString"
+	rdfXMLLiteral    = "http://www.w3.org/1999/02/22-rdf-syntax-ns#XMLLiteral"
+	rdfStatement     = "http://www.w3.org/1999/02/22-rdf-syntax-ns#Statement"
+	rdfSubject       = "http://www.w3.org/1999/02/22-rdf-syntax-ns#subject"
+	rdfPredicate     = "http://www.w3.org/1999/02/22-rdf-syntax-ns#predicate"
+	rdfObject        = "http://www.w3.org/1999/02/22-rdf-syntax-ns#object"
+)
+
+// RDFXMLReader parses RDF/XML. Node elements without rdf:about or rdf:ID get a
+// new Skolem IRI, and so do property elements with parse type "Resource" and
+// the cells of parse type "Collection". Each rdf:nodeID gets a Skolem IRI of
+// its own, which is the same for all of its occurrences in the document. An
+// rdf:ID on a property element reifies the statement.
+type RDFXMLReader struct {
+	// The XML decoder applies buffering when R is not an io.ByteReader.
+	R io.Reader
+
+	// BaseIRI is the document base, which an xml:base attribute replaces
+	// within the scope of its element. Users may initialize the base IRI
+	// to the data location.
+	BaseIRI *url.URL
+
+	dec *xml.Decoder // lazy initiation
+
+	scopes   []xmlScope // open elements
+	bindings []xmlNS    // namespace declarations in effect
+
+	rootSeen bool // root element encountered
+	inRDF    bool // within an rdf:RDF root element
+
+	tokLineNo int   // input position of last token
+	tokOffset int64 // input position of last token
+
+	skolemizer // blank node IRIs
+}
+
+// XMLScope has the context of an open element.
+type xmlScope struct {
+	name  xml.Name // as is, without namespace resolution
+	base  *url.URL // xml:base in effect, without fragment
+	lang  string   // xml:lang in effect, in lower case
+	nsLen int      // number of bindings before element
+}
+
+// XMLNS is a namespace declaration.
+type xmlNS struct {
+	prefix string // zero for default namespace
+	IRI    string
+}
+
+// SyntaxErr is a convenience constructor. The position is that of the last
+// token read.
+func (r *RDFXMLReader) syntaxErr(kind error, reason string) error {
+	return &SyntaxError{
+		LineNo:  r.tokLineNo,
+		Offset:  r.tokOffset,
+		Reason:  reason,
+		Err:     kind,
+		grammar: rdfXMLGrammar,
+	}
+}
+
+// Token returns the next token from the XML decoder.
+func (r *RDFXMLReader) token() (xml.Token, error) {
+	r.tokLineNo, _ = r.dec.InputPos()
+	r.tokOffset = r.dec.InputOffset()
+
+	tok, err := r.dec.RawToken()
+	if err != nil {
+		var e *xml.SyntaxError
+		if errors.As(err, &e) {
+			return nil, &SyntaxError{
+				LineNo:  e.Line,
+				Offset:  -1,
+				Reason:  e.Msg,
+				Err:     ErrMalformedXML,
+				grammar: rdfXMLGrammar,
+			}
+		}
+		return nil, err
+	}
+	return tok, nil
+}
+
+// TokenContinue is like token, yet it expects more to follow.
+func (r *RDFXMLReader) tokenContinue() (xml.Token, error) {
+	tok, err := r.token()
+	if err != nil && errors.Is(err, io.EOF) {
+		err = fmt.Errorf("%w: element not closed", io.ErrUnexpectedEOF)
+	}
+	return tok, err
+}
+
+// ReadAppend adds the triples of the next node element on the top level to dst,
+// and it returns the extended buffer. Reads match the order of appearance with
+// the nested nodes, if any, before their enclosing statement.
+//
+// SyntaxError is used for malformed RDF/XML exclusively, which includes
+// malformed XML. Stream errors pass as is, with the exception of io.EOF.
+// Incomplete records at the end of stream are addressed with
+// io.ErrUnexpectedEOF instead.
+func (r *RDFXMLReader) ReadAppend(dst []Triple) ([]Triple, error) {
+	if r.dec == nil {
+		r.dec = xml.NewDecoder(r.R)
+	}
+
+	for {
+		tok, err := r.token()
+		if err != nil {
+			if errors.Is(err, io.EOF) && (r.inRDF || !r.rootSeen) {
+				err = fmt.Errorf("%w: root element not closed", io.ErrUnexpectedEOF)
+			}
+			return dst, err
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if r.inRDF {
+				_, err = r.nodeElt(tok, &dst)
+				return dst, err
+			}
+			if r.rootSeen {
+				return dst, r.syntaxErr(ErrMalformedXML, "more than one root element")
+			}
+			r.rootSeen = true
+
+			name, attrs, err := r.pushScope(tok)
+			if err != nil {
+				return dst, err
+			}
+			if name != rdfNS+"RDF" {
+				// node element as document root
+				_, err = r.afterNodeStart(name, attrs, &dst)
+				return dst, err
+			}
+			if len(attrs) != 0 {
+				return dst, r.syntaxErr(ErrUnexpectedToken, "attribute on rdf:RDF element")
+			}
+			r.inRDF = true
+
+		case xml.EndElement:
+			err = r.popScope(tok)
+			if err != nil {
+				return dst, err
+			}
+			r.inRDF = false
+
+		case xml.CharData:
+			if !isXMLSpace(tok) {
+				return dst, r.syntaxErr(ErrUnexpectedToken, "text outside of node element")
+			}
+		}
+	}
+}
+
+// IsXMLSpace returns whether text consists of whitespace exclusively.
+func isXMLSpace(text []byte) bool {
+	for _, c := range text {
+		switch c {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// PushScope enters the element of start. The return has the namespace of the
+// element resolved, with the attributes for RDF (without the XML reserved).
+// Attribute names get their Space resolved to the namespace IRI.
+func (r *RDFXMLReader) pushScope(start xml.StartElement) (name string, attrs []xml.Attr, err error) {
+	s := xmlScope{
+		name:  start.Name,
+		base:  r.BaseIRI,
+		nsLen: len(r.bindings),
+	}
+	if n := len(r.scopes); n != 0 {
+		s.base = r.scopes[n-1].base
+		s.lang = r.scopes[n-1].lang
+	}
+
+	for _, a := range start.Attr {
+		switch {
+		case a.Name.Space == "" && a.Name.Local == "xmlns":
+			r.bindings = append(r.bindings, xmlNS{"", a.Value})
+		case a.Name.Space == "xmlns":
+			r.bindings = append(r.bindings, xmlNS{a.Name.Local, a.Value})
+		case a.Name.Space == "xml":
+			switch a.Name.Local {
+			case "base":
+				u, err := url.Parse(a.Value)
+				if err != nil {
+					return "", nil, r.syntaxErr(ErrIllegalIRI, fmt.Sprintf("malformed xml:base %q", a.Value))
+				}
+				if s.base != nil {
+					u = s.base.ResolveReference(u)
+				} else if !u.IsAbs() {
+					return "", nil, r.syntaxErr(ErrNoBaseIRI, fmt.Sprintf("relative xml:base %q without base IRI", a.Value))
+				}
+				u.Fragment, u.RawFragment = "", ""
+				s.base = u
+			case "lang":
+				s.lang = strings.ToLower(a.Value)
+			}
+		default:
+			attrs = append(attrs, a)
+		}
+	}
+	r.scopes = append(r.scopes, s)
+
+	ns, ok := r.lookupNS(start.Name.Space)
+	switch {
+	case !ok && start.Name.Space != "":
+		return "", nil, r.syntaxErr(ErrUndefinedPrefix, fmt.Sprintf("undefined namespace prefix %q", start.Name.Space))
+	case ns == "":
+		return "", nil, r.syntaxErr(ErrUnexpectedToken, fmt.Sprintf("element <%s> without namespace", start.Name.Local))
+	}
+	name = ns + start.Name.Local
+
+	for i := range attrs {
+		a := &attrs[i]
+		if a.Name.Space != "" {
+			ns, ok := r.lookupNS(a.Name.Space)
+			if !ok {
+				return "", nil, r.syntaxErr(ErrUndefinedPrefix, fmt.Sprintf("undefined namespace prefix %q", a.Name.Space))
+			}
+			a.Name.Space = ns
+			continue
+		}
+
+		// “… the attribute names ID, about, resource, parseType or
+		// type …” without namespace map to RDF for compatibility.
+		switch a.Name.Local {
+		case "ID", "about", "resource", "parseType", "type":
+			a.Name.Space = rdfNS
+		default:
+			return "", nil, r.syntaxErr(ErrUnexpectedToken, fmt.Sprintf("attribute %q without namespace", a.Name.Local))
+		}
+	}
+	return name, attrs, nil
+}
+
+// PopScope leaves the element of end.
+func (r *RDFXMLReader) popScope(end xml.EndElement) error {
+	s := r.scopes[len(r.scopes)-1]
+	if end.Name != s.name {
+		return r.syntaxErr(ErrMalformedXML, fmt.Sprintf("element <%s> closed by </%s>", rawXMLName(s.name), rawXMLName(end.Name)))
+	}
+	r.bindings = r.bindings[:s.nsLen]
+	r.scopes = r.scopes[:len(r.scopes)-1]
+	return nil
+}
+
+// RawXMLName returns the name as it appears in the document.
+func rawXMLName(n xml.Name) string {
+	if n.Space == "" {
+		return n.Local
+	}
+	return n.Space + ":" + n.Local
+}
+
+// LookupNS returns the namespace IRI of prefix in effect.
+func (r *RDFXMLReader) lookupNS(prefix string) (IRI string, ok bool) {
+	for i := len(r.bindings) - 1; i >= 0; i-- {
+		if r.bindings[i].prefix == prefix {
+			return r.bindings[i].IRI, true
+		}
+	}
+	return "", false
+}
+
+// Resolve applies the base IRI in effect on relative references.
+func (r *RDFXMLReader) resolve(ref string) (IRI string, err error) {
+	l, err := url.Parse(ref)
+	if err != nil {
+		return "", r.syntaxErr(ErrIllegalIRI, fmt.Sprintf("malformed IRI reference %q", ref))
+	}
+	if l.Scheme != "" {
+		return ref, nil
+	}
+	base := r.scopes[len(r.scopes)-1].base
+	if base == nil {
+		return "", r.syntaxErr(ErrNoBaseIRI, fmt.Sprintf("relative reference %q without base IRI", ref))
+	}
+	IRI = base.ResolveReference(l).String()
+	if strings.HasSuffix(ref, "#") && !strings.HasSuffix(IRI, "#") {
+		IRI += "#" // empty fragment lost in URL
+	}
+	return IRI, nil
+}
+
+// IsNCName returns whether s is a valid XML name without colon, as required for
+// the values of rdf:ID and rdf:nodeID.
+func isNCName(s string) bool {
+	for i, c := range s {
+		switch {
+		case c == '_', isPNCharsBase(c):
+			continue
+		case i != 0 && (c == '.' || isPNChars(c)):
+			continue
+		}
+		return false
+	}
+	return s != ""
+}
+
+// IDIRI returns the IRI of an rdf:ID value.
+func (r *RDFXMLReader) idIRI(id string) (IRI string, err error) {
+	if !isNCName(id) {
+		return "", r.syntaxErr(ErrIllegalChar, fmt.Sprintf("illegal rdf:ID %q", id))
+	}
+	return r.resolve("#" + id)
+}
+
+// NodeIDIRI returns the Skolem IRI of an rdf:nodeID value.
+func (r *RDFXMLReader) nodeIDIRI(id string) (IRI string, err error) {
+	if !isNCName(id) {
+		return "", r.syntaxErr(ErrIllegalChar, fmt.Sprintf("illegal rdf:nodeID %q", id))
+	}
+	return r.blankIRI(id), nil
+}
+
+// Literal sets the object of t to text, with the datatype when not zero, and
+// with the language in effect otherwise.
+func (r *RDFXMLReader) literal(t *Triple, text, datatype string) {
+	t.Object = text
+	switch lang := r.scopes[len(r.scopes)-1].lang; {
+	case datatype != "":
+		t.DatatypeIRI = datatype
+	case lang != "":
+		t.DatatypeIRI = rdfLangString
+		t.LangTag = lang
+	default:
+		t.DatatypeIRI = XSDString
+	}
+}
+
+// NodeElt reads the node element of start, including its end, and it returns
+// the IRI of the node. The triples of the node go into dstp.
+func (r *RDFXMLReader) nodeElt(start xml.StartElement, dstp *[]Triple) (IRI string, err error) {
+	name, attrs, err := r.pushScope(start)
+	if err != nil {
+		return "", err
+	}
+	return r.afterNodeStart(name, attrs, dstp)
+}
+
+// AfterNodeStart continues with the node element named name, after pushScope.
+func (r *RDFXMLReader) afterNodeStart(name string, attrs []xml.Attr, dstp *[]Triple) (IRI string, err error) {
+	switch name {
+	case rdfNS + "RDF", rdfNS + "ID", rdfNS + "about", rdfNS + "bagID",
+		rdfNS + "parseType", rdfNS + "resource", rdfNS + "nodeID",
+		rdfNS + "li", rdfNS + "aboutEach", rdfNS + "aboutEachPrefix",
+		rdfNS + "datatype":
+		return "", r.syntaxErr(ErrUnexpectedToken, fmt.Sprintf("illegal node element <%s>", rawXMLName(r.scopes[len(r.scopes)-1].name)))
+	}
+
+	// node identification is optional
+	var props []xml.Attr
+	for _, a := range attrs {
+		var s string
+		switch a.Name.Space + a.Name.Local {
+		case rdfNS + "about":
+			s, err = r.resolve(a.Value)
+		case rdfNS + "ID":
+			s, err = r.idIRI(a.Value)
+		case rdfNS + "nodeID":
+			s, err = r.nodeIDIRI(a.Value)
+		default:
+			props = append(props, a)
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if IRI != "" {
+			return "", r.syntaxErr(ErrUnexpectedToken, "node element with more than one of rdf:about, rdf:ID and rdf:nodeID")
+		}
+		IRI = s
+	}
+	if IRI == "" {
+		IRI = r.newAnonIRI()
+	}
+
+	if name != rdfNS+"Description" {
+		// typed node element
+		*dstp = append(*dstp, Triple{
+			SubjectIRI:   IRI,
+			PredicateIRI: rdfType,
+			Object:       name,
+		})
+	}
+	err = r.propertyAttrs(IRI, props, dstp)
+	if err != nil {
+		return "", err
+	}
+	return IRI, r.propertyElts(IRI, dstp)
+}
+
+// PropertyAttrs adds the property attributes of a node.
+func (r *RDFXMLReader) propertyAttrs(subject string, attrs []xml.Attr, dstp *[]Triple) error {
+	for _, a := range attrs {
+		t := Triple{
+			SubjectIRI:   subject,
+			PredicateIRI: a.Name.Space + a.Name.Local,
+		}
+		switch t.PredicateIRI {
+		case rdfType:
+			var err error
+			t.Object, err = r.resolve(a.Value)
+			if err != nil {
+				return err
+			}
+		case rdfNS + "Description", rdfNS + "RDF", rdfNS + "ID",
+			rdfNS + "about", rdfNS + "bagID", rdfNS + "parseType",
+			rdfNS + "resource", rdfNS + "nodeID", rdfNS + "li",
+			rdfNS + "aboutEach", rdfNS + "aboutEachPrefix", rdfNS + "datatype":
+			return r.syntaxErr(ErrUnexpectedToken, fmt.Sprintf("illegal property attribute %s", t.PredicateIRI))
+		default:
+			r.literal(&t, a.Value, "")
+		}
+		*dstp = append(*dstp, t)
+	}
+	return nil
+}
+
+// PropertyElts reads the content of a node element, including its end.
+func (r *RDFXMLReader) propertyElts(subject string, dstp *[]Triple) error {
+	liNo := 0 // rdf:li count
+	for {
+		tok, err := r.tokenContinue()
+		if err != nil {
+			return err
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			err = r.propertyElt(subject, tok, &liNo, dstp)
+			if err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return r.popScope(tok)
+		case xml.CharData:
+			if !isXMLSpace(tok) {
+				return r.syntaxErr(ErrUnexpectedToken, "text in node element")
+			}
+		}
+	}
+}
+
+// PropertyElt reads the property element of start, including its end.
+func (r *RDFXMLReader) propertyElt(subject string, start xml.StartElement, liNo *int, dstp *[]Triple) error {
+	name, attrs, err := r.pushScope(start)
+	if err != nil {
+		return err
+	}
+	switch name {
+	case rdfNS + "li":
+		*liNo++
+		name = rdfNS + "_" + strconv.Itoa(*liNo)
+	case rdfNS + "Description", rdfNS + "RDF", rdfNS + "ID",
+		rdfNS + "about", rdfNS + "bagID", rdfNS + "parseType",
+		rdfNS + "resource", rdfNS + "nodeID", rdfNS + "aboutEach",
+		rdfNS + "aboutEachPrefix", rdfNS + "datatype":
+		return r.syntaxErr(ErrUnexpectedToken, fmt.Sprintf("illegal property element <%s>", rawXMLName(start.Name)))
+	}
+	t := Triple{
+		SubjectIRI:   subject,
+		PredicateIRI: name,
+	}
+
+	var reifyIRI, objectIRI, datatype, parseType string
+	var hasParseType bool
+	var props []xml.Attr
+	for _, a := range attrs {
+		switch a.Name.Space + a.Name.Local {
+		case rdfNS + "ID":
+			reifyIRI, err = r.idIRI(a.Value)
+		case rdfNS + "parseType":
+			parseType, hasParseType = a.Value, true
+		case rdfNS + "resource", rdfNS + "nodeID":
+			if objectIRI != "" {
+				return r.syntaxErr(ErrUnexpectedToken, "property element with both rdf:resource and rdf:nodeID")
+			}
+			if a.Name.Local == "resource" {
+				objectIRI, err = r.resolve(a.Value)
+			} else {
+				objectIRI, err = r.nodeIDIRI(a.Value)
+			}
+		case rdfNS + "datatype":
+			datatype, err = r.resolve(a.Value)
+		default:
+			props = append(props, a)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if hasParseType && (objectIRI != "" || datatype != "" || len(props) != 0) {
+		return r.syntaxErr(ErrUnexpectedToken, "rdf:parseType with rdf:resource, rdf:nodeID, rdf:datatype or property attributes")
+	}
+
+	switch {
+	case !hasParseType:
+		err = r.propertyContent(&t, objectIRI, datatype, props, dstp)
+	case parseType == "Resource":
+		t.Object = r.newAnonIRI()
+		err = r.propertyElts(t.Object, dstp)
+	case parseType == "Collection":
+		t.Object, err = r.collection(dstp)
+	default: // "Literal", and any other value
+		t.Object, err = r.xmlLiteral()
+		t.DatatypeIRI = rdfXMLLiteral
+	}
+	if err != nil {
+		return err
+	}
+	*dstp = append(*dstp, t)
+
+	if reifyIRI != "" {
+		*dstp = append(*dstp,
+			Triple{SubjectIRI: reifyIRI, PredicateIRI: rdfType, Object: rdfStatement},
+			Triple{SubjectIRI: reifyIRI, PredicateIRI: rdfSubject, Object: t.SubjectIRI},
+			Triple{SubjectIRI: reifyIRI, PredicateIRI: rdfPredicate, Object: t.PredicateIRI},
+			Triple{SubjectIRI: reifyIRI, PredicateIRI: rdfObject, Object: t.Object, DatatypeIRI: t.DatatypeIRI, LangTag: t.LangTag, BaseDir: t.BaseDir},
+		)
+	}
+	return nil
+}
+
+// PropertyContent reads the content of a property element without parse type,
+// including its end. The object of the property goes into t.
+func (r *RDFXMLReader) propertyContent(t *Triple, objectIRI, datatype string, props []xml.Attr, dstp *[]Triple) error {
+	var text strings.Builder
+	for {
+		tok, err := r.tokenContinue()
+		if err != nil {
+			return err
+		}
+
+		switch tok := tok.(type) {
+		case xml.CharData:
+			text.Write(tok)
+
+		case xml.StartElement:
+			if objectIRI != "" || datatype != "" || len(props) != 0 {
+				return r.syntaxErr(ErrUnexpectedToken, "node element in property element with rdf:resource, rdf:nodeID, rdf:datatype or property attributes")
+			}
+			if !isXMLSpace([]byte(text.String())) {
+				return r.syntaxErr(ErrUnexpectedToken, "both text and node element in property element")
+			}
+			t.Object, err = r.nodeElt(tok, dstp)
+			if err != nil {
+				return err
+			}
+			return r.afterObjectNode()
+
+		case xml.EndElement:
+			if objectIRI == "" && len(props) == 0 {
+				r.literal(t, text.String(), datatype)
+				return r.popScope(tok)
+			}
+
+			// empty property element
+			if datatype != "" {
+				return r.syntaxErr(ErrUnexpectedToken, "rdf:datatype with rdf:resource, rdf:nodeID or property attributes")
+			}
+			if !isXMLSpace([]byte(text.String())) {
+				return r.syntaxErr(ErrUnexpectedToken, "text in property element with rdf:resource, rdf:nodeID or property attributes")
+			}
+			if objectIRI == "" {
+				objectIRI = r.newAnonIRI()
+			}
+			t.Object = objectIRI
+			err = r.propertyAttrs(objectIRI, props, dstp)
+			if err != nil {
+				return err
+			}
+			return r.popScope(tok)
+		}
+	}
+}
+
+// AfterObjectNode reads the end of a property element after its node element.
+func (r *RDFXMLReader) afterObjectNode() error {
+	for {
+		tok, err := r.tokenContinue()
+		if err != nil {
+			return err
+		}
+
+		switch tok := tok.(type) {
+		case xml.CharData:
+			if !isXMLSpace(tok) {
+				return r.syntaxErr(ErrUnexpectedToken, "both text and node element in property element")
+			}
+		case xml.StartElement:
+			return r.syntaxErr(ErrUnexpectedToken, "more than one node element in property element")
+		case xml.EndElement:
+			return r.popScope(tok)
+		}
+	}
+}
+
+// Collection reads the content of a property element with parse type
+// "Collection", including its end. The return is the first list cell, or
+// rdf:nil for none.
+func (r *RDFXMLReader) collection(dstp *[]Triple) (firstIRI string, err error) {
+	var nodeIRIs []string
+ReadNodes:
+	for {
+		tok, err := r.tokenContinue()
+		if err != nil {
+			return "", err
+		}
+
+		switch tok := tok.(type) {
+		case xml.CharData:
+			if !isXMLSpace(tok) {
+				return "", r.syntaxErr(ErrUnexpectedToken, "text in collection")
+			}
+		case xml.StartElement:
+			IRI, err := r.nodeElt(tok, dstp)
+			if err != nil {
+				return "", err
+			}
+			nodeIRIs = append(nodeIRIs, IRI)
+		case xml.EndElement:
+			err = r.popScope(tok)
+			if err != nil {
+				return "", err
+			}
+			break ReadNodes
+		}
+	}
+
+	nextIRI := rdfNil
+	if len(nodeIRIs) != 0 {
+		nextIRI = r.newAnonIRI()
+	}
+	firstIRI = nextIRI
+	for i, IRI := range nodeIRIs {
+		cellIRI := nextIRI
+		nextIRI = rdfNil
+		if i+1 < len(nodeIRIs) {
+			nextIRI = r.newAnonIRI()
+		}
+		*dstp = append(*dstp,
+			Triple{SubjectIRI: cellIRI, PredicateIRI: rdfFirst, Object: IRI},
+			Triple{SubjectIRI: cellIRI, PredicateIRI: rdfRest, Object: nextIRI},
+		)
+	}
+	return firstIRI, nil
+}
+
+// XMLLiteral reads the content of a property element with parse type "Literal",
+// including its end. The content is returned as XML, with declarations for any
+// of the namespaces in use which were declared outside of the content.
+func (r *RDFXMLReader) xmlLiteral() (string, error) {
+	var b strings.Builder
+	var open []xml.Name     // elements in content
+	var declared [][]string // namespace prefixes per element in content
+
+	isDeclared := func(prefix string) bool {
+		if prefix == "xml" || prefix == "xmlns" {
+			return true
+		}
+		for _, prefixes := range declared {
+			for _, p := range prefixes {
+				if p == prefix {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	for {
+		tok, err := r.tokenContinue()
+		if err != nil {
+			return "", err
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			var prefixes []string
+			for _, a := range tok.Attr {
+				switch {
+				case a.Name.Space == "" && a.Name.Local == "xmlns":
+					prefixes = append(prefixes, "")
+				case a.Name.Space == "xmlns":
+					prefixes = append(prefixes, a.Name.Local)
+				}
+			}
+			declared = append(declared, prefixes)
+			open = append(open, tok.Name)
+
+			b.WriteByte('<')
+			b.WriteString(rawXMLName(tok.Name))
+			// namespaces from outside the content
+			used := []string{tok.Name.Space}
+			for _, a := range tok.Attr {
+				if a.Name.Space != "" {
+					used = append(used, a.Name.Space)
+				}
+			}
+			for _, prefix := range used {
+				if isDeclared(prefix) {
+					continue
+				}
+				IRI, ok := r.lookupNS(prefix)
+				if !ok {
+					if prefix == "" {
+						continue // no namespace
+					}
+					return "", r.syntaxErr(ErrUndefinedPrefix, fmt.Sprintf("undefined namespace prefix %q", prefix))
+				}
+				if prefix == "" {
+					b.WriteString(` xmlns="`)
+				} else {
+					b.WriteString(` xmlns:`)
+					b.WriteString(prefix)
+					b.WriteString(`="`)
+				}
+				writeXMLAttrValue(&b, IRI)
+				b.WriteByte('"')
+				declared[len(declared)-1] = append(declared[len(declared)-1], prefix)
+			}
+			for _, a := range tok.Attr {
+				b.WriteByte(' ')
+				b.WriteString(rawXMLName(a.Name))
+				b.WriteString(`="`)
+				writeXMLAttrValue(&b, a.Value)
+				b.WriteByte('"')
+			}
+			b.WriteByte('>')
+
+		case xml.EndElement:
+			if len(open) == 0 {
+				return b.String(), r.popScope(tok)
+			}
+			if tok.Name != open[len(open)-1] {
+				return "", r.syntaxErr(ErrMalformedXML, fmt.Sprintf("element <%s> closed by </%s>", rawXMLName(open[len(open)-1]), rawXMLName(tok.Name)))
+			}
+			open = open[:len(open)-1]
+			declared = declared[:len(declared)-1]
+			b.WriteString("</")
+			b.WriteString(rawXMLName(tok.Name))
+			b.WriteByte('>')
+
+		case xml.CharData:
+			writeXMLText(&b, tok)
+
+		case xml.ProcInst:
+			b.WriteString("<?")
+			b.WriteString(tok.Target)
+			if len(tok.Inst) != 0 {
+				b.WriteByte(' ')
+				b.Write(tok.Inst)
+			}
+			b.WriteString("?>")
+		}
+	}
+}
+
+// WriteXMLText escapes text as canonical XML character data.
+func writeXMLText(b *strings.Builder, text []byte) {
+	for _, c := range text {
+		switch c {
+		case '&':
+			b.WriteString("&amp;")
+		case '<':
+			b.WriteString("&lt;")
+		case '>':
+			b.WriteString("&gt;")
+		case '\r':
+			b.WriteString("&#xD;")
+		default:
+			b.WriteByte(c)
+		}
+	}
+}
+
+// WriteXMLAttrValue escapes s as canonical XML attribute value in double quotes.
+func writeXMLAttrValue(b *strings.Builder, s string) {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '&':
+			b.WriteString("&amp;")
+		case '<':
+			b.WriteString("&lt;")
+		case '"':
+			b.WriteString("&quot;")
+		case '\t':
+			b.WriteString("&#x9;")
+		case '\n':
+			b.WriteString("&#xA;")
+		case '\r':
+			b.WriteString("&#xD;")
+		default:
+			b.WriteByte(c)
+		}
+	}
+}
+
+// RDFXMLWriter encodes RDF/XML. Statements are grouped per subject in a node
+// element, which is typed with one of its rdf:type objects when possible, and
+// which falls back to rdf:Description otherwise. Output is written on Close,
+// as grouping needs all statements.
+//
+// Skolem IRIs, as minted by Reader, are written as blank nodes. The ones with a
+// single reference get nested in their property element. The ones with
+// multiple references get an rdf:nodeID instead.
+//
+// Literals of type rdf:XMLLiteral are written as is, with parse type "Literal",
+// when their content is well-formed XML. Other content falls back to an escaped
+// text with rdf:datatype.
+type RDFXMLWriter struct {
+	W io.Writer
+
+	// Prefixes maps labels to namespace IRIs, as in "ex" to
+	// "http://example.com/ns#". Namespaces without a label get one
+	// generated, as in "ns1". Only the namespaces in use get declared.
+	Prefixes map[string]string
+
+	Order TurtleOrder
+
+	triples []Triple
+}
+
+// WriteTriple buffers t. Predicate IRIs must split into a namespace and an XML
+// name for the property element. Base directions, quoted triples, and control
+// characters can not be expressed in RDF/XML either. Such triples get an error
+// without any effect.
+func (w *RDFXMLWriter) WriteTriple(t Triple) error {
+	t, err := checkNTriple(t)
+	if err != nil {
+		return err
+	}
+	if _, _, ok := splitXMLName(t.PredicateIRI); !ok || isRDFXMLSyntaxTerm(t.PredicateIRI) {
+		return fmt.Errorf("RDF/XML can not express predicate %q as an XML QName", t.PredicateIRI)
+	}
+	if t.BaseDir != "" {
+		return fmt.Errorf("RDF/XML can not express the base direction of %s", t)
+	}
+	if IsQuotedTriple(t.SubjectIRI) || t.DatatypeIRI == "" && IsQuotedTriple(t.Object) {
+		return fmt.Errorf("RDF/XML can not express the quoted triple of %s", t)
+	}
+	for _, c := range t.Object {
+		if !isXMLChar(c) {
+			return fmt.Errorf("RDF/XML can not express character %q of %s", c, t)
+		}
+	}
+	w.triples = append(w.triples, t)
+	return nil
+}
+
+// Close writes the document with all triples buffered. It does not close W.
+func (w *RDFXMLWriter) Close() error {
+	enc := rdfXMLEncoder{prefixPerNS: map[string]string{rdfNS: "rdf"}}
+	for label, IRI := range w.Prefixes {
+		if !isNCName(label) || strings.HasPrefix(strings.ToLower(label), "xml") {
+			return fmt.Errorf("RDF/XML can not express prefix label %q", label)
+		}
+		if IRI == rdfNS || label == "rdf" {
+			continue
+		}
+		// label order resolves ties
+		if l, ok := enc.prefixPerNS[IRI]; !ok || label < l {
+			enc.prefixPerNS[IRI] = label
+		}
+	}
+
+	subjects := groupTurtle(w.triples, w.Order)
+	w.triples = nil
+	enc.scan(subjects, "")
+	g := newTurtleGraph(&enc.turtleEncoder, subjects)
+	var body strings.Builder
+	for _, s := range subjects {
+		if !g.inline[s.IRI] {
+			enc.nodeElt(&body, g, s, "\t")
+		}
+	}
+	// references in a cycle need a label
+	for _, s := range subjects {
+		if !g.done[s.IRI] {
+			delete(g.inline, s.IRI)
+			enc.nodeElt(&body, g, s, "\t")
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<rdf:RDF xmlns:rdf=\"" + rdfNS + "\"")
+	namespaces := make([]string, 0, len(enc.declared))
+	for IRI := range enc.declared {
+		namespaces = append(namespaces, IRI)
+	}
+	slices.SortFunc(namespaces, func(a, b string) int {
+		return strings.Compare(enc.prefixPerNS[a], enc.prefixPerNS[b])
+	})
+	for _, IRI := range namespaces {
+		if IRI == rdfNS {
+			continue
+		}
+		b.WriteString("\n\txmlns:" + enc.prefixPerNS[IRI] + "=\"")
+		writeXMLAttrValue(&b, IRI)
+		b.WriteByte('"')
+	}
+	b.WriteString(">\n")
+	b.WriteString(body.String())
+	b.WriteString("</rdf:RDF>\n")
+
+	_, err := io.WriteString(w.W, b.String())
+	return err
+}
+
+// RDFXMLEncoder holds the notation state of an RDF/XML document.
+type rdfXMLEncoder struct {
+	turtleEncoder // blank node analysis
+
+	prefixPerNS map[string]string // label per namespace IRI
+	declared    map[string]bool   // namespace IRIs written
+}
+
+// QName returns the qualified XML name of IRI, with false for none.
+func (e *rdfXMLEncoder) qName(IRI string) (string, bool) {
+	namespace, local, ok := splitXMLName(IRI)
+	if !ok {
+		return "", false
+	}
+	label, ok := e.prefixPerNS[namespace]
+	if !ok {
+		taken := make(map[string]bool, len(e.prefixPerNS))
+		for _, l := range e.prefixPerNS {
+			taken[l] = true
+		}
+		for n := 1; ; n++ {
+			label = "ns" + strconv.Itoa(n)
+			if !taken[label] {
+				break
+			}
+		}
+		e.prefixPerNS[namespace] = label
+	}
+	if e.declared == nil {
+		e.declared = make(map[string]bool)
+	}
+	e.declared[namespace] = true
+	return label + ":" + local, true
+}
+
+// NodeElt writes the node element of s.
+func (e *rdfXMLEncoder) nodeElt(b *strings.Builder, g *turtleGraph, s *turtleSubject, indent string) {
+	g.done[s.IRI] = true
+
+	// typed node element when possible
+	name, typeIRI := "rdf:Description", ""
+TypeSearch:
+	for _, p := range s.predicates {
+		if p.IRI != rdfType {
+			continue
+		}
+		for _, o := range p.objects {
+			if o.DatatypeIRI != "" || IsSkolemIRI(o.Object) || isRDFXMLSyntaxTerm(o.Object) {
+				continue
+			}
+			if qName, ok := e.qName(o.Object); ok {
+				name, typeIRI = qName, o.Object
+				break TypeSearch
+			}
+		}
+	}
+
+	b.WriteString(indent + "<" + name)
+	switch {
+	case g.inline[s.IRI] || g.refs[s.IRI] == 0 && e.isAnonymous(s.IRI):
+		break // anonymous
+	case IsSkolemIRI(s.IRI):
+		b.WriteString(` rdf:nodeID="` + e.label(s.IRI) + `"`)
+	default:
+		b.WriteString(` rdf:about="`)
+		writeXMLAttrValue(b, s.IRI)
+		b.WriteByte('"')
+	}
+
+	hasProps := false
+	for _, p := range s.predicates {
+		for _, o := range p.objects {
+			if p.IRI == rdfType && o.DatatypeIRI == "" && o.Object == typeIRI {
+				continue // in node element name
+			}
+			if !hasProps {
+				hasProps = true
+				b.WriteString(">\n")
+			}
+			e.propertyElt(b, g, p.IRI, o, indent+"\t")
+		}
+	}
+	if !hasProps {
+		b.WriteString("/>\n")
+	} else {
+		b.WriteString(indent + "</" + name + ">\n")
+	}
+}
+
+// PropertyElt writes the property element of predicate with the object of t.
+func (e *rdfXMLEncoder) propertyElt(b *strings.Builder, g *turtleGraph, predicate string, t Triple, indent string) {
+	name, _ := e.qName(predicate) // verified by WriteTriple
+	b.WriteString(indent + "<" + name)
+
+	switch {
+	case t.DatatypeIRI == "":
+		switch {
+		case g.inline[t.Object] && !g.done[t.Object]:
+			s := g.subjectIndex[t.Object]
+			if s == nil {
+				g.done[t.Object] = true
+				b.WriteString(" rdf:parseType=\"Resource\"/>\n")
+				return
+			}
+			b.WriteString(">\n")
+			e.nodeElt(b, g, s, indent+"\t")
+			b.WriteString(indent + "</" + name + ">\n")
+			return
+		case IsSkolemIRI(t.Object):
+			b.WriteString(` rdf:nodeID="` + e.label(t.Object) + `"/>` + "\n")
+			return
+		}
+		b.WriteString(` rdf:resource="`)
+		writeXMLAttrValue(b, t.Object)
+		b.WriteString("\"/>\n")
+		return
+
+	case t.DatatypeIRI == rdfXMLLiteral && isXMLContent(t.Object):
+		b.WriteString(` rdf:parseType="Literal">`)
+		b.WriteString(t.Object)
+		b.WriteString("</" + name + ">\n")
+		return
+	case t.LangTag != "":
+		b.WriteString(` xml:lang="`)
+		writeXMLAttrValue(b, t.LangTag)
+		b.WriteByte('"')
+	case t.DatatypeIRI != XSDString:
+		b.WriteString(` rdf:datatype="`)
+		writeXMLAttrValue(b, t.DatatypeIRI)
+		b.WriteByte('"')
+	}
+	b.WriteByte('>')
+	writeXMLText(b, []byte(t.Object))
+	b.WriteString("</" + name + ">\n")
+}
+
+// SplitXMLName returns the longest suffix of IRI which is an XML name without
+// colon as local, with the remainder as namespace. Both must be non-empty.
+func splitXMLName(IRI string) (namespace, local string, ok bool) {
+	for i := range IRI {
+		if i != 0 && isNCName(IRI[i:]) {
+			return IRI[:i], IRI[i:], true
+		}
+	}
+	return "", "", false
+}
+
+// IsRDFXMLSyntaxTerm returns whether IRI is reserved by the RDF/XML grammar. Such
+// names are permitted as neither node element nor property element.
+func isRDFXMLSyntaxTerm(IRI string) bool {
+	switch IRI {
+	case rdfNS + "Description", rdfNS + "RDF", rdfNS + "ID",
+		rdfNS + "about", rdfNS + "bagID", rdfNS + "parseType",
+		rdfNS + "resource", rdfNS + "nodeID", rdfNS + "li",
+		rdfNS + "aboutEach", rdfNS + "aboutEachPrefix", rdfNS + "datatype":
+		return true
+	}
+	return false
+}
+
+// IsXMLContent returns whether s is well-formed XML content, with declarations
+// for all of the namespace prefixes in use. Such content reads back as is from
+// a property element with parse type "Literal".
+func isXMLContent(s string) bool {
+	d := xml.NewDecoder(strings.NewReader("<x>" + s + "</x>"))
+	var open []xml.Name     // elements, including the wrapper
+	var declared [][]string // namespace prefixes per element
+
+	isDeclared := func(prefix string) bool {
+		if prefix == "" || prefix == "xml" || prefix == "xmlns" {
+			return true
+		}
+		for _, prefixes := range declared {
+			if slices.Contains(prefixes, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for {
+		tok, err := d.RawToken()
+		if err != nil {
+			return false // includes EOF before the wrapper end
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			var prefixes []string
+			for _, a := range tok.Attr {
+				if a.Name.Space == "xmlns" {
+					prefixes = append(prefixes, a.Name.Local)
+				}
+			}
+			declared = append(declared, prefixes)
+			open = append(open, tok.Name)
+
+			if !isDeclared(tok.Name.Space) {
+				return false
+			}
+			for _, a := range tok.Attr {
+				if !isDeclared(a.Name.Space) {
+					return false
+				}
+			}
+		case xml.EndElement:
+			if len(open) == 0 || open[len(open)-1] != tok.Name {
+				return false
+			}
+			if len(open) == 1 {
+				// wrapper must end the input
+				_, err := d.RawToken()
+				return err == io.EOF
+			}
+			open = open[:len(open)-1]
+			declared = declared[:len(declared)-1]
+		case xml.CharData, xml.Comment:
+			break
+		default:
+			return false
+		}
+	}
+}
+
+// IsXMLChar returns whether c matches Char from the XML grammar.
+func isXMLChar(c rune) bool {
+	switch {
+	case c == '\t', c == '\n', c == '\r':
+		return true
+	case c < 0x20:
+		return false
+	case c <= 0xD7FF, c >= 0xE000 && c <= 0xFFFD, c >= 0x10000 && c <= 0x10FFFF:
+		return true
+	}
+	return false
+}
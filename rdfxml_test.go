@@ -0,0 +1,354 @@
+package tripn
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var rdfXMLTriples = []struct {
+	rdfXML  string
+	triples []Triple
+}{
+	// W3C RDF 1.1 XML Syntax, example 7
+	{`<?xml version="1.0" encoding="utf-8"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+         xmlns:dc="http://purl.org/dc/elements/1.1/"
+         xmlns:ex="http://example.org/stuff/1.0/">
+  <rdf:Description rdf:about="http://www.w3.org/TR/rdf-syntax-grammar"
+		   dc:title="RDF/XML Syntax Specification (Revised)">
+    <ex:editor>
+      <rdf:Description ex:fullName="Dave Beckett">
+	<ex:homePage rdf:resource="http://purl.org/net/dajobe/" />
+      </rdf:Description>
+    </ex:editor>
+  </rdf:Description>
+</rdf:RDF>`,
+		[]Triple{
+			{"http://www.w3.org/TR/rdf-syntax-grammar", "http://purl.org/dc/elements/1.1/title", "RDF/XML Syntax Specification (Revised)", XSDString, "", ""},
+			{"http://example.com/skolem-stub/anon#1", "http://example.org/stuff/1.0/fullName", "Dave Beckett", XSDString, "", ""},
+			{"http://example.com/skolem-stub/anon#1", "http://example.org/stuff/1.0/homePage", "http://purl.org/net/dajobe/", "", "", ""},
+			{"http://www.w3.org/TR/rdf-syntax-grammar", "http://example.org/stuff/1.0/editor", "http://example.com/skolem-stub/anon#1", "", "", ""},
+		},
+	},
+
+	// typed node as root, with xml:lang, xml:base, rdf:datatype and rdf:nodeID
+	{`<ex:Doc xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns:ex="http://example.org/"
+	xml:base="http://example.org/dir/page#frag" rdf:ID="d1" xml:lang="EN">
+  <ex:title>Hello</ex:title>
+  <ex:title xml:lang="">Plain</ex:title>
+  <ex:size rdf:datatype="http://www.w3.org/2001/XMLSchema#integer">42</ex:size>
+  <ex:next rdf:resource="other"/>
+  <ex:same rdf:nodeID="n1"/>
+  <ex:empty/>
+</ex:Doc>`,
+		[]Triple{
+			{"http://example.org/dir/page#d1", rdfType, "http://example.org/Doc", "", "", ""},
+			{"http://example.org/dir/page#d1", "http://example.org/title", "Hello", rdfLangString, "en", ""},
+			{"http://example.org/dir/page#d1", "http://example.org/title", "Plain", XSDString, "", ""},
+			{"http://example.org/dir/page#d1", "http://example.org/size", "42", XSDInteger, "", ""},
+			{"http://example.org/dir/page#d1", "http://example.org/next", "http://example.org/dir/other", "", "", ""},
+			{"http://example.org/dir/page#d1", "http://example.org/same", "http://example.com/skolem-stub/blank#n1", "", "", ""},
+			{"http://example.org/dir/page#d1", "http://example.org/empty", "", rdfLangString, "en", ""},
+		},
+	},
+
+	// parse types, rdf:li numbering and reification
+	{`<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns:ex="http://example.org/">
+  <rdf:Seq rdf:about="http://example.org/seq">
+    <rdf:li rdf:resource="http://example.org/a"/>
+    <rdf:li>b</rdf:li>
+  </rdf:Seq>
+  <rdf:Description rdf:about="http://example.org/s">
+    <ex:address rdf:parseType="Resource">
+      <ex:city>Amsterdam</ex:city>
+    </ex:address>
+    <ex:markup rdf:parseType="Literal"><b xmlns="http://www.w3.org/1999/xhtml">bold &amp; <ex:i a="&quot;">x</ex:i></b></ex:markup>
+    <ex:list rdf:parseType="Collection">
+      <rdf:Description rdf:about="http://example.org/one"/>
+      <rdf:Description rdf:about="http://example.org/two"/>
+    </ex:list>
+    <ex:none rdf:parseType="Collection"/>
+    <ex:said rdf:ID="r1">hi</ex:said>
+  </rdf:Description>
+</rdf:RDF>`,
+		[]Triple{
+			{"http://example.org/seq", rdfType, "http://www.w3.org/1999/02/22-rdf-syntax-ns#Seq", "", "", ""},
+			{"http://example.org/seq", "http://www.w3.org/1999/02/22-rdf-syntax-ns#_1", "http://example.org/a", "", "", ""},
+			{"http://example.org/seq", "http://www.w3.org/1999/02/22-rdf-syntax-ns#_2", "b", XSDString, "", ""},
+
+			{"http://example.com/skolem-stub/anon#1", "http://example.org/city", "Amsterdam", XSDString, "", ""},
+			{"http://example.org/s", "http://example.org/address", "http://example.com/skolem-stub/anon#1", "", "", ""},
+			{"http://example.org/s", "http://example.org/markup", `<b xmlns="http://www.w3.org/1999/xhtml">bold &amp; <ex:i xmlns:ex="http://example.org/" a="&quot;">x</ex:i></b>`, rdfXMLLiteral, "", ""},
+			{"http://example.com/skolem-stub/anon#2", rdfFirst, "http://example.org/one", "", "", ""},
+			{"http://example.com/skolem-stub/anon#2", rdfRest, "http://example.com/skolem-stub/anon#3", "", "", ""},
+			{"http://example.com/skolem-stub/anon#3", rdfFirst, "http://example.org/two", "", "", ""},
+			{"http://example.com/skolem-stub/anon#3", rdfRest, rdfNil, "", "", ""},
+			{"http://example.org/s", "http://example.org/list", "http://example.com/skolem-stub/anon#2", "", "", ""},
+			{"http://example.org/s", "http://example.org/none", rdfNil, "", "", ""},
+			{"http://example.org/s", "http://example.org/said", "hi", XSDString, "", ""},
+			{"http://example.org/base#r1", rdfType, rdfStatement, "", "", ""},
+			{"http://example.org/base#r1", rdfSubject, "http://example.org/s", "", "", ""},
+			{"http://example.org/base#r1", rdfPredicate, "http://example.org/said", "", "", ""},
+			{"http://example.org/base#r1", rdfObject, "hi", XSDString, "", ""},
+		},
+	},
+}
+
+func TestRDFXMLReader(t *testing.T) {
+	base, err := url.Parse("http://example.org/base")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range rdfXMLTriples {
+		r := RDFXMLReader{
+			R:          strings.NewReader(test.rdfXML),
+			BaseIRI:    base,
+			skolemizer: skolemStub,
+		}
+
+		got := []Triple{}
+		var err error
+		for err == nil {
+			got, err = r.ReadAppend(got)
+		}
+		if err != io.EOF {
+			t.Errorf("got error %v, for RDF/XML:\n%s", err, test.rdfXML)
+			continue
+		}
+		if !slices.Equal(got, test.triples) {
+			msg := "got triples:"
+			for _, t := range got {
+				msg += "\n\t" + t.String()
+			}
+			msg += "\nwant triples:"
+			for _, t := range test.triples {
+				msg += "\n\t" + t.String()
+			}
+			t.Error(msg, "\nfor RDF/XML:\n", test.rdfXML)
+		}
+	}
+}
+
+var rdfXMLSyntaxErrors = []struct {
+	rdfXML string
+	reason string
+	kind   error
+}{
+	{`<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"><rdf:li/></rdf:RDF>`,
+		"illegal node element <rdf:li>", ErrUnexpectedToken},
+	{`<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"><ex:Thing/></rdf:RDF>`,
+		`undefined namespace prefix "ex"`, ErrUndefinedPrefix},
+	{`<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"><rdf:Description rdf:about="x"/></rdf:RDF>`,
+		`relative reference "x" without base IRI`, ErrNoBaseIRI},
+	{`<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"><rdf:Description rdf:about="http://example.org/a" rdf:nodeID="b"/></rdf:RDF>`,
+		"node element with more than one of rdf:about, rdf:ID and rdf:nodeID", ErrUnexpectedToken},
+	{`<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns:ex="http://example.org/"><rdf:Description><ex:p rdf:resource="http://example.org/o">text</ex:p></rdf:Description></rdf:RDF>`,
+		"text in property element with rdf:resource, rdf:nodeID or property attributes", ErrUnexpectedToken},
+	{`<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns:ex="http://example.org/"><rdf:Description><ex:p>a<rdf:Description/></ex:p></rdf:Description></rdf:RDF>`,
+		"both text and node element in property element", ErrUnexpectedToken},
+	{`<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"><rdf:Description rdf:nodeID="1x"/></rdf:RDF>`,
+		`illegal rdf:nodeID "1x"`, ErrIllegalChar},
+	{`<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"><rdf:Description></rdf:RDF>`,
+		"element <rdf:Description> closed by </rdf:RDF>", ErrMalformedXML},
+	{`<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"><rdf:Description a="b"/></rdf:RDF>`,
+		`attribute "a" without namespace`, ErrUnexpectedToken},
+}
+
+func TestRDFXMLReaderSyntaxErrors(t *testing.T) {
+	for _, test := range rdfXMLSyntaxErrors {
+		r := RDFXMLReader{R: strings.NewReader(test.rdfXML)}
+
+		var err error
+		for err == nil {
+			_, err = r.ReadAppend(nil)
+		}
+		var e *SyntaxError
+		if !errors.As(err, &e) {
+			t.Errorf("got error %v, want a *SyntaxError, for RDF/XML:\n%s", err, test.rdfXML)
+			continue
+		}
+		if e.Reason != test.reason {
+			t.Errorf("got reason %q, want %q, for RDF/XML:\n%s", e.Reason, test.reason, test.rdfXML)
+		}
+		if !errors.Is(err, test.kind) {
+			t.Errorf("got error %v, want kind %q, for RDF/XML:\n%s", err, test.kind, test.rdfXML)
+		}
+		if !strings.HasPrefix(e.Error(), "RDF/XML syntax violation on line № 1") {
+			t.Errorf("got error message %q, want RDF/XML on line 1", e.Error())
+		}
+	}
+}
+
+func TestRDFXMLReaderUnexpectedEOF(t *testing.T) {
+	r := RDFXMLReader{R: strings.NewReader(`<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+<rdf:Description rdf:about="http://example.org/a"/>`)}
+
+	got, err := r.ReadAppend(nil)
+	if err != nil || len(got) != 0 {
+		t.Fatalf("got %d triples and error %v, want none", len(got), err)
+	}
+	_, err = r.ReadAppend(nil)
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("got error %v, want io.ErrUnexpectedEOF", err)
+	}
+}
+
+func TestRDFXMLWriter(t *testing.T) {
+	triples := []Triple{
+		{"http://example.org/doc", rdfType, "http://example.org/ns#Document", "", "", ""},
+		{"http://example.org/doc", "http://purl.org/dc/terms/title", "RDF & XML", XSDString, "", ""},
+		{"http://example.org/doc", "http://purl.org/dc/terms/title", "RDF en XML", rdfLangString, "nl", ""},
+		{"http://example.org/doc", "http://example.org/ns#pages", "12", XSDInteger, "", ""},
+		{"http://example.org/doc", "http://example.org/ns#see", "http://example.org/other?a=1&b=2", "", "", ""},
+		{"http://example.org/doc", rdfType, "urn:isbn:0-00-000000-0", "", "", ""},
+		{"http://example.org/other?a=1&b=2", "http://example.org/ns#note", "line\r\nfeed", XSDString, "", ""},
+		{"http://example.org/other?a=1&b=2", "http://example.org/ns#markup", `<b xmlns="http://www.w3.org/1999/xhtml">bold &amp; <ex:i xmlns:ex="http://example.org/" a="&quot;">x</ex:i></b>`, rdfXMLLiteral, "", ""},
+		{"http://example.org/other?a=1&b=2", "http://example.org/ns#markup", "a < b", rdfXMLLiteral, "", ""},
+		{"http://example.org/other?a=1&b=2", "http://example.org/ns#markup", "</x><x>", rdfXMLLiteral, "", ""},
+	}
+
+	var b strings.Builder
+	w := RDFXMLWriter{W: &b, Prefixes: map[string]string{"dc": "http://purl.org/dc/terms/"}}
+	for _, triple := range triples {
+		if err := w.WriteTriple(triple); err != nil {
+			t.Fatal("write error:", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal("close error:", err)
+	}
+
+	const want = `<?xml version="1.0" encoding="UTF-8"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	xmlns:dc="http://purl.org/dc/terms/"
+	xmlns:ns1="http://example.org/ns#">
+	<ns1:Document rdf:about="http://example.org/doc">
+		<rdf:type rdf:resource="urn:isbn:0-00-000000-0"/>
+		<dc:title>RDF &amp; XML</dc:title>
+		<dc:title xml:lang="nl">RDF en XML</dc:title>
+		<ns1:pages rdf:datatype="http://www.w3.org/2001/XMLSchema#integer">12</ns1:pages>
+		<ns1:see rdf:resource="http://example.org/other?a=1&amp;b=2"/>
+	</ns1:Document>
+	<rdf:Description rdf:about="http://example.org/other?a=1&amp;b=2">
+		<ns1:note>line&#xD;
+feed</ns1:note>
+		<ns1:markup rdf:parseType="Literal"><b xmlns="http://www.w3.org/1999/xhtml">bold &amp; <ex:i xmlns:ex="http://example.org/" a="&quot;">x</ex:i></b></ns1:markup>
+		<ns1:markup rdf:datatype="http://www.w3.org/1999/02/22-rdf-syntax-ns#XMLLiteral">a &lt; b</ns1:markup>
+		<ns1:markup rdf:datatype="http://www.w3.org/1999/02/22-rdf-syntax-ns#XMLLiteral">&lt;/x&gt;&lt;x&gt;</ns1:markup>
+	</rdf:Description>
+</rdf:RDF>
+`
+	if got := b.String(); got != want {
+		t.Errorf("got RDF/XML:\n%s\nwant:\n%s", got, want)
+	}
+
+	// round trip
+	r := RDFXMLReader{R: strings.NewReader(b.String())}
+	got := []Triple{}
+	var err error
+	for err == nil {
+		got, err = r.ReadAppend(got)
+	}
+	if err != io.EOF {
+		t.Fatal("read error:", err)
+	}
+	slices.SortFunc(got, compareTriples)
+	slices.SortFunc(triples, compareTriples)
+	if !slices.Equal(got, triples) {
+		t.Errorf("got triples %q, want %q", got, triples)
+	}
+}
+
+func TestRDFXMLWriterBlankNodes(t *testing.T) {
+	const turtle = `@prefix : <http://example.org/ns#> .
+:s :p [ a :Thing ; :q [] ] ; :r _:x .
+_:x :q _:x .
+[ :p :o ] .
+`
+	r := Reader{R: bufio.NewReader(strings.NewReader(turtle))}
+	var b strings.Builder
+	w := RDFXMLWriter{W: &b, Prefixes: map[string]string{"": "http://example.org/ns#"}}
+	for triple, err := range r.All() {
+		if err == nil {
+			err = w.WriteTriple(triple)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err == nil {
+		t.Error("got no error for empty prefix label")
+	}
+
+	b.Reset()
+	w = RDFXMLWriter{W: &b, Prefixes: map[string]string{"ex": "http://example.org/ns#"}}
+	for triple, err := range (&Reader{R: bufio.NewReader(strings.NewReader(turtle))}).All() {
+		if err == nil {
+			err = w.WriteTriple(triple)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal("close error:", err)
+	}
+
+	const want = `<?xml version="1.0" encoding="UTF-8"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	xmlns:ex="http://example.org/ns#">
+	<rdf:Description rdf:about="http://example.org/ns#s">
+		<ex:p>
+			<ex:Thing>
+				<ex:q rdf:parseType="Resource"/>
+			</ex:Thing>
+		</ex:p>
+		<ex:r rdf:nodeID="b1"/>
+	</rdf:Description>
+	<rdf:Description rdf:nodeID="b1">
+		<ex:q rdf:nodeID="b1"/>
+	</rdf:Description>
+	<rdf:Description>
+		<ex:p rdf:resource="http://example.org/ns#o"/>
+	</rdf:Description>
+</rdf:RDF>
+`
+	if got := b.String(); got != want {
+		t.Errorf("got RDF/XML:\n%s\nwant:\n%s", got, want)
+	}
+
+	// round trip
+	rr := RDFXMLReader{R: strings.NewReader(b.String())}
+	var got []Triple
+	var err error
+	for err == nil {
+		got, err = rr.ReadAppend(got)
+	}
+	if err != io.EOF {
+		t.Fatal("read error:", err)
+	}
+	if len(got) != 6 {
+		t.Errorf("got %d triples, want 6", len(got))
+	}
+}
+
+func TestRDFXMLWriterUnsupported(t *testing.T) {
+	for _, triple := range []Triple{
+		{"http://example.org/s", "urn:isbn:0-00-000000-0", "http://example.org/o", "", "", ""},
+		{"http://example.org/s", rdfNS + "li", "http://example.org/o", "", "", ""},
+		{"http://example.org/s", "http://example.org/p", "hello", rdfDirLangString, "en", "ltr"},
+		{"<< <http://example.org/a> <http://example.org/b> <http://example.org/c> >>", "http://example.org/p", "http://example.org/o", "", "", ""},
+		{"http://example.org/s", "http://example.org/p", "bell\b", XSDString, "", ""},
+	} {
+		w := RDFXMLWriter{W: io.Discard}
+		if err := w.WriteTriple(triple); err == nil {
+			t.Errorf("got no error for %s", triple)
+		}
+	}
+}
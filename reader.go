@@ -2,6 +2,7 @@ package tripn
 
 import (
 	"bufio"
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
@@ -14,34 +15,216 @@ import (
 
 // SyntaxError signals malformed input.
 type SyntaxError struct {
-	LineNo int    // text position
+	LineNo int    // text position, zero for unknown
+	Column int    // rune position in line, zero for unknown
+	Offset int64  // byte position in input, -1 for unknown
 	Reason string // English message
+
+	// The line of input with a caret marker under the position,
+	// on a second line. Zero for unknown.
+	Excerpt string
+
+	// Err is one of the Err* kinds, for use with errors.Is.
+	Err error
+
+	grammar grammar // input format
+}
+
+// Grammar identifies the syntax of input.
+type grammar uint8
+
+// Grammar options are all derived from Turtle.
+const (
+	turtleGrammar grammar = iota
+	nTriplesGrammar
+	nQuadsGrammar
+	trigGrammar
+	rdfXMLGrammar
+	jsonLDGrammar
+	n3Grammar
+	trixGrammar
+	htmlGrammar
+)
+
+// String returns the name of the syntax.
+func (g grammar) String() string {
+	switch g {
+	case nTriplesGrammar:
+		return "N-Triples"
+	case nQuadsGrammar:
+		return "N-Quads"
+	case trigGrammar:
+		return "TriG"
+	case rdfXMLGrammar:
+		return "RDF/XML"
+	case jsonLDGrammar:
+		return "JSON-LD"
+	case n3Grammar:
+		return "N3"
+	case trixGrammar:
+		return "TriX"
+	case htmlGrammar:
+		return "HTML"
+	default:
+		return "Turtle"
+	}
 }
 
-// SyntaxErr is a convenience constructor.
-func (r *Reader) syntaxErr(reason string) error {
-	return &SyntaxError{
-		LineNo: r.lineNo,
-		Reason: reason,
+// Kinds of SyntaxError.
+var (
+	ErrInvalidUTF8         = errors.New("invalid UTF-8")
+	ErrTokenSize           = errors.New("token exceeds maximum size")
+	ErrIllegalChar         = errors.New("illegal character")
+	ErrIllegalEscape       = errors.New("illegal escape")
+	ErrUnterminatedLiteral = errors.New("unterminated literal")
+	ErrUndefinedPrefix     = errors.New("undefined prefix")
+	ErrIllegalIRI          = errors.New("malformed IRI")
+	ErrNoBaseIRI           = errors.New("relative IRI without base")
+	ErrUnexpectedToken     = errors.New("unexpected token")
+	ErrMalformedXML        = errors.New("malformed XML")
+	ErrMalformedJSON       = errors.New("malformed JSON")
+)
+
+// SyntaxErr is a convenience constructor. The kind goes into Err. Input
+// position at must be a slice of the current chunk, or nil for unknown.
+func (r *Reader) syntaxErr(kind error, at []byte, reason string) error {
+	e := &SyntaxError{
+		LineNo:  r.lineNo,
+		Offset:  -1,
+		Reason:  reason,
+		Err:     kind,
+		grammar: r.grammar,
+	}
+
+	// locate at in chunk
+	if i := cap(r.chunk) - cap(at); len(at) != 0 && i >= 0 && i < len(r.chunk) && &r.chunk[i] == &at[0] {
+		e.Column = r.chunkColumn + utf8.RuneCount(r.chunk[:i]) + 1
+		e.Offset = r.chunkOffset + int64(i)
+		e.Excerpt = excerpt(r.chunk, i)
 	}
+	return e
 }
 
 // Error implements the standard error interface.
 func (e *SyntaxError) Error() string {
-	return fmt.Sprintf("Turtle syntax violation on line № %d: %s", e.LineNo, e.Reason)
+	if e.LineNo == 0 {
+		return fmt.Sprintf("%s syntax violation: %s", e.grammar, e.Reason)
+	}
+	if e.Column == 0 {
+		return fmt.Sprintf("%s syntax violation on line № %d: %s", e.grammar, e.LineNo, e.Reason)
+	}
+	return fmt.Sprintf("%s syntax violation on line № %d, column %d: %s", e.grammar, e.LineNo, e.Column, e.Reason)
+}
+
+// Unwrap returns the kind of error.
+func (e *SyntaxError) Unwrap() error { return e.Err }
+
+// Excerpt returns line with a caret marker under index i. Long lines are cut
+// in a window around the position.
+func excerpt(line []byte, i int) string {
+	line = bytes.TrimRight(line, "\r\n")
+	if i > len(line) {
+		i = len(line)
+	}
+
+	const window = 60 // maximum number of bytes on each side
+	start, end := 0, len(line)
+	var prefix, suffix string
+	if i > window {
+		start = i - window
+		for start < i && !utf8.RuneStart(line[start]) {
+			start++
+		}
+		prefix = "…"
+	}
+	if end-i > window {
+		end = i + window
+		for end > i && !utf8.RuneStart(line[end]) {
+			end--
+		}
+		suffix = "…"
+	}
+
+	var b strings.Builder
+	b.WriteString(prefix)
+	b.Write(line[start:end])
+	b.WriteString(suffix)
+	b.WriteByte('\n')
+	// align with tabs as is
+	for _, c := range prefix + string(line[start:i]) {
+		if c == '\t' {
+			b.WriteByte('\t')
+		} else {
+			b.WriteByte(' ')
+		}
+	}
+	b.WriteByte('^')
+	return b.String()
+}
+
+// InvalidUTF8Index returns the position of the first invalid UTF-8 encoding in
+// s, or len(s) for none.
+func invalidUTF8Index(s []byte) int {
+	for i := 0; i < len(s); {
+		c, size := utf8.DecodeRune(s[i:])
+		if c == utf8.RuneError && size == 1 {
+			return i
+		}
+		i += size
+	}
+	return len(s)
 }
 
 // Reader parses Turtle in a strict manner. The input is standard compliant when
-// read completes without error and vise versa.
+// read completes without error and vise versa. Version selects the revision of
+// the grammar.
 //
 // Reader mints new, globally unique IRIs for blank nodes, a.k.a. Skolemization.
 // Any of such get true from IsSkolemIRI.
 type Reader struct {
-	// Any lines longer than the buffer size cause a *SyntaxError.
-	// The default size of 4 KiB could be too low in some cases.
+	// Lines longer than the buffer size are read in multiple chunks.
+	// The buffer size has no effect on the maximum token size.
 	R *bufio.Reader
 
-	pending []byte // ReadSlice remainder
+	// Tokens larger than MaxTokenSize in bytes cause a *SyntaxError. The
+	// limit protects against unbounded memory use on malformed input, as
+	// tokens may continue over any number of buffer reads. Zero defaults
+	// to DefaultMaxTokenSize.
+	MaxTokenSize int
+
+	// Recover from syntax errors, when set, by skipping the malformed
+	// statement, i.e., up to the next "." outside of any quoted literal.
+	// Reads continue as normal with the statement that follows.
+	Recover bool
+
+	// SyntaxErrs has any errors skipped in Recover mode, in order of
+	// appearance.
+	SyntaxErrs []*SyntaxError
+
+	// Version selects the revision of the grammar. The zero value accepts
+	// RDF-star on top of RDF 1.1.
+	Version RDFVersion
+
+	pending []byte // read remainder
+	buf     []byte // chunk assembly
+	carry   []byte // read ahead for the next chunk
+
+	midLine   bool // last chunk read did not end the line
+	inComment bool // comment continues in the next chunk
+
+	literalQuote byte // open delimiter, if any
+	literalLong  bool // open delimiter is triple
+	literalTyped bool // datatype or language tag after last delimiter
+
+	emit           func(Triple) error // optional triple receiver
+	tripleCount    int                // triples produced
+	handler        Handler            // optional event receiver
+	commentHandler CommentHandler     // optional event receiver
+	commentBuf     []byte             // text assembly
+
+	chunk       []byte // last read
+	chunkOffset int64  // byte position of chunk in input
+	chunkColumn int    // rune position of chunk in line
 
 	// Relative IRI encounters get resolved against this root. Any "@base"
 	// and "BASE" directives read update the value accordingly. Users may
@@ -55,86 +238,222 @@ type Reader struct {
 	prefixPerLabel map[string]string
 
 	lineNo          int // input position
-	anonNodeNo      int // anonymous nodes seen
 	collectionLevel int // nest count
 	propListLevel   int // nest count
+	annotationLevel int // nest count
 
-	skolemIRICache string // lazy initiation
+	skolemizer              // blank node IRIs
+	variableIRICache string // lazy initiation
+
+	grammar grammar // input format
+
+	graphIRI string // current graph, zero for default
+	inGraph  bool   // within curly brackets
+
+	formulaLevel     int                 // nest count
+	formulaQuads     []Quad              // statements of formulae read
+	quantifiedScopes []map[string]string // replacements per formulaLevel
+	formulaScopes    []int               // scope number per formulaLevel
+	formulaCount     int                 // formulae seen
 }
 
 // SkolemIRIRoot is the reserved namespace path.
 const skolemIRIRoot = "web+skolem://quies.net/"
 
-// SkolemIRIRoot identifies the Reader session lazily.
-func (r *Reader) skolemIRIRoot() string {
-	if r.skolemIRICache == "" {
-		r.skolemIRICache = fmt.Sprintf(skolemIRIRoot+"%x%x/",
-			time.Now().UnixNano(), rand.Uint32())
+// Skolemizer mints the Skolem IRIs of a read session. Readers embed it.
+type skolemizer struct {
+	anonNodeNo     int    // anonymous nodes seen
+	skolemIRICache string // lazy initiation
+}
+
+// SkolemIRIRoot identifies the session lazily.
+func (s *skolemizer) skolemIRIRoot() string {
+	if s.skolemIRICache == "" {
+		s.skolemIRICache = newSkolemIRIRoot()
 	}
-	return r.skolemIRICache
+	return s.skolemIRICache
+}
+
+// NewSkolemIRIRoot returns a namespace path which is unique to the session.
+func newSkolemIRIRoot() string {
+	return fmt.Sprintf(skolemIRIRoot+"%x%x/", time.Now().UnixNano(), rand.Uint32())
+}
+
+// NewAnonIRI mints a Skolem IRI for an anonymous node.
+func (s *skolemizer) newAnonIRI() string {
+	s.anonNodeNo++
+	return fmt.Sprintf("%sanon#%d", s.skolemIRIRoot(), s.anonNodeNo)
+}
+
+// BlankIRI returns the Skolem IRI of a blank node label. Labels are scoped to
+// the session.
+func (s *skolemizer) blankIRI(label string) string {
+	return s.skolemIRIRoot() + "blank#" + label
 }
 
 // IsSkolemIRI returns whether s is a IRI minted by a Reader (for anonymous
 // nodes).
 func IsSkolemIRI(s string) bool {
-	return strings.HasPrefix(skolemIRIRoot, s)
+	return strings.HasPrefix(s, skolemIRIRoot)
+}
+
+// DefaultMaxTokenSize is the MaxTokenSize of Reader when zero.
+const DefaultMaxTokenSize = 16 << 20
+
+func (r *Reader) maxTokenSize() int {
+	if r.MaxTokenSize > 0 {
+		return r.MaxTokenSize
+	}
+	return DefaultMaxTokenSize
 }
 
 // Lead skips whitespace and comments in a line.
-func lead(line []byte) []byte {
+func (r *Reader) lead(line []byte) ([]byte, error) {
 	for i, c := range line {
 		switch c {
 		case ' ', '\t', '\r':
 			continue
-		case '#', '\n':
-			return nil
+		case '#':
+			r.inComment = r.midLine
+			if r.commentHandler != nil {
+				return nil, r.comment(line[i+1:])
+			}
+			return nil, nil
+		case '\n':
+			return nil, nil
 		default:
-			return line[i:]
+			return line[i:], nil
 		}
 	}
-	return nil
+	return nil, nil
 }
 
 // Line returns a buffer that starts with a non-whitespace character. Comment
 // lines are omitted, yet the returned may include a comment trailer later on.
-// Lines without a trailing new-line character imply EOF.
+// Lines without a trailing new-line character imply EOF, or a chunk split.
 //
 // The caller MUST park the remainder of the line after parsing in .pending.
 func (r *Reader) line() ([]byte, error) {
 	line := r.pending
+	r.pending = nil
 	for {
-		line = lead(line)
+		var err error
+		line, err = r.lead(line)
+		if err != nil {
+			return nil, err
+		}
 		if len(line) != 0 {
 			return line, nil
 		}
 
-		var err error
-		line, err = r.R.ReadSlice('\n')
-		switch {
-		case err == nil, errors.Is(err, io.EOF) && len(line) != 0:
-			r.lineNo++
+		line, err = r.read()
+		if err != nil {
+			return nil, err
+		}
+		if r.inComment {
+			// skip comment continuation
+			r.inComment = r.midLine
+			if r.commentHandler != nil {
+				err = r.comment(line)
+				if err != nil {
+					return nil, err
+				}
+			}
+			line = nil
+		}
+	}
+}
 
-			if !utf8.Valid(line) {
-				r.pending = line
-				return nil, r.syntaxErr("invalid UTF-8")
+// Read returns the next chunk of input. Chunks end with a new-line character,
+// with the exception of the last line, and with the exception of lines which
+// exceed the buffer size of R. Such long lines are split after whitespace, such
+// that only quoted literals and comments can continue in the following chunk.
+func (r *Reader) read() ([]byte, error) {
+	var buf []byte // assembly in use
+	if len(r.carry) != 0 {
+		buf = append(r.buf[:0], r.carry...)
+		r.carry = nil
+	}
+
+	for {
+		chunk, err := r.R.ReadSlice('\n')
+		switch {
+		case err == nil, errors.Is(err, io.EOF):
+			if buf != nil {
+				buf = append(buf, chunk...)
+				r.buf = buf
+				chunk = buf
+			}
+			if len(chunk) == 0 {
+				return nil, err
 			}
+			return chunk, r.chunkRead(chunk, false)
 
 		case errors.Is(err, bufio.ErrBufferFull):
-			r.pending = line
-			return nil, r.syntaxErr("line too long")
+			if buf == nil {
+				buf = r.buf[:0]
+				if i := lastSpace(chunk); i >= 0 {
+					// carry needs copy before next read
+					r.carry = append(buf, chunk[i+1:]...)
+					r.buf = r.carry
+					return chunk[:i+1], r.chunkRead(chunk[:i+1], true)
+				}
+			}
+
+			// assembly has no whitespace yet
+			offset := len(buf)
+			buf = append(buf, chunk...)
+			r.buf = buf
+			if i := lastSpace(chunk); i >= 0 {
+				i += offset
+				r.carry = buf[i+1:]
+				return buf[:i+1], r.chunkRead(buf[:i+1], true)
+			}
+			if len(buf) > r.maxTokenSize() {
+				r.chunkRead(buf, true)
+				return nil, r.syntaxErr(ErrTokenSize, buf, "token exceeds maximum size")
+			}
+
 		default:
-			r.pending = line
 			return nil, err
 		}
 	}
 }
 
+// ChunkRead registers a read, with midLine for chunks that do not end a line.
+func (r *Reader) chunkRead(chunk []byte, midLine bool) error {
+	r.chunkOffset += int64(len(r.chunk))
+	if r.midLine {
+		r.chunkColumn += utf8.RuneCount(r.chunk)
+	} else {
+		r.lineNo++
+		r.chunkColumn = 0
+	}
+	r.midLine = midLine
+	r.chunk = chunk
+
+	if !utf8.Valid(chunk) {
+		return r.syntaxErr(ErrInvalidUTF8, chunk[invalidUTF8Index(chunk):], "invalid UTF-8")
+	}
+	return nil
+}
+
+// LastSpace returns the index of the last space or tab in line, or -1 for none.
+func lastSpace(line []byte) int {
+	for i := len(line) - 1; i >= 0; i-- {
+		if line[i] == ' ' || line[i] == '\t' {
+			return i
+		}
+	}
+	return -1
+}
+
 // LineContinue is like line, yet it accepts the pending read and it expects
 // more to follow.
 func (r *Reader) lineContinue(remainder []byte) (line []byte, err error) {
-	line = lead(remainder)
-	if len(line) != 0 {
-		return line, nil
+	line, err = r.lead(remainder)
+	if err != nil || len(line) != 0 {
+		return line, err
 	}
 	line, err = r.line()
 	if err != nil && errors.Is(err, io.EOF) {
@@ -150,18 +469,169 @@ func (r *Reader) lineContinue(remainder []byte) (line []byte, err error) {
 // SyntaxError is used for malformed Turtle exclusively. Stream errors pass as
 // is, with the exception of io.EOF. Incomplete records at the end of stream
 // are addressed with io.ErrUnexpectedEOF instead.
+//
+// In Recover mode, malformed statements are skipped with their SyntaxError
+// added to SyntaxErrs. Any triples of such statement are discarded from dst.
 func (r *Reader) ReadAppend(dst []Triple) ([]Triple, error) {
+	for {
+		offset := len(dst)
+		var err error
+		dst, err = r.readStatement(dst)
+		if err == nil || !r.Recover {
+			return dst, err
+		}
+		var e *SyntaxError
+		if !errors.As(err, &e) {
+			return dst, err
+		}
+		dst = dst[:offset]
+		r.SyntaxErrs = append(r.SyntaxErrs, e)
+
+		err = r.resync(e)
+		if err != nil {
+			return dst, err
+		}
+	}
+}
+
+// ReadStatement adds the triples of the next statement to dst.
+func (r *Reader) readStatement(dst []Triple) ([]Triple, error) {
 	subject, line, err := r.readSubject(&dst)
 	if err != nil {
 		return dst, err
 	}
+	if subject != "" {
+		if r.grammar == n3Grammar {
+			subject = r.quantifiedTerm(subject)
+		}
+		line, err = r.readPredicateObjectList(subject, line, &dst)
+		if err != nil {
+			return dst, err
+		}
+	}
+	r.pending = line
+	return dst, nil // ✅
+}
+
+// AppendTriple adds t to dstp, or it passes t to the emit function when set.
+func (r *Reader) appendTriple(dstp *[]Triple, t Triple) error {
+	r.tripleCount++
+	if r.emit != nil {
+		return r.emit(t)
+	}
+	*dstp = append(*dstp, t)
+	return nil
+}
+
+// Resync skips input up to the next statement terminator (".") after syntax
+// error e. The search starts at the position of e, and it passes any quoted
+// literals, IRI references and comments as a whole.
+func (r *Reader) resync(e *SyntaxError) error {
+	r.collectionLevel = 0
+	r.propListLevel = 0
+	r.annotationLevel = 0
+	r.pending = nil
+	r.inComment = false
+
+	var line []byte
+	if i := e.Offset - r.chunkOffset; e.Offset >= 0 && i >= 0 && i < int64(len(r.chunk)) {
+		line = r.chunk[i:]
+	}
+
+	// syntax error may be inside a quoted literal
+	quote, longQuote := r.literalQuote, r.literalLong
+	r.literalQuote = 0
+
+	var inIRI, inComment bool
+	var prevChunkEnd byte // last character of the previous chunk, if any
+	for {
+		for i := 0; i < len(line); i++ {
+			c := line[i]
+			switch {
+			case inComment:
+				inComment = c != '\n'
+
+			case inIRI:
+				switch c {
+				case '>', ' ', '\t', '\r', '\n':
+					inIRI = false
+				}
+
+			case quote != 0:
+				switch {
+				case c == '\\':
+					i++ // pass escaped
+				case c == quote && !longQuote:
+					quote = 0
+				case (c == '\r' || c == '\n') && !longQuote:
+					quote = 0
+				case c == quote && i+2 < len(line) && line[i+1] == c && line[i+2] == c:
+					i += 2
+					quote = 0
+				}
+
+			default:
+				switch c {
+				case '#':
+					inComment = true
+				case '<':
+					inIRI = true
+				case '"', '\'':
+					quote = c
+					longQuote = i+2 < len(line) && line[i+1] == c && line[i+2] == c
+					if longQuote {
+						i += 2
+					}
+				case '.':
+					// dots in names or numbers don't terminate
+					next := line[i+1:]
+					if len(next) != 0 && next[0] >= '0' && next[0] <= '9' {
+						continue
+					}
+					prev := prevChunkEnd
+					if i != 0 {
+						prev = line[i-1]
+					}
+					if isNameByte(prev) && len(next) != 0 && (next[0] == '.' || next[0] == ':' || pnCharsLen(next) != 0) {
+						continue
+					}
+					r.pending = next
+					return nil
+				}
+			}
+		}
+
+		if len(line) != 0 {
+			prevChunkEnd = line[len(line)-1]
+		}
+		var err error
+		line, err = r.read()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// IsNameByte returns whether c may occur in a prefixed name, with any byte of
+// a multi-byte UTF-8 encoding included.
+func isNameByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '_' || c == '-' || c == ':' || c == '.' || c >= 0x80
+}
 
+// ReadPredicateObjectList reads the predicates with their objects of subject.
+// The list ends with a "." at the statement level, it ends with a "]" in blank
+// nodes (when propListLevel is not zero), and it ends with a "|}" in
+// annotations (when annotationLevel is not zero). The remainder starts after
+// the terminator.
+func (r *Reader) readPredicateObjectList(subject string, line []byte, dstp *[]Triple) (remainder []byte, err error) {
 ReadPredicate:
 	for {
 		var predicate string
-		predicate, line, err = r.readPredicate(line)
+		var inverse bool
+		predicate, inverse, line, err = r.readVerb(line)
 		if err != nil {
-			return dst, err
+			return nil, err
 		}
 
 	ReadObject:
@@ -170,36 +640,91 @@ ReadPredicate:
 				SubjectIRI:   subject,
 				PredicateIRI: predicate,
 			}
-			line, err = r.readObject(line, &t, &dst)
+			if inverse {
+				line, err = r.lineContinue(line)
+				if err != nil {
+					return nil, err
+				}
+			}
+			at := line
+			line, err = r.readObject(line, &t, dstp)
+			if err != nil {
+				return nil, err
+			}
+			if inverse {
+				if t.DatatypeIRI != "" {
+					return nil, r.syntaxErr(ErrUnexpectedToken, at, `literal as subject of "<="`)
+				}
+				t.SubjectIRI, t.Object = t.Object, t.SubjectIRI
+			}
+			err = r.appendTriple(dstp, t)
 			if err != nil {
-				return dst, err
+				return nil, err
 			}
-			dst = append(dst, t)
 
 			// read terminator or followup
 			line, err = r.lineContinue(line)
 			if err != nil {
-				return dst, err
+				return nil, err
+			}
+			if r.isAnnotationStart(line) {
+				line, err = r.readAnnotation(t, line, dstp)
+				if err != nil {
+					return nil, err
+				}
 			}
 			switch line[0] {
-			case '.':
-				r.pending = line[1:]
-				return dst, nil // ✅
 			case ',':
 				line = line[1:]
 				continue ReadObject
 			case ';':
-				line = line[1:]
-				continue ReadPredicate
-			default:
-				return dst, r.syntaxErr("illegal triple continuation")
+				// predicate may be omitted (repeatedly)
+				for {
+					line, err = r.lineContinue(line[1:])
+					if err != nil {
+						return nil, err
+					}
+					if line[0] != ';' {
+						break
+					}
+				}
+				if !r.isPredicateObjectListEnd(line[0]) {
+					continue ReadPredicate
+				}
+			}
+
+			if r.isPredicateObjectListEnd(line[0]) {
+				switch line[0] {
+				case '}':
+					return line, nil // graph or formula closes on next read
+				case '|':
+					if len(line) < 2 || line[1] != '}' {
+						return nil, r.syntaxErr(ErrUnexpectedToken, line, `annotation not closed with "|}"`)
+					}
+					return line[2:], nil
+				}
+				return line[1:], nil
 			}
+			return nil, r.syntaxErr(ErrUnexpectedToken, line, "illegal triple continuation")
 		}
 	}
 }
 
+// IsPredicateObjectListEnd returns whether c terminates the current level. The
+// dot is optional for the last statement in a graph or in a formula.
+func (r *Reader) isPredicateObjectListEnd(c byte) bool {
+	if r.propListLevel != 0 {
+		return c == ']'
+	}
+	if r.annotationLevel != 0 {
+		return c == '|'
+	}
+	return c == '.' || c == '}' && (r.inGraph || r.formulaLevel != 0)
+}
+
 // ReadSubject reads the next node from the input stream. It may append to dstp
-// on encounters with collections and/or blank nodes with a property list.
+// on encounters with collections and/or blank nodes with a property list. The
+// IRI is zero when a blank node property list completed the statement.
 func (r *Reader) readSubject(dstp *[]Triple) (IRI string, lineRemainder []byte, _ error) {
 	line, err := r.line()
 	if err != nil {
@@ -207,6 +732,7 @@ func (r *Reader) readSubject(dstp *[]Triple) (IRI string, lineRemainder []byte,
 	}
 
 	for {
+		var isLabel bool // graph encounter
 		switch line[0] {
 		case '@':
 			line, err = r.inDirective(line)
@@ -214,22 +740,91 @@ func (r *Reader) readSubject(dstp *[]Triple) (IRI string, lineRemainder []byte,
 				return "", nil, err
 			}
 		case '<':
-			return r.inIRI(line)
+			if len(line) > 1 && line[1] == '<' {
+				return r.inSubjectTriple(line, dstp)
+			}
+			IRI, line, err = r.inIRI(line)
+			if err == nil {
+				line, isLabel, err = r.afterLabelOrSubject(IRI, line)
+			}
+			if err != nil || !isLabel {
+				return IRI, line, err
+			}
 		case '[':
-			return r.inAnonymous(line, dstp)
+			n := r.tripleCount
+			IRI, line, err = r.inAnonymous(line, dstp)
+			if err == nil && r.tripleCount == n {
+				line, isLabel, err = r.afterLabelOrSubject(IRI, line)
+			}
+			if err != nil {
+				return "", nil, err
+			}
+			if r.tripleCount == n {
+				if !isLabel {
+					return IRI, line, nil
+				}
+				break
+			}
+			// predicate–object list is optional after a property list
+			line, err = r.lineContinue(line)
+			if err != nil {
+				return "", nil, err
+			}
+			switch {
+			case line[0] == '.':
+				return "", line[1:], nil
+			case line[0] == '}' && (r.inGraph || r.formulaLevel != 0):
+				return "", line, nil
+			}
+			return IRI, line, nil
 		case '(':
 			return r.inCollection(line, dstp)
 		case '_':
-			return r.inBlankLabel(line)
+			IRI, line, err = r.inBlankLabel(line)
+			if err == nil {
+				line, isLabel, err = r.afterLabelOrSubject(IRI, line)
+			}
+			if err != nil || !isLabel {
+				return IRI, line, err
+			}
+		case '?':
+			if r.grammar != n3Grammar {
+				return "", nil, r.syntaxErr(ErrUnexpectedToken, line, "illegal subject token")
+			}
+			return r.inVariable(line)
+		case '{':
+			if r.grammar == n3Grammar {
+				return r.inFormula(line)
+			}
+			if r.grammar != trigGrammar || r.inGraph {
+				return "", nil, r.syntaxErr(ErrUnexpectedToken, line, "illegal subject token")
+			}
+			r.openGraph("")
+			line = line[1:]
+		case '}':
+			if r.formulaLevel != 0 {
+				return "", line, nil // formula closes on next read
+			}
+			if !r.inGraph {
+				return "", nil, r.syntaxErr(ErrUnexpectedToken, line, "illegal subject token")
+			}
+			r.inGraph = false
+			r.graphIRI = ""
+			line = line[1:]
 		default:
 			IRI, line, err = r.inUndeterminedSubject(line)
-			// IRI is zero on PREFIX or BASE encounter
-			if err != nil || IRI != "" {
+			// IRI is zero on PREFIX, BASE or GRAPH encounter
+			if err == nil && IRI != "" {
+				line, isLabel, err = r.afterLabelOrSubject(IRI, line)
+			}
+			if err != nil || IRI != "" && !isLabel {
 				return IRI, line, err
 			}
 		}
 
-		line, err = r.lineContinue(line)
+		// directives and graphs may end the input
+		r.pending = line
+		line, err = r.line()
 		if err != nil {
 			return "", nil, err
 		}
@@ -238,6 +833,9 @@ func (r *Reader) readSubject(dstp *[]Triple) (IRI string, lineRemainder []byte,
 
 // InDirective continues from "@" in the buffer.
 func (r *Reader) inDirective(line []byte) (remainder []byte, err error) {
+	if r.inGraph {
+		return nil, r.syntaxErr(ErrUnexpectedToken, line, "directive inside graph")
+	}
 	if len(line) < 2 {
 		return nil, fmt.Errorf("%w: directive interrupted", io.ErrUnexpectedEOF)
 	}
@@ -259,8 +857,25 @@ func (r *Reader) inDirective(line []byte) (remainder []byte, err error) {
 
 		terminated := true
 		return r.afterPrefixDirective(line, terminated)
+
+	case 'f':
+		if r.grammar == n3Grammar {
+			return r.inQuantifier(line)
+		}
+
+	case 'v':
+		if r.Version != RDF12 {
+			break
+		}
+		line, err = r.inToken(line[1:], "version")
+		if err != nil {
+			return nil, err
+		}
+
+		terminated := true
+		return r.afterVersionDirective(line, terminated)
 	}
-	return nil, r.syntaxErr(`unknown directive; expected either "@base" or "@prefix"`)
+	return nil, r.syntaxErr(ErrUnexpectedToken, line, `unknown directive; expected either "@base" or "@prefix"`)
 }
 
 // InToken continues from the first letter of token in the buffer.
@@ -270,7 +885,7 @@ func (r *Reader) inToken(line []byte, token string) (remainder []byte, err error
 			return nil, fmt.Errorf("%w: token %q interrupted", io.ErrUnexpectedEOF, token)
 		}
 		if line[i] != token[i] {
-			return nil, r.syntaxErr(fmt.Sprintf("unknown token; expected %q", token))
+			return nil, r.syntaxErr(ErrUnexpectedToken, line, fmt.Sprintf("unknown token; expected %q", token))
 		}
 	}
 	return line[len(token):], nil
@@ -284,7 +899,7 @@ func (r *Reader) afterBaseDirective(line []byte, terminated bool) (remainder []b
 		return nil, err
 	}
 	if line[0] != '<' {
-		return nil, r.syntaxErr(`IRI reference of base directive does not start with "<"`)
+		return nil, r.syntaxErr(ErrUnexpectedToken, line, `IRI reference of base directive does not start with "<"`)
 	}
 	s, line, err := r.inIRI(line)
 	if err != nil {
@@ -298,39 +913,32 @@ func (r *Reader) afterBaseDirective(line []byte, terminated bool) (remainder []b
 			return nil, err
 		}
 		if line[0] != '.' {
-			return nil, r.syntaxErr(`base directive not terminated with "."`)
+			return nil, r.syntaxErr(ErrUnexpectedToken, line, `base directive not terminated with "."`)
 		}
 		line = line[1:]
 	}
+
+	if r.handler != nil {
+		err = r.handler.Base(s)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return line, nil
 }
 
 // AfterPrefixeDirective continues with line after a "@prefix" or "PREFIX" encounter.
 func (r *Reader) afterPrefixDirective(line []byte, terminated bool) (remainder []byte, err error) {
-	var label string
 	line, err = r.lineContinue(line)
 	if err != nil {
 		return nil, err
 	}
-ReadLabel:
-	for i := 0; ; i++ {
-		if i >= len(line) {
-			return nil, fmt.Errorf("%w: prefix directive label interrupted", io.ErrUnexpectedEOF)
-		}
-
-		switch line[i] {
-		case ':':
-			label = string(line[:i])
-			line = line[i+1:]
-			break ReadLabel
-
-		case ' ', '\t', '\r', '\n':
-			return nil, r.syntaxErr(`prefix label without ":" suffix`)
-
-		default:
-			// TODO: validate
-		}
+	i := scanPrefixLabel(line)
+	if i >= len(line) || line[i] != ':' {
+		return nil, r.syntaxErr(ErrUnexpectedToken, line[i:], `prefix label without ":" suffix`)
 	}
+	label := string(line[:i])
+	line = line[i+1:]
 
 	var prefix string
 	line, err = r.lineContinue(line)
@@ -338,7 +946,7 @@ ReadLabel:
 		return nil, err
 	}
 	if line[0] != '<' {
-		return nil, r.syntaxErr(`IRI of prefix directive does not start with "<"`)
+		return nil, r.syntaxErr(ErrUnexpectedToken, line, `IRI of prefix directive does not start with "<"`)
 	}
 	prefix, line, err = r.inIRI(line)
 	if err != nil {
@@ -357,10 +965,17 @@ ReadLabel:
 			return nil, err
 		}
 		if line[0] != '.' {
-			return nil, r.syntaxErr(`prefix directive is not terminated with "."`)
+			return nil, r.syntaxErr(ErrUnexpectedToken, line, `prefix directive is not terminated with "."`)
 		}
 		line = line[1:]
 	}
+
+	if r.handler != nil {
+		err = r.handler.Prefix(label, prefix)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return line, nil
 }
 
@@ -374,42 +989,16 @@ func (r *Reader) readPredicate(line []byte) (IRI string, remainder []byte, err e
 		return r.inIRI(line)
 	}
 
-	var prefixLabel []byte
-	i := 0
-ReadToken:
-	for ; ; i++ {
-		if i >= len(line) {
-			return "", nil, fmt.Errorf("%w: predicate interrupted", io.ErrUnexpectedEOF)
-		}
-		switch line[i] {
-		case ' ', '\t', '\r', '\n': // WS
-			break ReadToken
-
-		case ':':
-			if prefixLabel == nil {
-				prefixLabel = line[:i]
-				line = line[i+1:]
-				i = 0
-			}
-
-		default:
-			// TODO: validation
-		}
-	}
-
-	if prefixLabel == nil {
-		if i == 1 && line[0] == 'a' {
-			return "http://www.w3.org/1999/02/22-rdf-syntax-ns#type", line[2:], err
-		}
-		return "", nil, r.syntaxErr("illegal predicate token")
-	}
-
-	// allocation omitted by compiler
-	prefix, ok := r.prefixPerLabel[string(prefixLabel)]
-	if !ok {
-		return "", nil, r.syntaxErr("undefined prefix on predicate")
+	IRI, keyword, remainder, err := r.inPrefixedName(line)
+	switch {
+	case err != nil:
+		return "", nil, err
+	case IRI != "":
+		return IRI, remainder, nil
+	case len(keyword) == 1 && keyword[0] == 'a':
+		return "http://www.w3.org/1999/02/22-rdf-syntax-ns#type", remainder, nil
 	}
-	return prefix + string(line[:i]), line[i+1:], nil
+	return "", nil, r.syntaxErr(ErrUnexpectedToken, line, "illegal predicate token")
 }
 
 // ReadObject maps the next node to t. It may append to dstp on encounters with
@@ -422,17 +1011,31 @@ func (r *Reader) readObject(line []byte, t *Triple, dstp *[]Triple) (remainder [
 
 	switch line[0] {
 	case '<':
-		t.Object, remainder, err = r.inIRI(line)
+		if len(line) > 1 && line[1] == '<' {
+			t.Object, remainder, err = r.inTripleNode(line, dstp)
+		} else {
+			t.Object, remainder, err = r.inIRI(line)
+		}
 	case '_':
 		t.Object, remainder, err = r.inBlankLabel(line)
 	case '[':
 		t.Object, remainder, err = r.inAnonymous(line, dstp)
 	case '(':
 		t.Object, remainder, err = r.inCollection(line, dstp)
-	case '"':
-		remainder, err = r.inDoubleQuote(line, t)
-	case '\'':
-		remainder, err = r.inSingleQuote(line, t)
+	case '{':
+		if r.grammar == n3Grammar {
+			t.Object, remainder, err = r.inFormula(line)
+		} else {
+			remainder, err = r.inUndeterminedObject(line, t)
+		}
+	case '?':
+		if r.grammar == n3Grammar {
+			t.Object, remainder, err = r.inVariable(line)
+		} else {
+			remainder, err = r.inUndeterminedObject(line, t)
+		}
+	case '"', '\'':
+		remainder, err = r.inQuote(line, t)
 	case '+', '-':
 		remainder, err = r.inNumberWithSign(line, 1, t)
 	case '.', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
@@ -440,122 +1043,94 @@ func (r *Reader) readObject(line []byte, t *Triple, dstp *[]Triple) (remainder [
 	default:
 		remainder, err = r.inUndeterminedObject(line, t)
 	}
+	if err == nil && r.grammar == n3Grammar && t.DatatypeIRI == "" {
+		t.Object = r.quantifiedTerm(t.Object)
+	}
 	return
 }
 
-// Line could start with a prefixed name, or "BASE", or "PREFIX".
-// The IRI return is zero for directive encounters.
+// Line could start with a prefixed name, or "BASE", or "PREFIX", or "VERSION"
+// in RDF 1.2, or "GRAPH" in TriG. The IRI return is zero for directive and
+// graph encounters.
 func (r *Reader) inUndeterminedSubject(line []byte) (IRI string, remainder []byte, err error) {
-	var prefixLabel, local []byte
-ReadToken:
-	for i := 0; ; i++ {
-		if i >= len(line) {
-			return "", nil, fmt.Errorf("%w: subject interrupted", io.ErrUnexpectedEOF)
-		}
-		switch line[i] {
-		case ' ', '\t', '\r', '\n': // WS
-			local = line[:i]
-			line = line[i+1:]
-			break ReadToken
-
-		case ':':
-			if prefixLabel == nil {
-				prefixLabel = line[:i]
-				line = line[i+1:]
-				i = 0
-			}
-
-		default:
-			// TODO: validation
-		}
+	IRI, local, remainder, err := r.inPrefixedName(line)
+	if err != nil || IRI != "" {
+		return IRI, remainder, err
+	}
+	if r.inGraph {
+		return "", nil, r.syntaxErr(ErrUnexpectedToken, line, "illegal subject token")
 	}
 
-	if prefixLabel == nil {
-		// tokens are case insensitive 😖
-		switch len(local) {
-		case 4:
-			if (local[0] == 'B' || local[0] == 'b') &&
-				(local[1] == 'A' || local[1] == 'a') &&
-				(local[2] == 'S' || local[2] == 's') &&
-				(local[3] == 'E' || local[3] == 'e') {
-				terminated := false
-				line, err = r.afterBaseDirective(line, terminated)
-				return "", line, err
-			}
+	// tokens are case insensitive 😖
+	switch len(local) {
+	case 4:
+		if (local[0] == 'B' || local[0] == 'b') &&
+			(local[1] == 'A' || local[1] == 'a') &&
+			(local[2] == 'S' || local[2] == 's') &&
+			(local[3] == 'E' || local[3] == 'e') {
+			terminated := false
+			remainder, err = r.afterBaseDirective(remainder, terminated)
+			return "", remainder, err
+		}
 
-		case 6:
-			if (local[0] == 'P' || local[0] == 'p') &&
-				(local[1] == 'R' || local[1] == 'r') &&
-				(local[2] == 'E' || local[2] == 'e') &&
-				(local[3] == 'F' || local[3] == 'f') &&
-				(local[4] == 'I' || local[4] == 'i') &&
-				(local[5] == 'X' || local[5] == 'x') {
-				terminated := false
-				line, err = r.afterPrefixDirective(line, terminated)
-				return "", line, err
-			}
+	case 6:
+		if (local[0] == 'P' || local[0] == 'p') &&
+			(local[1] == 'R' || local[1] == 'r') &&
+			(local[2] == 'E' || local[2] == 'e') &&
+			(local[3] == 'F' || local[3] == 'f') &&
+			(local[4] == 'I' || local[4] == 'i') &&
+			(local[5] == 'X' || local[5] == 'x') {
+			terminated := false
+			remainder, err = r.afterPrefixDirective(remainder, terminated)
+			return "", remainder, err
+		}
 
+	case 7:
+		if r.Version == RDF12 &&
+			(local[0] == 'V' || local[0] == 'v') &&
+			(local[1] == 'E' || local[1] == 'e') &&
+			(local[2] == 'R' || local[2] == 'r') &&
+			(local[3] == 'S' || local[3] == 's') &&
+			(local[4] == 'I' || local[4] == 'i') &&
+			(local[5] == 'O' || local[5] == 'o') &&
+			(local[6] == 'N' || local[6] == 'n') {
+			terminated := false
+			remainder, err = r.afterVersionDirective(remainder, terminated)
+			return "", remainder, err
 		}
-		return "", nil, r.syntaxErr("illegal subject token")
-	}
 
-	// allocation omitted by compiler
-	prefix, ok := r.prefixPerLabel[string(prefixLabel)]
-	if !ok {
-		return "", nil, r.syntaxErr("undefined prefix on subject node")
+	case 5:
+		if r.grammar == trigGrammar &&
+			(local[0] == 'G' || local[0] == 'g') &&
+			(local[1] == 'R' || local[1] == 'r') &&
+			(local[2] == 'A' || local[2] == 'a') &&
+			(local[3] == 'P' || local[3] == 'p') &&
+			(local[4] == 'H' || local[4] == 'h') {
+			remainder, err = r.afterGraphKeyword(remainder)
+			return "", remainder, err
+		}
 	}
-	return prefix + string(local), line, nil
+	return "", nil, r.syntaxErr(ErrUnexpectedToken, line, "illegal subject token")
 }
 
 // Line could start with a prefixed name, or boolean "true" or "false".
 func (r *Reader) inUndeterminedObject(line []byte, t *Triple) (remainder []byte, err error) {
-	var prefixLabel []byte
-	i := 0
-ReadToken:
-	for ; ; i++ {
-		if i >= len(line) {
-			return nil, fmt.Errorf("%w: object interrupted", io.ErrUnexpectedEOF)
-		}
-		switch line[i] {
-		case ' ', '\t', '\r', '\n': // WS
-			break ReadToken
-
-		case ':':
-			if prefixLabel == nil {
-				prefixLabel = line[:i]
-				line = line[i+1:]
-				i = 0
-			}
-
-		default:
-			// TODO: validation
-			continue
-		}
+	IRI, keyword, remainder, err := r.inPrefixedName(line)
+	if err != nil {
+		return nil, err
 	}
-
-	if prefixLabel == nil {
-		switch string(line[:i]) {
-		case "true":
-			t.Object = "true"
-			t.DatatypeIRI = XSDBoolean
-			return line[i+1:], nil
-
-		case "false":
-			t.Object = "true"
-			t.DatatypeIRI = XSDBoolean
-			return line[i+1:], nil
-		}
-		return nil, r.syntaxErr("illegal object token")
+	if IRI != "" {
+		t.Object = IRI
+		return remainder, nil
 	}
-	// got a prefixed name
 
-	// allocation omitted by compiler
-	prefix, ok := r.prefixPerLabel[string(prefixLabel)]
-	if !ok {
-		return nil, r.syntaxErr("undefined prefix on object node")
+	switch string(keyword) {
+	case "true", "false":
+		t.Object = string(keyword)
+		t.DatatypeIRI = XSDBoolean
+		return remainder, nil
 	}
-	t.Object = prefix + string(line[:i])
-	return line[i+1:], nil
+	return nil, r.syntaxErr(ErrUnexpectedToken, line, "illegal object token")
 }
 
 // InIRI continues from "<" in the buffer.
@@ -564,77 +1139,185 @@ func (r *Reader) inIRI(line []byte) (IRI string, remainder []byte, err error) {
 		c := line[i]
 		switch c {
 		case '>':
-			IRI = string(line[1:i])
-			l, err := url.Parse(IRI)
-			if err == nil && l.Scheme == "" {
-				if r.BaseIRI == nil {
-					return "", nil, r.syntaxErr("relative reference without base IRI")
-				}
-				IRI = r.BaseIRI.ResolveReference(l).String()
-			}
-			return IRI, line[i+1:], err
+			return r.resolveIRI(line, string(line[1:i]), line[i+1:])
 
 		case '<', '"', '{', '}', '|', '^', '`':
-			return "", nil, r.syntaxErr("illegal character in IRI reference")
+			return "", nil, r.syntaxErr(ErrIllegalChar, line[i:], "illegal character in IRI reference")
 
 		case '\\':
-			panic("TODO: Unicode escape")
+			return r.inIRIEscape(line[1:i], line[i:])
 
 		default:
 			if c <= 0x20 {
-				return "", nil, r.syntaxErr("control character in IRI reference")
+				return "", nil, r.syntaxErr(ErrIllegalChar, line[i:], "control character in IRI reference")
 			}
 		}
 	}
 	return "", nil, fmt.Errorf("%w: URI reference interupted", io.ErrUnexpectedEOF)
 }
 
-// InBlankLabel continues from "_" in the buffer.
-func (r *Reader) inBlankLabel(line []byte) (IRI string, remainder []byte, err error) {
-	if len(line) > 1 {
-		if line[1] != ':' {
-			return "", nil, r.syntaxErr(`prefixed name starts with underscore ("_")`)
-		}
+// InIRIEscape continues from "\\" in the buffer, with copyAsIs as the IRI
+// reference content read before.
+func (r *Reader) inIRIEscape(copyAsIs, line []byte) (IRI string, remainder []byte, err error) {
+	var b strings.Builder
+	// oversized allocation is better than resizes later on
+	b.Grow(len(copyAsIs) + len(line))
+	b.Write(copyAsIs)
 
-		for i := 2; i < len(line); i++ {
-			switch line[i] {
-			case ' ', '\t', '\r', '\n': // WS
-				return r.skolemIRIRoot() + "blank#" + string(line[2:i]), line[i+1:], nil
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch c {
+		case '>':
+			b.Write(line[:i])
+			return r.resolveIRI(copyAsIs, b.String(), line[i+1:])
+
+		case '<', '"', '{', '}', '|', '^', '`':
+			return "", nil, r.syntaxErr(ErrIllegalChar, line[i:], "illegal character in IRI reference")
+
+		case '\\':
+			b.Write(line[:i])
+			escape := line[i:]
+			if i+1 >= len(line) {
+				return "", nil, fmt.Errorf("%w: URI reference interupted", io.ErrUnexpectedEOF)
+			}
+			var u rune
+			switch line[i+1] {
+			case 'u':
+				u, line, err = r.hexRune(line[i+2:], 4)
+			case 'U':
+				u, line, err = r.hexRune(line[i+2:], 8)
+			default:
+				return "", nil, r.syntaxErr(ErrIllegalEscape, escape, "illegal escape in IRI reference; only UCHAR permitted")
+			}
+			if err != nil {
+				return "", nil, err
+			}
+			// “IRIREF … excluding the characters
+			// <>"{}|^`\ and those in the range #x00-#x20”
+			switch u {
+			case '<', '>', '"', '{', '}', '|', '^', '`', '\\':
+				return "", nil, r.syntaxErr(ErrIllegalChar, escape, "Unicode escape of illegal character in IRI reference")
 			}
+			if u <= 0x20 {
+				return "", nil, r.syntaxErr(ErrIllegalChar, escape, "Unicode escape of control character in IRI reference")
+			}
+			b.WriteRune(u)
+			i = -1 // continue at start of remainder
 
-			// TODO: validate label character
+		default:
+			if c <= 0x20 {
+				return "", nil, r.syntaxErr(ErrIllegalChar, line[i:], "control character in IRI reference")
+			}
 		}
 	}
-	return "", nil, fmt.Errorf("%w: blank node not closed", io.ErrUnexpectedEOF)
+	return "", nil, fmt.Errorf("%w: URI reference interupted", io.ErrUnexpectedEOF)
+}
+
+// ResolveIRI applies the base IRI on relative references. The position of the
+// reference in the buffer is at.
+func (r *Reader) resolveIRI(at []byte, s string, remainder []byte) (IRI string, _ []byte, err error) {
+	l, err := url.Parse(s)
+	if err != nil {
+		return "", nil, r.syntaxErr(ErrIllegalIRI, at, "malformed IRI reference")
+	}
+	if l.Scheme != "" {
+		return s, remainder, nil
+	}
+	if r.BaseIRI == nil {
+		return "", nil, r.syntaxErr(ErrNoBaseIRI, at, "relative reference without base IRI")
+	}
+	IRI = r.BaseIRI.ResolveReference(l).String()
+	if strings.HasSuffix(s, "#") && !strings.HasSuffix(IRI, "#") {
+		IRI += "#" // empty fragment lost in URL
+	}
+	return IRI, remainder, nil
 }
 
-// InAnonymous continues from "[" in the buffer.
+// InAnonymous continues from "[" in the buffer. Any predicate–object list
+// within gets appended to dstp.
 func (r *Reader) inAnonymous(line []byte, dstp *[]Triple) (skolemIRI string, remainder []byte, err error) {
-	r.anonNodeNo++
-	skolemIRI = fmt.Sprintf("%sanon#%d", r.skolemIRIRoot(), r.anonNodeNo)
+	skolemIRI = r.newAnonIRI()
+
+	line, err = r.lineContinue(line[1:])
+	if err != nil {
+		return "", nil, err
+	}
+	if line[0] == ']' {
+		return skolemIRI, line[1:], nil
+	}
 
-	// may contain predicate–object list
+	r.propListLevel++
+	line, err = r.readPredicateObjectList(skolemIRI, line, dstp)
+	if err != nil {
+		return "", nil, err
+	}
+	r.propListLevel--
+	return skolemIRI, line, nil
+}
+
+// RDF vocabulary for collections, a.k.a. lists.
+const (
+	rdfFirst = "http://www.w3.org/1999/02/22-rdf-syntax-ns#first"
+	rdfRest  = "http://www.w3.org/1999/02/22-rdf-syntax-ns#rest"
+	rdfNil   = "http://www.w3.org/1999/02/22-rdf-syntax-ns#nil"
+)
+
+// InCollection continues from "(" in the buffer. Each of the list cells gets a
+// Skolem IRI. The empty collection "()" is rdf:nil.
+func (r *Reader) inCollection(line []byte, dstp *[]Triple) (firstIRI string, remainder []byte, err error) {
+	r.collectionLevel++
+	line = line[1:] // pass '('
+
+	var cellIRI string // current position
 	for {
-		for i := 1; i < len(line); i++ {
-			switch line[i] {
-			case ' ', '\t', '\r', '\n': // WS
-				continue
-			case ']':
-				return skolemIRI, line[i+1:], err
-			default:
-				r.propListLevel++
-				panic("TODO: anonymous predicate-object list not implemented yet")
+		line, err = r.lineContinue(line)
+		if err != nil {
+			return "", nil, err
+		}
+
+		if line[0] == ')' {
+			r.collectionLevel--
+			if cellIRI == "" {
+				return rdfNil, line[1:], nil
+			}
+			err = r.appendTriple(dstp, Triple{
+				SubjectIRI:   cellIRI,
+				PredicateIRI: rdfRest,
+				Object:       rdfNil,
+			})
+			if err != nil {
+				return "", nil, err
+			}
+			return firstIRI, line[1:], nil
+		}
+
+		// link new cell
+		nextIRI := r.newAnonIRI()
+		if cellIRI == "" {
+			firstIRI = nextIRI
+		} else {
+			err = r.appendTriple(dstp, Triple{
+				SubjectIRI:   cellIRI,
+				PredicateIRI: rdfRest,
+				Object:       nextIRI,
+			})
+			if err != nil {
+				return "", nil, err
 			}
 		}
+		cellIRI = nextIRI
 
-		line, err = r.lineContinue(nil)
+		t := Triple{
+			SubjectIRI:   cellIRI,
+			PredicateIRI: rdfFirst,
+		}
+		line, err = r.readObject(line, &t, dstp)
+		if err != nil {
+			return "", nil, err
+		}
+		err = r.appendTriple(dstp, t)
 		if err != nil {
 			return "", nil, err
 		}
 	}
 }
-
-// InCollection continues from "(" in the buffer.
-func (r *Reader) inCollection(line []byte, dstp *[]Triple) (firstIRI string, remainder []byte, err error) {
-	panic("TODO: collection list not implemented yet")
-}
@@ -2,6 +2,7 @@ package tripn
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"slices"
@@ -33,7 +34,7 @@ var turtleTriples = []struct {
  <http://example.com/object1> 
 	. `,
 		[]Triple{
-			{"http://example.com/subject1", "http://example.com/predicate1", "http://example.com/object1", "", ""},
+			{"http://example.com/subject1", "http://example.com/predicate1", "http://example.com/object1", "", "", ""},
 		},
 	},
 
@@ -42,27 +43,27 @@ var turtleTriples = []struct {
 BASE <http://example.net/>              # SPARQL variant without dot
 <subject2> <predicate2> <object2> .`,
 		[]Triple{
-			{"http://example.com/subject1", "http://example.com/predicate1", "http://example.com/object1", "", ""},
-			{"http://example.net/subject2", "http://example.net/predicate2", "http://example.net/object2", "", ""},
+			{"http://example.com/subject1", "http://example.com/predicate1", "http://example.com/object1", "", "", ""},
+			{"http://example.net/subject2", "http://example.net/predicate2", "http://example.net/object2", "", "", ""},
 		},
 	},
 	{` base <http://example.com/> <subject1> <predicate1> <object1> .
 	   @base <http://example.net/> . <subject2> <predicate2> <object2> .
 # uncommon yet legal`,
 		[]Triple{
-			{"http://example.com/subject1", "http://example.com/predicate1", "http://example.com/object1", "", ""},
-			{"http://example.net/subject2", "http://example.net/predicate2", "http://example.net/object2", "", ""},
+			{"http://example.com/subject1", "http://example.com/predicate1", "http://example.com/object1", "", "", ""},
+			{"http://example.net/subject2", "http://example.net/predicate2", "http://example.net/object2", "", "", ""},
 		},
 	},
 
 	{`bASe <http://example.com/> @prefix p: <path/> . p:subject1 p:predicate1 p:object1 .`,
 		[]Triple{
-			{"http://example.com/path/subject1", "http://example.com/path/predicate1", "http://example.com/path/object1", "", ""},
+			{"http://example.com/path/subject1", "http://example.com/path/predicate1", "http://example.com/path/object1", "", "", ""},
 		},
 	},
 	{`@base <http://example.com/> . PrefiX p: <path/> p:subject1 p:predicate1 p:object1 .`,
 		[]Triple{
-			{"http://example.com/path/subject1", "http://example.com/path/predicate1", "http://example.com/path/object1", "", ""},
+			{"http://example.com/path/subject1", "http://example.com/path/predicate1", "http://example.com/path/object1", "", "", ""},
 		},
 	},
 
@@ -70,15 +71,15 @@ BASE <http://example.net/>              # SPARQL variant without dot
           :subject1 :predicate1 :object1 .
           :subject2 a :object2 .              # rdf:type predicate`,
 		[]Triple{
-			{"http://example.com/subject1", "http://example.com/predicate1", "http://example.com/object1", "", ""},
-			{"http://example.com/subject2", "http://www.w3.org/1999/02/22-rdf-syntax-ns#type", "http://example.com/object2", "", ""},
+			{"http://example.com/subject1", "http://example.com/predicate1", "http://example.com/object1", "", "", ""},
+			{"http://example.com/subject2", "http://www.w3.org/1999/02/22-rdf-syntax-ns#type", "http://example.com/object2", "", "", ""},
 		},
 	},
 
 	{`<http://伝言.example.com/?user=أكرم&amp;channel=R%26D> a true .`,
 		[]Triple{
 			{"http://伝言.example.com/?user=أكرم&amp;channel=R%26D", "http://www.w3.org/1999/02/22-rdf-syntax-ns#type",
-				"true", "http://www.w3.org/2001/XMLSchema#boolean", ""},
+				"true", "http://www.w3.org/2001/XMLSchema#boolean", "", ""},
 		},
 	},
 
@@ -86,8 +87,8 @@ BASE <http://example.net/>              # SPARQL variant without dot
 	{`<http://example.org/#spiderman> <http://www.perceive.net/schemas/relationship/enemyOf> <http://example.org/#green-goblin> ;
                                              <http://xmlns.com/foaf/0.1/name> "Spiderman" .`,
 		[]Triple{
-			{"http://example.org/#spiderman", "http://www.perceive.net/schemas/relationship/enemyOf", "http://example.org/#green-goblin", "", ""},
-			{"http://example.org/#spiderman", "http://xmlns.com/foaf/0.1/name", "Spiderman", "http://www.w3.org/2001/XMLSchema#string", ""},
+			{"http://example.org/#spiderman", "http://www.perceive.net/schemas/relationship/enemyOf", "http://example.org/#green-goblin", "", "", ""},
+			{"http://example.org/#spiderman", "http://xmlns.com/foaf/0.1/name", "Spiderman", "http://www.w3.org/2001/XMLSchema#string", "", ""},
 		},
 	},
 
@@ -95,9 +96,9 @@ BASE <http://example.net/>              # SPARQL variant without dot
 	{`<http://example.org/#spiderman> <http://xmlns.com/foaf/0.1/name> "Spiderman", "Человек-паук"@ru .`,
 		[]Triple{
 			{"http://example.org/#spiderman", "http://xmlns.com/foaf/0.1/name", "Spiderman",
-				"http://www.w3.org/2001/XMLSchema#string", ""},
+				"http://www.w3.org/2001/XMLSchema#string", "", ""},
 			{"http://example.org/#spiderman", "http://xmlns.com/foaf/0.1/name", "Человек-паук",
-				"http://www.w3.org/1999/02/22-rdf-syntax-ns#langString", "ru"},
+				"http://www.w3.org/1999/02/22-rdf-syntax-ns#langString", "ru", ""},
 		},
 	},
 
@@ -121,31 +122,31 @@ BASE <http://example.net/>              # SPARQL variant without dot
 		[]Triple{{
 			"http://example.org/#green-goblin",
 			"http://www.perceive.net/schemas/relationship/enemyOf",
-			"http://example.org/#spiderman", "", "",
+			"http://example.org/#spiderman", "", "", "",
 		}, {
 			"http://example.org/#green-goblin",
 			"http://www.w3.org/1999/02/22-rdf-syntax-ns#type",
-			"http://xmlns.com/foaf/0.1/Person", "", "",
+			"http://xmlns.com/foaf/0.1/Person", "", "", "",
 		}, {
 			"http://example.org/#green-goblin",
 			"http://xmlns.com/foaf/0.1/name",
-			"Green Goblin", "http://www.w3.org/2001/XMLSchema#string", "",
+			"Green Goblin", "http://www.w3.org/2001/XMLSchema#string", "", "",
 		}, {
 			"http://example.org/#spiderman",
 			"http://www.perceive.net/schemas/relationship/enemyOf",
-			"http://example.org/#green-goblin", "", "",
+			"http://example.org/#green-goblin", "", "", "",
 		}, {
 			"http://example.org/#spiderman",
 			"http://www.w3.org/1999/02/22-rdf-syntax-ns#type",
-			"http://xmlns.com/foaf/0.1/Person", "", "",
+			"http://xmlns.com/foaf/0.1/Person", "", "", "",
 		}, {
 			"http://example.org/#spiderman",
 			"http://xmlns.com/foaf/0.1/name",
-			"Spiderman", "http://www.w3.org/2001/XMLSchema#string", "",
+			"Spiderman", "http://www.w3.org/2001/XMLSchema#string", "", "",
 		}, {
 			"http://example.org/#spiderman",
 			"http://xmlns.com/foaf/0.1/name",
-			"Человек-паук", "http://www.w3.org/1999/02/22-rdf-syntax-ns#langString", "ru",
+			"Человек-паук", "http://www.w3.org/1999/02/22-rdf-syntax-ns#langString", "ru", "",
 		}},
 	},
 
@@ -167,25 +168,25 @@ and up to two sequential apostrophes ('').''' .
 `,
 		[]Triple{
 			{"http://example.org/vocab/show/218", "http://www.w3.org/2000/01/rdf-schema#label",
-				"That Seventies Show", "http://www.w3.org/2001/XMLSchema#string", ""},
+				"That Seventies Show", "http://www.w3.org/2001/XMLSchema#string", "", ""},
 			{"http://example.org/vocab/show/218", "http://www.w3.org/2000/01/rdf-schema#label",
-				"That Seventies Show", "http://www.w3.org/2001/XMLSchema#string", ""},
+				"That Seventies Show", "http://www.w3.org/2001/XMLSchema#string", "", ""},
 			{"http://example.org/vocab/show/218", "http://www.w3.org/2000/01/rdf-schema#label",
-				"That Seventies Show", "http://www.w3.org/2001/XMLSchema#string", ""},
+				"That Seventies Show", "http://www.w3.org/2001/XMLSchema#string", "", ""},
 			{"http://example.org/vocab/show/218", "http://example.org/vocab/show/localName",
 				"That Seventies Show",
-				"http://www.w3.org/1999/02/22-rdf-syntax-ns#langString", "en"},
+				"http://www.w3.org/1999/02/22-rdf-syntax-ns#langString", "en", ""},
 			{"http://example.org/vocab/show/218", "http://example.org/vocab/show/localName",
 				"Cette Série des Années Soixante-dix",
-				"http://www.w3.org/1999/02/22-rdf-syntax-ns#langString", "fr"},
+				"http://www.w3.org/1999/02/22-rdf-syntax-ns#langString", "fr", ""},
 			{"http://example.org/vocab/show/218", "http://example.org/vocab/show/localName",
 				"Cette Série des Années Septante",
-				"http://www.w3.org/1999/02/22-rdf-syntax-ns#langString", "fr-be"},
+				"http://www.w3.org/1999/02/22-rdf-syntax-ns#langString", "fr-be", ""},
 			{"http://example.org/vocab/show/218", "http://example.org/vocab/show/blurb",
 				`This is a multi-line                        # literal with embedded new lines and quotes
 literal with many quotes (""""")
 and up to two sequential apostrophes ('').`,
-				"http://www.w3.org/2001/XMLSchema#string", ""},
+				"http://www.w3.org/2001/XMLSchema#string", "", ""},
 		},
 	},
 
@@ -198,11 +199,11 @@ and up to two sequential apostrophes ('').`,
 `,
 		[]Triple{
 			{"http://en.wikipedia.org/wiki/Helium", "http://example.org/elements/atomicNumber",
-				"2", "http://www.w3.org/2001/XMLSchema#integer", ""},
+				"2", "http://www.w3.org/2001/XMLSchema#integer", "", ""},
 			{"http://en.wikipedia.org/wiki/Helium", "http://example.org/elements/atomicMass",
-				"4.002602", "http://www.w3.org/2001/XMLSchema#decimal", ""},
+				"4.002602", "http://www.w3.org/2001/XMLSchema#decimal", "", ""},
 			{"http://en.wikipedia.org/wiki/Helium", "http://example.org/elements/specificGravity",
-				"1.663E-4", "http://www.w3.org/2001/XMLSchema#double", ""},
+				"1.663E-4", "http://www.w3.org/2001/XMLSchema#double", "", ""},
 		},
 	},
 
@@ -213,19 +214,185 @@ _:alice foaf:knows _:bob .
 _:bob foaf:knows _:alice .`,
 		[]Triple{
 			{"http://example.com/skolem-stub/blank#alice", "http://xmlns.com/foaf/0.1/knows",
-				"http://example.com/skolem-stub/blank#bob", "", ""},
+				"http://example.com/skolem-stub/blank#bob", "", "", ""},
 			{"http://example.com/skolem-stub/blank#bob", "http://xmlns.com/foaf/0.1/knows",
-				"http://example.com/skolem-stub/blank#alice", "", ""},
+				"http://example.com/skolem-stub/blank#alice", "", "", ""},
+		},
+	},
+
+	// collection EXAMPLE 18 from W3C's “RDF 1.1 Turtle” Recommendation
+	{`@prefix : <http://example.org/stuff/1.0/> .
+:a :b ( "apple" "banana" ) .
+`,
+		[]Triple{
+			{"http://example.com/skolem-stub/anon#1", "http://www.w3.org/1999/02/22-rdf-syntax-ns#first",
+				"apple", "http://www.w3.org/2001/XMLSchema#string", "", ""},
+			{"http://example.com/skolem-stub/anon#1", "http://www.w3.org/1999/02/22-rdf-syntax-ns#rest",
+				"http://example.com/skolem-stub/anon#2", "", "", ""},
+			{"http://example.com/skolem-stub/anon#2", "http://www.w3.org/1999/02/22-rdf-syntax-ns#first",
+				"banana", "http://www.w3.org/2001/XMLSchema#string", "", ""},
+			{"http://example.com/skolem-stub/anon#2", "http://www.w3.org/1999/02/22-rdf-syntax-ns#rest",
+				"http://www.w3.org/1999/02/22-rdf-syntax-ns#nil", "", "", ""},
+			{"http://example.org/stuff/1.0/a", "http://example.org/stuff/1.0/b",
+				"http://example.com/skolem-stub/anon#1", "", "", ""},
+		},
+	},
+
+	// nested collections as subject and object, stretched over lines
+	{`@prefix : <http://example.org/> .
+( 42
+  ( <http://example.org/x> )
+) :p () , (
+	() ) .
+`,
+		[]Triple{
+			{"http://example.com/skolem-stub/anon#1", "http://www.w3.org/1999/02/22-rdf-syntax-ns#first",
+				"42", "http://www.w3.org/2001/XMLSchema#integer", "", ""},
+			{"http://example.com/skolem-stub/anon#1", "http://www.w3.org/1999/02/22-rdf-syntax-ns#rest",
+				"http://example.com/skolem-stub/anon#2", "", "", ""},
+			{"http://example.com/skolem-stub/anon#3", "http://www.w3.org/1999/02/22-rdf-syntax-ns#first",
+				"http://example.org/x", "", "", ""},
+			{"http://example.com/skolem-stub/anon#3", "http://www.w3.org/1999/02/22-rdf-syntax-ns#rest",
+				"http://www.w3.org/1999/02/22-rdf-syntax-ns#nil", "", "", ""},
+			{"http://example.com/skolem-stub/anon#2", "http://www.w3.org/1999/02/22-rdf-syntax-ns#first",
+				"http://example.com/skolem-stub/anon#3", "", "", ""},
+			{"http://example.com/skolem-stub/anon#2", "http://www.w3.org/1999/02/22-rdf-syntax-ns#rest",
+				"http://www.w3.org/1999/02/22-rdf-syntax-ns#nil", "", "", ""},
+			{"http://example.com/skolem-stub/anon#1", "http://example.org/p",
+				"http://www.w3.org/1999/02/22-rdf-syntax-ns#nil", "", "", ""},
+			{"http://example.com/skolem-stub/anon#4", "http://www.w3.org/1999/02/22-rdf-syntax-ns#first",
+				"http://www.w3.org/1999/02/22-rdf-syntax-ns#nil", "", "", ""},
+			{"http://example.com/skolem-stub/anon#4", "http://www.w3.org/1999/02/22-rdf-syntax-ns#rest",
+				"http://www.w3.org/1999/02/22-rdf-syntax-ns#nil", "", "", ""},
+			{"http://example.com/skolem-stub/anon#1", "http://example.org/p",
+				"http://example.com/skolem-stub/anon#4", "", "", ""},
+		},
+	},
+
+	// blank node EXAMPLE 15 from W3C's “RDF 1.1 Turtle” Recommendation
+	{`@prefix foaf: <http://xmlns.com/foaf/0.1/> .
+
+# Someone knows someone else, who has the name "Bob".
+[] foaf:knows [ foaf:name "Bob" ] .`,
+		[]Triple{
+			{"http://example.com/skolem-stub/anon#2", "http://xmlns.com/foaf/0.1/name",
+				"Bob", "http://www.w3.org/2001/XMLSchema#string", "", ""},
+			{"http://example.com/skolem-stub/anon#1", "http://xmlns.com/foaf/0.1/knows",
+				"http://example.com/skolem-stub/anon#2", "", "", ""},
+		},
+	},
+
+	// nested property lists EXAMPLE 16 from W3C's “RDF 1.1 Turtle” Recommendation
+	{`@base <http://example.com/> .
+@prefix foaf: <http://xmlns.com/foaf/0.1/> .
+
+[ foaf:name "Alice" ] foaf:knows [
+    foaf:name "Bob" ;
+    foaf:knows [
+        foaf:name "Eve" ] ;
+    foaf:mbox <bob@example.com> ] .
+[ foaf:name "Carol" ; ] . # standalone with trailing semicolon`,
+		[]Triple{
+			{"http://example.com/skolem-stub/anon#1", "http://xmlns.com/foaf/0.1/name",
+				"Alice", "http://www.w3.org/2001/XMLSchema#string", "", ""},
+			{"http://example.com/skolem-stub/anon#2", "http://xmlns.com/foaf/0.1/name",
+				"Bob", "http://www.w3.org/2001/XMLSchema#string", "", ""},
+			{"http://example.com/skolem-stub/anon#3", "http://xmlns.com/foaf/0.1/name",
+				"Eve", "http://www.w3.org/2001/XMLSchema#string", "", ""},
+			{"http://example.com/skolem-stub/anon#2", "http://xmlns.com/foaf/0.1/knows",
+				"http://example.com/skolem-stub/anon#3", "", "", ""},
+			{"http://example.com/skolem-stub/anon#2", "http://xmlns.com/foaf/0.1/mbox",
+				"http://example.com/bob@example.com", "", "", ""},
+			{"http://example.com/skolem-stub/anon#1", "http://xmlns.com/foaf/0.1/knows",
+				"http://example.com/skolem-stub/anon#2", "", "", ""},
+			{"http://example.com/skolem-stub/anon#4", "http://xmlns.com/foaf/0.1/name",
+				"Carol", "http://www.w3.org/2001/XMLSchema#string", "", ""},
+		},
+	},
+
+	// Unicode escapes in IRI references
+	{`<http://example.com/caf\u00E9> <http://example.com/\U0001F600> <http://example.com/\u003F#x> .`,
+		[]Triple{
+			{"http://example.com/café", "http://example.com/😀", "http://example.com/?#x", "", "", ""},
+		},
+	},
+
+	// prefixed names and numbers terminated by punctuation
+	{`@prefix ex: <http://example.com/> .
+@prefix e.x: <http://example.org/dotted/> .
+ex:a ex:b ex:c,ex:d;ex:e ex:with\~tilde\.,ex:%41, ex:a.b.c, e.x:y:z, ex:.
+ex:f ex:g 10,-1.5,1.E3, .5e-2, true, false;ex:h "x"@EN-us,"1"^^ex:int.
+_:b.1 ex:i (_:b2 ex:a).`,
+		[]Triple{
+			{"http://example.com/a", "http://example.com/b", "http://example.com/c", "", "", ""},
+			{"http://example.com/a", "http://example.com/b", "http://example.com/d", "", "", ""},
+			{"http://example.com/a", "http://example.com/e", "http://example.com/with~tilde.", "", "", ""},
+			{"http://example.com/a", "http://example.com/e", "http://example.com/%41", "", "", ""},
+			{"http://example.com/a", "http://example.com/e", "http://example.com/a.b.c", "", "", ""},
+			{"http://example.com/a", "http://example.com/e", "http://example.org/dotted/y:z", "", "", ""},
+			{"http://example.com/a", "http://example.com/e", "http://example.com/", "", "", ""},
+			{"http://example.com/f", "http://example.com/g", "10", "http://www.w3.org/2001/XMLSchema#integer", "", ""},
+			{"http://example.com/f", "http://example.com/g", "-1.5", "http://www.w3.org/2001/XMLSchema#decimal", "", ""},
+			{"http://example.com/f", "http://example.com/g", "1.E3", "http://www.w3.org/2001/XMLSchema#double", "", ""},
+			{"http://example.com/f", "http://example.com/g", ".5e-2", "http://www.w3.org/2001/XMLSchema#double", "", ""},
+			{"http://example.com/f", "http://example.com/g", "true", "http://www.w3.org/2001/XMLSchema#boolean", "", ""},
+			{"http://example.com/f", "http://example.com/g", "false", "http://www.w3.org/2001/XMLSchema#boolean", "", ""},
+			{"http://example.com/f", "http://example.com/h", "x", "http://www.w3.org/1999/02/22-rdf-syntax-ns#langString", "en-us", ""},
+			{"http://example.com/f", "http://example.com/h", "1", "http://example.com/int", "", ""},
+			{"http://example.com/skolem-stub/anon#1", "http://www.w3.org/1999/02/22-rdf-syntax-ns#first",
+				"http://example.com/skolem-stub/blank#b2", "", "", ""},
+			{"http://example.com/skolem-stub/anon#1", "http://www.w3.org/1999/02/22-rdf-syntax-ns#rest",
+				"http://example.com/skolem-stub/anon#2", "", "", ""},
+			{"http://example.com/skolem-stub/anon#2", "http://www.w3.org/1999/02/22-rdf-syntax-ns#first",
+				"http://example.com/a", "", "", ""},
+			{"http://example.com/skolem-stub/anon#2", "http://www.w3.org/1999/02/22-rdf-syntax-ns#rest",
+				"http://www.w3.org/1999/02/22-rdf-syntax-ns#nil", "", "", ""},
+			{"http://example.com/skolem-stub/blank#b.1", "http://example.com/i",
+				"http://example.com/skolem-stub/anon#1", "", "", ""},
+		},
+	},
+
+	// directional language-tagged strings from RDF 1.2
+	{`@prefix : <http://example.com/> .
+:s :p "مرحبا"@AR--rtl, 'hi'@en-US--ltr ; :q "plain"@en .`,
+		[]Triple{
+			{"http://example.com/s", "http://example.com/p", "مرحبا", "http://www.w3.org/1999/02/22-rdf-syntax-ns#dirLangString", "ar", "rtl"},
+			{"http://example.com/s", "http://example.com/p", "hi", "http://www.w3.org/1999/02/22-rdf-syntax-ns#dirLangString", "en-us", "ltr"},
+			{"http://example.com/s", "http://example.com/q", "plain", "http://www.w3.org/1999/02/22-rdf-syntax-ns#langString", "en", ""},
+		},
+	},
+
+	// quoted triples and annotations from RDF-star
+	{`@prefix : <http://example.com/> .
+<< :s :p "o"@EN >> :certainty 0.9 .
+:a :b << << _:x :p [] >> :q :o >> .
+:s :p :o {| :source :feed ; :at [ :n 1 ] |} , :o2 .`,
+		[]Triple{
+			{`<< <http://example.com/s> <http://example.com/p> "o"@en >>`, "http://example.com/certainty",
+				"0.9", "http://www.w3.org/2001/XMLSchema#decimal", "", ""},
+			{"http://example.com/a", "http://example.com/b",
+				"<< << <http://example.com/skolem-stub/blank#x> <http://example.com/p> <http://example.com/skolem-stub/anon#1> >> <http://example.com/q> <http://example.com/o> >>", "", "", ""},
+			{"http://example.com/s", "http://example.com/p", "http://example.com/o", "", "", ""},
+			{"<< <http://example.com/s> <http://example.com/p> <http://example.com/o> >>", "http://example.com/source",
+				"http://example.com/feed", "", "", ""},
+			{"http://example.com/skolem-stub/anon#2", "http://example.com/n",
+				"1", "http://www.w3.org/2001/XMLSchema#integer", "", ""},
+			{"<< <http://example.com/s> <http://example.com/p> <http://example.com/o> >>", "http://example.com/at",
+				"http://example.com/skolem-stub/anon#2", "", "", ""},
+			{"http://example.com/s", "http://example.com/p", "http://example.com/o2", "", "", ""},
 		},
 	},
 }
 
+// SkolemStub has a fixed namespace for predictable Skolem IRIs.
+var skolemStub = skolemizer{skolemIRICache: "http://example.com/skolem-stub/"}
+
 func TestReader(t *testing.T) {
 	for _, test := range turtleTriples {
 		// sample stream
 		r := Reader{
-			R:              bufio.NewReader(strings.NewReader(test.turtle)),
-			skolemIRICache: "http://example.com/skolem-stub/",
+			R:          bufio.NewReader(strings.NewReader(test.turtle)),
+			skolemizer: skolemStub,
 		}
 
 		var got []Triple
@@ -277,3 +444,235 @@ func TestReader(t *testing.T) {
 		t.Error(msg, "\nfor Turtle:\n", test.turtle)
 	}
 }
+
+var turtleSyntaxErrors = []struct {
+	turtle string
+	reason string
+	kind   error
+	column int
+}{
+	{`<http://example.com/a\u0020b> <http://example.com/p> <http://example.com/o> .`,
+		"Unicode escape of control character in IRI reference", ErrIllegalChar, 22},
+	{`<http://example.com/\u003E> <http://example.com/p> <http://example.com/o> .`,
+		"Unicode escape of illegal character in IRI reference", ErrIllegalChar, 21},
+	{`<http://example.com/\u00G0> <http://example.com/p> <http://example.com/o> .`,
+		"illegal hex in Unicode escape", ErrIllegalEscape, 25},
+	{`<http://example.com/\n> <http://example.com/p> <http://example.com/o> .`,
+		"illegal escape in IRI reference; only UCHAR permitted", ErrIllegalEscape, 21},
+
+	{`@prefix ex: <http://example.com/> . ex:a ex:b ex:c\d .`,
+		"illegal escape in local name", ErrIllegalEscape, 51},
+	{`@prefix ex: <http://example.com/> . ex:a ex:b ex:%4G .`,
+		"illegal percent-encoding in local name", ErrIllegalEscape, 50},
+	{`@prefix ex: <http://example.com/> . ex:a ex:b no:c .`,
+		`undefined prefix "no"`, ErrUndefinedPrefix, 47},
+	{`@prefix ex: <http://example.com/> . ex:a ex:b 12ab .`,
+		"illegal character in number", ErrIllegalChar, 49},
+	{`@prefix ex: <http://example.com/> . _:-a ex:b ex:c .`,
+		"illegal first character in blank node label", ErrIllegalChar, 39},
+	{`@prefix e.: <http://example.com/> .`,
+		`prefix label without ":" suffix`, ErrUnexpectedToken, 10},
+	{`<a> <b> <c> .`,
+		"relative reference without base IRI", ErrNoBaseIRI, 1},
+
+	{`@prefix : <http://example.com/> . :s :p "x"@en--up .`,
+		`base direction is neither "ltr" nor "rtl"`, ErrUnexpectedToken, 47},
+	{`@prefix : <http://example.com/> . :s :p "x"@en--RTL .`,
+		`base direction is neither "ltr" nor "rtl"`, ErrUnexpectedToken, 47},
+	{`@prefix : <http://example.com/> . :s :p "x"@-- .`,
+		"empty code in language tag", ErrUnexpectedToken, 45},
+
+	{`@prefix : <http://example.com/> . << :s :p [ :q :o ] >> :p :o .`,
+		"blank node property list in quoted triple", ErrUnexpectedToken, 44},
+	{`@prefix : <http://example.com/> . :s :p << :s :p ( ) >> .`,
+		"collection in quoted triple", ErrUnexpectedToken, 50},
+	{`@prefix : <http://example.com/> . << :s :p :o :x >> :p :o .`,
+		`quoted triple not closed with ">>"`, ErrUnexpectedToken, 47},
+	{`@prefix : <http://example.com/> . << "s" :p :o >> :p :o .`,
+		"illegal subject token in quoted triple", ErrUnexpectedToken, 38},
+	{`@prefix : <http://example.com/> . :s :p :o {| :p :o | .`,
+		`annotation not closed with "|}"`, ErrUnexpectedToken, 53},
+}
+
+func TestReaderSyntaxErrors(t *testing.T) {
+	for _, test := range turtleSyntaxErrors {
+		r := Reader{R: bufio.NewReader(strings.NewReader(test.turtle))}
+
+		var err error
+		for err == nil {
+			_, err = r.ReadAppend(nil)
+		}
+		var e *SyntaxError
+		if !errors.As(err, &e) {
+			t.Errorf("got error %v, want a *SyntaxError, for Turtle:\n%s", err, test.turtle)
+			continue
+		}
+		if e.Reason != test.reason {
+			t.Errorf("got reason %q, want %q, for Turtle:\n%s", e.Reason, test.reason, test.turtle)
+		}
+		if !errors.Is(err, test.kind) {
+			t.Errorf("got error %v, want kind %q, for Turtle:\n%s", err, test.kind, test.turtle)
+		}
+		if e.Column != test.column {
+			t.Errorf("got column %d, want %d, for Turtle:\n%s", e.Column, test.column, test.turtle)
+		}
+	}
+}
+
+func TestSyntaxErrorPosition(t *testing.T) {
+	const turtle = "@prefix ex: <http://example.com/> .\n  ex:a ex:b \"x\ny\" ."
+	for _, bufSize := range []int{16, 4096} {
+		r := Reader{R: bufio.NewReaderSize(strings.NewReader(turtle), bufSize)}
+		_, err := r.ReadAppend(nil)
+		var e *SyntaxError
+		if !errors.As(err, &e) {
+			t.Fatalf("got error %v, want a *SyntaxError", err)
+		}
+		if !errors.Is(err, ErrUnterminatedLiteral) {
+			t.Errorf("got error %v, want ErrUnterminatedLiteral", err)
+		}
+		if e.LineNo != 2 || e.Column != 15 || e.Offset != 50 {
+			t.Errorf("buffer size %d: got line № %d, column %d, offset %d; want line № 2, column 15, offset 50",
+				bufSize, e.LineNo, e.Column, e.Offset)
+		}
+		if bufSize > len(turtle) {
+			const want = "  ex:a ex:b \"x\n              ^"
+			if e.Excerpt != want {
+				t.Errorf("got excerpt:\n%s\nwant:\n%s", e.Excerpt, want)
+			}
+		}
+	}
+}
+
+// Chunks smaller than the lines must not affect the outcome.
+func TestReaderMinimumBufferSize(t *testing.T) {
+	for _, test := range turtleTriples {
+		r := Reader{
+			R:          bufio.NewReaderSize(strings.NewReader(test.turtle), 16),
+			skolemizer: skolemStub,
+		}
+
+		got := []Triple{}
+		var err error
+		for err == nil {
+			got, err = r.ReadAppend(got)
+		}
+		if err != io.EOF {
+			t.Errorf("got error %v, for Turtle:\n%s", err, test.turtle)
+			continue
+		}
+		if !slices.Equal(got, test.triples) {
+			t.Errorf("got triples %q, want %q, for Turtle:\n%s", got, test.triples, test.turtle)
+		}
+	}
+}
+
+func TestReaderLongLine(t *testing.T) {
+	long := strings.Repeat("0123456789 ", 100_000)
+	turtle := `<http://example.com/s> <http://example.com/p> "` + long + `" , "` + long + `"@en . # ` + long
+
+	r := Reader{R: bufio.NewReader(strings.NewReader(turtle))}
+	got, err := r.ReadAppend(nil)
+	if err != nil {
+		t.Fatal("read error:", err)
+	}
+	if len(got) != 2 || got[0].Object != long || got[1].Object != long || got[1].LangTag != "en" {
+		t.Errorf("got %d triples, want 2 with the long literal", len(got))
+	}
+	if _, err := r.ReadAppend(nil); err != io.EOF {
+		t.Errorf("got error %v after last statement, want io.EOF", err)
+	}
+
+	r = Reader{
+		R:            bufio.NewReader(strings.NewReader(turtle)),
+		MaxTokenSize: 1000,
+	}
+	_, err = r.ReadAppend(nil)
+	var e *SyntaxError
+	if !errors.As(err, &e) || e.Reason != "quoted literal exceeds maximum token size" {
+		t.Errorf("got error %v, want maximum token size exceeded", err)
+	}
+}
+
+func TestReaderLongIRI(t *testing.T) {
+	IRI := "http://example.com/" + strings.Repeat("x", 100_000)
+	turtle := "<" + IRI + "> <http://example.com/p> <" + IRI + "> ."
+
+	r := Reader{R: bufio.NewReaderSize(strings.NewReader(turtle), 16)}
+	got, err := r.ReadAppend(nil)
+	if err != nil {
+		t.Fatal("read error:", err)
+	}
+	if len(got) != 1 || got[0].SubjectIRI != IRI || got[0].Object != IRI {
+		t.Errorf("got %d triples, want 1 with the long IRIs", len(got))
+	}
+
+	r = Reader{
+		R:            bufio.NewReaderSize(strings.NewReader(turtle), 16),
+		MaxTokenSize: 1000,
+	}
+	_, err = r.ReadAppend(nil)
+	var e *SyntaxError
+	if !errors.As(err, &e) || e.Reason != "token exceeds maximum size" {
+		t.Errorf("got error %v, want maximum token size exceeded", err)
+	}
+}
+
+// Numbers with multiple digits used to loop forever.
+func TestReaderMultiDigitNumbers(t *testing.T) {
+	const turtle = `<http://example.com/s> <http://example.com/p> 123, -45, +678, 90.12 .`
+	r := Reader{R: bufio.NewReader(strings.NewReader(turtle))}
+	got, err := r.ReadAppend(nil)
+	if err != nil {
+		t.Fatal("read error:", err)
+	}
+	var gotObjects []string
+	for _, t := range got {
+		gotObjects = append(gotObjects, t.Object)
+	}
+	wantObjects := []string{"123", "-45", "+678", "90.12"}
+	if !slices.Equal(gotObjects, wantObjects) {
+		t.Errorf("got objects %q, want %q", gotObjects, wantObjects)
+	}
+}
+
+func TestReaderRecover(t *testing.T) {
+	const turtle = `@prefix ex: <http://example.com/> .
+ex:a ex:b ex:c .
+ex:a ex:b no:c ; ex:d "x . y", """long .
+literal""" , <http://example.com/.> . ex:e ex:f ex:g .
+ex:h ex:i [ ex:j 12ab ] . # broken nested
+ex:k ex:l ( ex:m "a\q . b" ) . ex:n ex:o ex:p .
+ex:a ex:b no:x .ex:q ex:r ex:s .
+`
+	r := Reader{
+		R:       bufio.NewReaderSize(strings.NewReader(turtle), 16),
+		Recover: true,
+	}
+	var got []Triple
+	var err error
+	for err == nil {
+		got, err = r.ReadAppend(got)
+	}
+	if err != io.EOF {
+		t.Fatalf("got error %v, want io.EOF", err)
+	}
+
+	var gotObjects []string
+	for _, t := range got {
+		gotObjects = append(gotObjects, t.Object)
+	}
+	wantObjects := []string{"http://example.com/c", "http://example.com/g", "http://example.com/p", "http://example.com/s"}
+	if !slices.Equal(gotObjects, wantObjects) {
+		t.Errorf("got objects %q, want %q", gotObjects, wantObjects)
+	}
+
+	if len(r.SyntaxErrs) != 4 {
+		t.Fatalf("got %d syntax errors, want 4: %v", len(r.SyntaxErrs), r.SyntaxErrs)
+	}
+	for i, kind := range []error{ErrUndefinedPrefix, ErrIllegalChar, ErrIllegalEscape, ErrUndefinedPrefix} {
+		if !errors.Is(r.SyntaxErrs[i], kind) {
+			t.Errorf("syntax error № %d got %v, want kind %q", i+1, r.SyntaxErrs[i], kind)
+		}
+	}
+}
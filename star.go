@@ -0,0 +1,367 @@
+package tripn
+
+// RDFVersion is a revision of the RDF grammars.
+type RDFVersion uint8
+
+// Reader supports the following revisions.
+const (
+	// RDFStar is RDF 1.1 with the quoted triples and annotations of
+	// RDF-star, i.e., "<< s p o >>" and "{| … |}". Base directions from
+	// RDF 1.2 are accepted too.
+	RDFStar RDFVersion = iota
+
+	// RDF11 is RDF 1.1 without any extensions.
+	RDF11
+
+	// RDF12 is RDF 1.2, with triple terms "<<( s p o )>>", reified
+	// triples "<< s p o ~ r >>", reifiers on annotations, and the version
+	// directive.
+	RDF12
+)
+
+// RDF vocabulary for reification in RDF 1.2.
+const rdfReifies = "http://www.w3.org/1999/02/22-rdf-syntax-ns#reifies"
+
+// InTripleNode continues from "<<" in the buffer. RDF-star gets a quoted
+// triple. RDF 1.2 gets either a triple term, or the reifier of a reified
+// triple, with its rdf:reifies statement appended to dstp.
+func (r *Reader) inTripleNode(line []byte, dstp *[]Triple) (IRI string, remainder []byte, err error) {
+	switch r.Version {
+	case RDF11:
+		return "", nil, r.syntaxErr(ErrUnexpectedToken, line, "quoted triple not permitted in RDF 1.1")
+	case RDF12:
+		if len(line) > 2 && line[2] == '(' {
+			return r.inTripleTerm(line)
+		}
+		return r.inReifiedTriple(line, dstp)
+	default:
+		return r.inQuotedTriple(line)
+	}
+}
+
+// InSubjectTriple is like inTripleNode for the subject position. Reified
+// triples may stand alone, i.e., without a predicate–object list, in which case
+// the IRI is zero.
+func (r *Reader) inSubjectTriple(line []byte, dstp *[]Triple) (IRI string, remainder []byte, err error) {
+	if r.Version == RDF12 && len(line) > 2 && line[2] == '(' {
+		return "", nil, r.syntaxErr(ErrUnexpectedToken, line, "triple term as subject")
+	}
+	IRI, line, err = r.inTripleNode(line, dstp)
+	if err != nil || r.Version != RDF12 {
+		return IRI, line, err
+	}
+
+	// predicate–object list is optional after a reified triple
+	line, err = r.lineContinue(line)
+	if err != nil {
+		return "", nil, err
+	}
+	switch {
+	case line[0] == '.':
+		return "", line[1:], nil
+	case line[0] == '}' && (r.inGraph || r.formulaLevel != 0):
+		return "", line, nil
+	}
+	return IRI, line, nil
+}
+
+// InQuotedTriple continues from "<<" in the buffer. The quoted triple is not
+// asserted, i.e., nothing gets appended to any of the statements.
+func (r *Reader) inQuotedTriple(line []byte) (quoted string, remainder []byte, err error) {
+	var t Triple
+	line, err = r.inTripleParts(line[2:], &t, nil, false)
+	if err != nil {
+		return "", nil, err
+	}
+
+	line, err = r.lineContinue(line)
+	if err != nil {
+		return "", nil, err
+	}
+	if line[0] != '>' || len(line) < 2 || line[1] != '>' {
+		return "", nil, r.syntaxErr(ErrUnexpectedToken, line, `quoted triple not closed with ">>"`)
+	}
+	return t.Quoted(), line[2:], nil
+}
+
+// InTripleTerm continues from "<<(" in the buffer. The triple term is not
+// asserted, i.e., nothing gets appended to any of the statements.
+func (r *Reader) inTripleTerm(line []byte) (term string, remainder []byte, err error) {
+	var t Triple
+	line, err = r.inTripleParts(line[3:], &t, nil, true)
+	if err != nil {
+		return "", nil, err
+	}
+
+	line, err = r.lineContinue(line)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(line) < 3 || line[0] != ')' || line[1] != '>' || line[2] != '>' {
+		return "", nil, r.syntaxErr(ErrUnexpectedToken, line, `triple term not closed with ")>>"`)
+	}
+	return t.TripleTerm(), line[3:], nil
+}
+
+// InReifiedTriple continues from "<<" in the buffer. The reifier defaults to a
+// new anonymous node. Its rdf:reifies statement gets appended to dstp.
+func (r *Reader) inReifiedTriple(line []byte, dstp *[]Triple) (reifier string, remainder []byte, err error) {
+	var t Triple
+	line, err = r.inTripleParts(line[2:], &t, dstp, false)
+	if err != nil {
+		return "", nil, err
+	}
+
+	line, err = r.lineContinue(line)
+	if err != nil {
+		return "", nil, err
+	}
+	if line[0] == '~' {
+		reifier, line, err = r.inReifier(line)
+		if err != nil {
+			return "", nil, err
+		}
+		line, err = r.lineContinue(line)
+		if err != nil {
+			return "", nil, err
+		}
+	} else {
+		reifier = r.newAnonIRI()
+	}
+	if line[0] != '>' || len(line) < 2 || line[1] != '>' {
+		return "", nil, r.syntaxErr(ErrUnexpectedToken, line, `quoted triple not closed with ">>"`)
+	}
+
+	err = r.appendTriple(dstp, Triple{
+		SubjectIRI:   reifier,
+		PredicateIRI: rdfReifies,
+		Object:       t.TripleTerm(),
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return reifier, line[2:], nil
+}
+
+// InTripleParts reads the subject, the predicate and the object of a quoted
+// triple, a triple term (when term is true), or a reified triple into t. Blank
+// nodes may not have a predicate–object list, and collections are not
+// permitted either.
+func (r *Reader) inTripleParts(line []byte, t *Triple, dstp *[]Triple, term bool) (remainder []byte, err error) {
+	line, err = r.lineContinue(line)
+	if err != nil {
+		return nil, err
+	}
+	switch line[0] {
+	case '<':
+		if len(line) < 2 || line[1] != '<' {
+			t.SubjectIRI, line, err = r.inIRI(line)
+			break
+		}
+		if term || r.Version == RDF12 && len(line) > 2 && line[2] == '(' {
+			return nil, r.syntaxErr(ErrUnexpectedToken, line, "illegal subject token in quoted triple")
+		}
+		t.SubjectIRI, line, err = r.inTripleNode(line, dstp)
+	case '_':
+		t.SubjectIRI, line, err = r.inBlankLabel(line)
+	case '[':
+		t.SubjectIRI, line, err = r.inQuotedAnonymous(line)
+	default:
+		t.SubjectIRI, _, line, err = r.inPrefixedName(line)
+		if err == nil && t.SubjectIRI == "" {
+			err = r.syntaxErr(ErrUnexpectedToken, line, "illegal subject token in quoted triple")
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	t.PredicateIRI, line, err = r.readPredicate(line)
+	if err != nil {
+		return nil, err
+	}
+
+	line, err = r.lineContinue(line)
+	if err != nil {
+		return nil, err
+	}
+	switch line[0] {
+	case '[':
+		t.Object, line, err = r.inQuotedAnonymous(line)
+	case '(':
+		err = r.syntaxErr(ErrUnexpectedToken, line, "collection in quoted triple")
+	case '<':
+		if term && len(line) > 2 && line[1] == '<' && line[2] != '(' {
+			err = r.syntaxErr(ErrUnexpectedToken, line, "reified triple in triple term")
+			break
+		}
+		line, err = r.readObject(line, t, dstp)
+	default:
+		line, err = r.readObject(line, t, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return line, nil
+}
+
+// InQuotedAnonymous continues from "[" in the buffer. Quoted triples permit
+// anonymous nodes without a predicate–object list only.
+func (r *Reader) inQuotedAnonymous(line []byte) (skolemIRI string, remainder []byte, err error) {
+	remainder, err = r.lineContinue(line[1:])
+	if err != nil {
+		return "", nil, err
+	}
+	if remainder[0] != ']' {
+		return "", nil, r.syntaxErr(ErrUnexpectedToken, line, "blank node property list in quoted triple")
+	}
+	return r.newAnonIRI(), remainder[1:], nil
+}
+
+// InReifier continues from "~" in the buffer. The IRI or blank node is
+// optional, with a new anonymous node as the default.
+func (r *Reader) inReifier(line []byte) (IRI string, remainder []byte, err error) {
+	line, err = r.lineContinue(line[1:])
+	if err != nil {
+		return "", nil, err
+	}
+	switch line[0] {
+	case '<':
+		if len(line) < 2 || line[1] != '<' {
+			return r.inIRI(line)
+		}
+	case '_':
+		return r.inBlankLabel(line)
+	case '[':
+		return r.inQuotedAnonymous(line)
+	default:
+		IRI, keyword, remainder, err := r.inPrefixedName(line)
+		switch {
+		case err != nil:
+			return "", nil, err
+		case IRI != "":
+			return IRI, remainder, nil
+		case len(keyword) != 0:
+			return "", nil, r.syntaxErr(ErrUnexpectedToken, line, "illegal reifier token")
+		}
+	}
+	return r.newAnonIRI(), line, nil
+}
+
+// IsAnnotationStart returns whether line starts with an annotation.
+func (r *Reader) isAnnotationStart(line []byte) bool {
+	switch {
+	case r.Version == RDF11:
+		return false
+	case line[0] == '~':
+		return r.Version == RDF12
+	}
+	return line[0] == '{' && len(line) > 1 && line[1] == '|'
+}
+
+// ReadAnnotation continues from the start of an annotation on t in the buffer.
+// RDF-star has one annotation block with t quoted as the subject. RDF 1.2 has
+// any number of reifiers and annotation blocks, with the reifier in effect as
+// the subject. The remainder starts at the first non-whitespace after the
+// annotation.
+func (r *Reader) readAnnotation(t Triple, line []byte, dstp *[]Triple) (remainder []byte, err error) {
+	if r.Version != RDF12 {
+		line, err = r.inAnnotation(t.Quoted(), line, dstp)
+		if err != nil {
+			return nil, err
+		}
+		return r.lineContinue(line)
+	}
+
+	var reifier string // pending use
+	for r.isAnnotationStart(line) {
+		if line[0] == '~' {
+			reifier, line, err = r.inReifier(line)
+			if err != nil {
+				return nil, err
+			}
+			err = r.appendTriple(dstp, Triple{
+				SubjectIRI:   reifier,
+				PredicateIRI: rdfReifies,
+				Object:       t.TripleTerm(),
+			})
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			if reifier == "" {
+				reifier = r.newAnonIRI()
+				err = r.appendTriple(dstp, Triple{
+					SubjectIRI:   reifier,
+					PredicateIRI: rdfReifies,
+					Object:       t.TripleTerm(),
+				})
+				if err != nil {
+					return nil, err
+				}
+			}
+			line, err = r.inAnnotation(reifier, line, dstp)
+			if err != nil {
+				return nil, err
+			}
+			reifier = "" // one annotation block per reifier
+		}
+
+		line, err = r.lineContinue(line)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return line, nil
+}
+
+// InAnnotation continues from "{|" in the buffer. The predicate–object list
+// within gets appended to dstp with subject.
+func (r *Reader) inAnnotation(subject string, line []byte, dstp *[]Triple) (remainder []byte, err error) {
+	// blank node property lists start over within
+	propListLevel := r.propListLevel
+	r.propListLevel = 0
+	r.annotationLevel++
+
+	line, err = r.readPredicateObjectList(subject, line[2:], dstp)
+	if err != nil {
+		return nil, err
+	}
+
+	r.annotationLevel--
+	r.propListLevel = propListLevel
+	return line, nil
+}
+
+// AfterVersionDirective continues with line after a "@version" or "VERSION"
+// encounter.
+func (r *Reader) afterVersionDirective(line []byte, terminated bool) (remainder []byte, err error) {
+	line, err = r.lineContinue(line)
+	if err != nil {
+		return nil, err
+	}
+	if line[0] != '"' && line[0] != '\'' || len(line) > 2 && line[1] == line[0] && line[2] == line[0] {
+		return nil, r.syntaxErr(ErrUnexpectedToken, line, "version specifier is not a single-line quoted string")
+	}
+	var t Triple
+	at := line
+	line, err = r.inQuote(line, &t)
+	if err != nil {
+		return nil, err
+	}
+	if t.DatatypeIRI != XSDString {
+		return nil, r.syntaxErr(ErrUnexpectedToken, at, "version specifier with language tag or datatype")
+	}
+
+	if terminated {
+		line, err = r.lineContinue(line)
+		if err != nil {
+			return nil, err
+		}
+		if line[0] != '.' {
+			return nil, r.syntaxErr(ErrUnexpectedToken, line, `version directive not terminated with "."`)
+		}
+		line = line[1:]
+	}
+	return line, nil
+}
@@ -0,0 +1,147 @@
+package tripn
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var rdf12Triples = []struct {
+	turtle  string
+	triples []Triple
+}{
+	{`VERSION "1.2"
+PREFIX : <http://example.com/>
+@version '1.2-basic' .
+:s :p <<( :a :b "c" )>> .
+:s :p <<( _:x :b <<( :a :b [] )>> )>> .`,
+		[]Triple{
+			{"http://example.com/s", "http://example.com/p",
+				`<<( <http://example.com/a> <http://example.com/b> "c" )>>`, "", "", ""},
+			{"http://example.com/s", "http://example.com/p",
+				"<<( <http://example.com/skolem-stub/blank#x> <http://example.com/b> <<( <http://example.com/a> <http://example.com/b> <http://example.com/skolem-stub/anon#1> )>> )>>", "", "", ""},
+		},
+	},
+
+	// reified triples
+	{`PREFIX : <http://example.com/>
+<< :a :b :c ~ :r >> :says :x .
+<< :a :b :c >> .
+:s :p << << :a :b :c ~ >> :d :e >> .`,
+		[]Triple{
+			{"http://example.com/r", rdfReifies,
+				"<<( <http://example.com/a> <http://example.com/b> <http://example.com/c> )>>", "", "", ""},
+			{"http://example.com/r", "http://example.com/says", "http://example.com/x", "", "", ""},
+			{"http://example.com/skolem-stub/anon#1", rdfReifies,
+				"<<( <http://example.com/a> <http://example.com/b> <http://example.com/c> )>>", "", "", ""},
+			{"http://example.com/skolem-stub/anon#2", rdfReifies,
+				"<<( <http://example.com/a> <http://example.com/b> <http://example.com/c> )>>", "", "", ""},
+			{"http://example.com/skolem-stub/anon#3", rdfReifies,
+				"<<( <http://example.com/skolem-stub/anon#2> <http://example.com/d> <http://example.com/e> )>>", "", "", ""},
+			{"http://example.com/s", "http://example.com/p", "http://example.com/skolem-stub/anon#3", "", "", ""},
+		},
+	},
+
+	// reifiers and annotation blocks
+	{`PREFIX : <http://example.com/>
+:s :p :o ~ :r1 {| :q 1 |} {| :q 2 |} ~ _:r3 , :o2 .`,
+		[]Triple{
+			{"http://example.com/s", "http://example.com/p", "http://example.com/o", "", "", ""},
+			{"http://example.com/r1", rdfReifies,
+				"<<( <http://example.com/s> <http://example.com/p> <http://example.com/o> )>>", "", "", ""},
+			{"http://example.com/r1", "http://example.com/q", "1", XSDInteger, "", ""},
+			{"http://example.com/skolem-stub/anon#1", rdfReifies,
+				"<<( <http://example.com/s> <http://example.com/p> <http://example.com/o> )>>", "", "", ""},
+			{"http://example.com/skolem-stub/anon#1", "http://example.com/q", "2", XSDInteger, "", ""},
+			{"http://example.com/skolem-stub/blank#r3", rdfReifies,
+				"<<( <http://example.com/s> <http://example.com/p> <http://example.com/o> )>>", "", "", ""},
+			{"http://example.com/s", "http://example.com/p", "http://example.com/o2", "", "", ""},
+		},
+	},
+}
+
+func TestReaderRDF12(t *testing.T) {
+	for _, test := range rdf12Triples {
+		r := Reader{
+			R:          bufio.NewReader(strings.NewReader(test.turtle)),
+			Version:    RDF12,
+			skolemizer: skolemStub,
+		}
+
+		got := []Triple{}
+		var err error
+		for err == nil {
+			got, err = r.ReadAppend(got)
+		}
+		if err != io.EOF {
+			t.Errorf("got error %v, for Turtle:\n%s", err, test.turtle)
+			continue
+		}
+		if !slices.Equal(got, test.triples) {
+			msg := "got triples:"
+			for _, t := range got {
+				msg += "\n\t" + t.String()
+			}
+			msg += "\nwant triples:"
+			for _, t := range test.triples {
+				msg += "\n\t" + t.String()
+			}
+			t.Error(msg, "\nfor Turtle:\n", test.turtle)
+		}
+	}
+}
+
+var versionSyntaxErrors = []struct {
+	version RDFVersion
+	turtle  string
+	reason  string
+	column  int
+}{
+	{RDF12, `PREFIX : <http://example.com/> <<( :a :b :c )>> :p :o .`,
+		"triple term as subject", 32},
+	{RDF12, `PREFIX : <http://example.com/> :s :p <<( :a :b << :c :d :e >> )>> .`,
+		"reified triple in triple term", 48},
+	{RDF12, `PREFIX : <http://example.com/> :s :p <<( :a :b :c >> .`,
+		`triple term not closed with ")>>"`, 51},
+	{RDF12, `PREFIX : <http://example.com/> :s :p :o ~ a .`,
+		"illegal reifier token", 43},
+	{RDF12, `VERSION "1.2"@en`,
+		"version specifier with language tag or datatype", 9},
+	{RDF12, `@version """1.2""" .`,
+		"version specifier is not a single-line quoted string", 10},
+	{RDFStar, `VERSION "1.2"`,
+		"illegal subject token", 1},
+	{RDFStar, `PREFIX : <http://example.com/> :s :p :o ~ :r .`,
+		"illegal triple continuation", 41},
+	{RDF11, `PREFIX : <http://example.com/> :s :p << :a :b :c >> .`,
+		"quoted triple not permitted in RDF 1.1", 38},
+	{RDF11, `PREFIX : <http://example.com/> :s :p :o {| :q :v |} .`,
+		"illegal triple continuation", 41},
+	{RDF11, `PREFIX : <http://example.com/> :s :p "x"@en--ltr .`,
+		"empty code in language tag", 45},
+}
+
+func TestReaderVersionSyntaxErrors(t *testing.T) {
+	for _, test := range versionSyntaxErrors {
+		r := Reader{
+			R:       bufio.NewReader(strings.NewReader(test.turtle)),
+			Version: test.version,
+		}
+
+		var err error
+		for err == nil {
+			_, err = r.ReadAppend(nil)
+		}
+		var e *SyntaxError
+		if !errors.As(err, &e) {
+			t.Errorf("got error %v, want a *SyntaxError, for Turtle:\n%s", err, test.turtle)
+			continue
+		}
+		if e.Reason != test.reason || e.Column != test.column {
+			t.Errorf("got reason %q at column %d, want %q at column %d, for Turtle:\n%s", e.Reason, e.Column, test.reason, test.column, test.turtle)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package tripn
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+)
+
+// TriGReader parses TriG in a strict manner. The input is standard compliant
+// when read completes without error and vise versa. TriG extends Turtle with
+// graph blocks, i.e., "{ … }" for the default graph, and "<g> { … }" or
+// "GRAPH <g> { … }" for named graphs.
+//
+// Each blank node label gets a Skolem IRI, which is the same for all of its
+// occurrences in the document, graph labels and graph blocks included. Anonymous
+// nodes, as in "[]", get a new Skolem IRI each.
+type TriGReader struct {
+	// Statements and graph blocks may span any number of lines, regardless
+	// of the buffer size. MaxTokenSize limits the tokens instead.
+	R *bufio.Reader
+
+	// Tokens larger than MaxTokenSize in bytes cause a *SyntaxError. Zero
+	// defaults to DefaultMaxTokenSize.
+	MaxTokenSize int
+
+	// BaseIRI is the document base, which "@base" and "BASE" directives
+	// replace as they are read. Users may initialize the base IRI to the
+	// data location.
+	BaseIRI *url.URL
+
+	// Version selects the revision of the grammar. The zero value accepts
+	// RDF-star on top of RDF 1.1.
+	Version RDFVersion
+
+	r   Reader   // parser state
+	buf []Triple // statement reuse
+}
+
+// ReadAppend adds quads from the input stream to dst, and it returns the
+// extended buffer. Reads match the order of appearance with the nested nodes,
+// if any, before their enclosing statement.
+//
+// SyntaxError is used for malformed TriG exclusively. Stream errors pass as is,
+// with the exception of io.EOF. Incomplete records at the end of stream, which
+// includes graphs without a closing "}", are addressed with io.ErrUnexpectedEOF
+// instead.
+func (r *TriGReader) ReadAppend(dst []Quad) ([]Quad, error) {
+	r.r.R = r.R
+	r.r.MaxTokenSize = r.MaxTokenSize
+	r.r.BaseIRI = r.BaseIRI
+	r.r.Version = r.Version
+	r.r.grammar = trigGrammar
+
+	var err error
+	r.buf, err = r.r.ReadAppend(r.buf[:0])
+	r.BaseIRI = r.r.BaseIRI
+	if err != nil {
+		if r.r.inGraph && errors.Is(err, io.EOF) {
+			err = fmt.Errorf("%w: graph not closed", io.ErrUnexpectedEOF)
+		}
+		return dst, err
+	}
+
+	for _, t := range r.buf {
+		dst = append(dst, Quad{Triple: t, GraphIRI: r.r.graphIRI})
+	}
+	return dst, nil
+}
+
+// OpenGraph enters the graph named IRI, with zero for the default graph.
+func (r *Reader) openGraph(IRI string) {
+	r.inGraph = true
+	r.graphIRI = IRI
+}
+
+// AfterLabelOrSubject continues with line after a node, which may be the label
+// of a graph in TriG. The return is true for graph encounters, in which case
+// the remainder starts after the "{".
+func (r *Reader) afterLabelOrSubject(IRI string, line []byte) (remainder []byte, isLabel bool, err error) {
+	if r.grammar != trigGrammar || r.inGraph {
+		return line, false, nil
+	}
+	line, err = r.lineContinue(line)
+	if err != nil {
+		return nil, false, err
+	}
+	if line[0] != '{' {
+		return line, false, nil
+	}
+	r.openGraph(IRI)
+	return line[1:], true, nil
+}
+
+// AfterGraphKeyword continues with line after a "GRAPH" encounter. The
+// remainder starts after the "{".
+func (r *Reader) afterGraphKeyword(line []byte) (remainder []byte, err error) {
+	line, err = r.lineContinue(line)
+	if err != nil {
+		return nil, err
+	}
+
+	var IRI string
+	switch line[0] {
+	case '<':
+		IRI, line, err = r.inIRI(line)
+	case '_':
+		IRI, line, err = r.inBlankLabel(line)
+	case '[':
+		line, err = r.lineContinue(line[1:])
+		if err == nil && line[0] != ']' {
+			err = r.syntaxErr(ErrUnexpectedToken, line, "graph label with blank node property list")
+		}
+		if err == nil {
+			IRI = r.newAnonIRI()
+			line = line[1:]
+		}
+	default:
+		var rest []byte
+		IRI, _, rest, err = r.inPrefixedName(line)
+		if err == nil && IRI == "" {
+			err = r.syntaxErr(ErrUnexpectedToken, line, "illegal graph label token")
+		}
+		line = rest
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	line, err = r.lineContinue(line)
+	if err != nil {
+		return nil, err
+	}
+	if line[0] != '{' {
+		return nil, r.syntaxErr(ErrUnexpectedToken, line, `graph label not followed by "{"`)
+	}
+	r.openGraph(IRI)
+	return line[1:], nil
+}
+
+// TriGWriter encodes TriG for human consumption, like TurtleWriter does with
+// Turtle. The default graph comes first, without a graph block. Each named
+// graph gets a "GRAPH" block. Output is written on Close, as grouping needs all
+// statements.
+//
+// Blank nodes are shared among graphs in TriG. Skolem IRIs which occur in more
+// than one graph, or which name a graph, get a blank node label "_:…".
+type TriGWriter struct {
+	W io.Writer
+
+	// Prefixes maps labels to namespace IRIs, as in "ex" to
+	// "http://example.com/ns#". IRI references within a namespace are
+	// written as a prefixed name. Only the prefixes in use get a directive.
+	Prefixes map[string]string
+
+	// Order applies to the named graphs too.
+	Order TurtleOrder
+
+	quads []Quad
+}
+
+// WriteQuad buffers q. Quads which can not be expressed in TriG get an error
+// without any effect.
+func (w *TriGWriter) WriteQuad(q Quad) error {
+	t, err := checkNTriple(q.Triple)
+	if err != nil {
+		return err
+	}
+	if q.GraphIRI != "" {
+		if err := checkNTriplesIRI(q.GraphIRI); err != nil {
+			return err
+		}
+	}
+	w.quads = append(w.quads, Quad{Triple: t, GraphIRI: q.GraphIRI})
+	return nil
+}
+
+// WriteTriple buffers t for the default graph.
+func (w *TriGWriter) WriteTriple(t Triple) error {
+	return w.WriteQuad(Quad{Triple: t})
+}
+
+// Close writes the document with all quads buffered. It does not close W.
+func (w *TriGWriter) Close() error {
+	enc := turtleEncoder{prefixes: w.Prefixes}
+	if err := enc.checkPrefixes(); err != nil {
+		return err
+	}
+
+	// triples per graph, with the default graph first
+	graphIRIs := []string{""}
+	triplesPerGraph := make(map[string][]Triple)
+	for _, q := range w.quads {
+		if _, ok := triplesPerGraph[q.GraphIRI]; !ok && q.GraphIRI != "" {
+			graphIRIs = append(graphIRIs, q.GraphIRI)
+		}
+		triplesPerGraph[q.GraphIRI] = append(triplesPerGraph[q.GraphIRI], q.Triple)
+	}
+	w.quads = nil
+	if w.Order == SortedOrder {
+		slices.Sort(graphIRIs[1:])
+	}
+
+	subjectsPerGraph := make([][]*turtleSubject, len(graphIRIs))
+	for i, IRI := range graphIRIs {
+		subjectsPerGraph[i] = groupTurtle(triplesPerGraph[IRI], w.Order)
+		enc.scan(subjectsPerGraph[i], IRI)
+	}
+
+	var body []byte
+	for i, IRI := range graphIRIs {
+		if IRI == "" {
+			body = enc.appendStatements(body, subjectsPerGraph[i], "")
+			continue
+		}
+		if len(body) != 0 {
+			body = append(body, '\n')
+		}
+		body = append(body, "GRAPH "...)
+		body = enc.appendNode(body, IRI)
+		body = append(body, " {\n"...)
+		body = enc.appendStatements(body, subjectsPerGraph[i], "\t")
+		body = append(body, "}\n"...)
+	}
+
+	doc := enc.appendPrefixes(nil)
+	if len(doc) != 0 && len(body) != 0 {
+		doc = append(doc, '\n')
+	}
+	doc = append(doc, body...)
+	_, err := w.W.Write(doc)
+	return err
+}
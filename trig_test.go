@@ -0,0 +1,254 @@
+package tripn
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var trigQuads = []struct {
+	trig  string
+	quads []Quad
+}{
+	{"", []Quad{}},
+	{"{}\nGRAPH <http://example.com/g> { }", []Quad{}},
+
+	// W3C TriG Recommendation, example 1
+	{`# This document encodes one graph.
+@prefix ex: <http://www.example.org/vocabulary#> .
+@prefix : <http://www.example.org/exampleDocument#> .
+
+:G1 { :Monica a ex:Person ;
+              ex:name "Monica Murphy" ;
+              ex:hasSkill ex:Management ,
+                          ex:Programming . }`,
+		[]Quad{
+			{Triple{"http://www.example.org/exampleDocument#Monica", "http://www.w3.org/1999/02/22-rdf-syntax-ns#type", "http://www.example.org/vocabulary#Person", "", "", ""}, "http://www.example.org/exampleDocument#G1"},
+			{Triple{"http://www.example.org/exampleDocument#Monica", "http://www.example.org/vocabulary#name", "Monica Murphy", XSDString, "", ""}, "http://www.example.org/exampleDocument#G1"},
+			{Triple{"http://www.example.org/exampleDocument#Monica", "http://www.example.org/vocabulary#hasSkill", "http://www.example.org/vocabulary#Management", "", "", ""}, "http://www.example.org/exampleDocument#G1"},
+			{Triple{"http://www.example.org/exampleDocument#Monica", "http://www.example.org/vocabulary#hasSkill", "http://www.example.org/vocabulary#Programming", "", "", ""}, "http://www.example.org/exampleDocument#G1"},
+		},
+	},
+
+	// default graph with and without brackets, optional dot at graph end
+	{`PREFIX : <http://example.com/>
+:s :p :o .
+{ :s :p :o2 }
+graph :g { :s :p :o3 . :s :p :o4 }
+:s :p :o5 .`,
+		[]Quad{
+			{Triple{"http://example.com/s", "http://example.com/p", "http://example.com/o", "", "", ""}, ""},
+			{Triple{"http://example.com/s", "http://example.com/p", "http://example.com/o2", "", "", ""}, ""},
+			{Triple{"http://example.com/s", "http://example.com/p", "http://example.com/o3", "", "", ""}, "http://example.com/g"},
+			{Triple{"http://example.com/s", "http://example.com/p", "http://example.com/o4", "", "", ""}, "http://example.com/g"},
+			{Triple{"http://example.com/s", "http://example.com/p", "http://example.com/o5", "", "", ""}, ""},
+		},
+	},
+
+	// blank node graphs, with nested nodes
+	{`@base <http://example.com/> .
+_:g { [ <p> <o> ] <p> ( 1 ) }
+[] { <s> <p> <o> ; }
+GRAPH [] { [ <p> <o> ] }`,
+		[]Quad{
+			{Triple{"http://example.com/skolem-stub/anon#1", "http://example.com/p", "http://example.com/o", "", "", ""}, "http://example.com/skolem-stub/blank#g"},
+			{Triple{"http://example.com/skolem-stub/anon#2", "http://www.w3.org/1999/02/22-rdf-syntax-ns#first", "1", XSDInteger, "", ""}, "http://example.com/skolem-stub/blank#g"},
+			{Triple{"http://example.com/skolem-stub/anon#2", "http://www.w3.org/1999/02/22-rdf-syntax-ns#rest", "http://www.w3.org/1999/02/22-rdf-syntax-ns#nil", "", "", ""}, "http://example.com/skolem-stub/blank#g"},
+			{Triple{"http://example.com/skolem-stub/anon#1", "http://example.com/p", "http://example.com/skolem-stub/anon#2", "", "", ""}, "http://example.com/skolem-stub/blank#g"},
+			{Triple{"http://example.com/s", "http://example.com/p", "http://example.com/o", "", "", ""}, "http://example.com/skolem-stub/anon#3"},
+			{Triple{"http://example.com/skolem-stub/anon#5", "http://example.com/p", "http://example.com/o", "", "", ""}, "http://example.com/skolem-stub/anon#4"},
+		},
+	},
+}
+
+func TestTriGReader(t *testing.T) {
+	for _, test := range trigQuads {
+		for _, bufSize := range []int{16, 4096} {
+			r := TriGReader{R: bufio.NewReaderSize(strings.NewReader(test.trig), bufSize)}
+			r.r.skolemIRICache = "http://example.com/skolem-stub/"
+
+			got := []Quad{}
+			var err error
+			for err == nil {
+				got, err = r.ReadAppend(got)
+			}
+			if err != io.EOF {
+				t.Errorf("got error %v, with buffer size %d, for TriG:\n%s", err, bufSize, test.trig)
+				continue
+			}
+			if !slices.Equal(got, test.quads) {
+				t.Errorf("got quads %q, want %q, with buffer size %d, for TriG:\n%s", got, test.quads, bufSize, test.trig)
+			}
+		}
+	}
+}
+
+var trigSyntaxErrors = []struct {
+	trig   string
+	reason string
+	column int
+}{
+	{`{ @prefix ex: <http://example.com/> . }`,
+		"directive inside graph", 3},
+	{`{ PREFIX ex: <http://example.com/> }`,
+		"illegal subject token", 3},
+	{`{ { } }`,
+		"illegal subject token", 3},
+	{`}`,
+		"illegal subject token", 1},
+	{`GRAPH <http://example.com/g> <http://example.com/s> <http://example.com/p> <http://example.com/o> .`,
+		`graph label not followed by "{"`, 30},
+	{`GRAPH [ <http://example.com/p> <http://example.com/o> ] { }`,
+		"graph label with blank node property list", 9},
+	{`GRAPH "g" { }`,
+		"illegal graph label token", 7},
+	{`{ <http://example.com/s> <http://example.com/p> <http://example.com/o> <http://example.com/s> <http://example.com/p> <http://example.com/o> }`,
+		"illegal triple continuation", 72},
+}
+
+func TestTriGReaderSyntaxErrors(t *testing.T) {
+	for _, test := range trigSyntaxErrors {
+		r := TriGReader{R: bufio.NewReader(strings.NewReader(test.trig))}
+
+		var err error
+		for err == nil {
+			_, err = r.ReadAppend(nil)
+		}
+		var e *SyntaxError
+		if !errors.As(err, &e) {
+			t.Errorf("got error %v, want a *SyntaxError, for TriG:\n%s", err, test.trig)
+			continue
+		}
+		if e.Reason != test.reason || e.Column != test.column {
+			t.Errorf("got reason %q at column %d, want %q at column %d, for TriG:\n%s", e.Reason, e.Column, test.reason, test.column, test.trig)
+		}
+		if !strings.HasPrefix(e.Error(), "TriG syntax violation") {
+			t.Errorf("got error message %q, want TriG", e.Error())
+		}
+	}
+}
+
+func TestTriGReaderGraphNotClosed(t *testing.T) {
+	r := TriGReader{R: bufio.NewReader(strings.NewReader(`<http://example.com/g> { <http://example.com/s> <http://example.com/p> <http://example.com/o> .`))}
+
+	got, err := r.ReadAppend(nil)
+	if err != nil || len(got) != 1 {
+		t.Fatalf("got %d quads and error %v, want 1 quad", len(got), err)
+	}
+	_, err = r.ReadAppend(nil)
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("got error %v, want io.ErrUnexpectedEOF", err)
+	}
+}
+
+var trigWriterFolds = []struct {
+	trig string
+	want string
+}{
+	{`@prefix : <http://example.com/> .
+:g2 { :s :p [ :q 1 ] . }
+{ :s a :Thing ; :p ( 1 2 ) . }
+:g1 { :s :p :o , :o2 . :t :p :o . }
+`, `@prefix : <http://example.com/> .
+
+:s a :Thing ;
+	:p ( 1 2 ) .
+
+GRAPH :g2 {
+	:s :p [ :q 1 ] .
+}
+
+GRAPH :g1 {
+	:s :p :o, :o2 .
+
+	:t :p :o .
+}
+`},
+
+	// blank nodes shared among graphs, and blank graph labels
+	{`@prefix : <http://example.com/> .
+:g1 { :s :p _:x . }
+:g2 { _:x :p :o . }
+_:g { [] :p _:g . }
+`, `@prefix : <http://example.com/> .
+
+GRAPH :g1 {
+	:s :p _:b1 .
+}
+
+GRAPH :g2 {
+	_:b1 :p :o .
+}
+
+GRAPH _:b2 {
+	[] :p _:b2 .
+}
+`},
+}
+
+func TestTriGWriter(t *testing.T) {
+	for _, test := range trigWriterFolds {
+		r := TriGReader{R: bufio.NewReader(strings.NewReader(test.trig))}
+		var quads []Quad
+		var err error
+		for err == nil {
+			quads, err = r.ReadAppend(quads)
+		}
+		if err != io.EOF {
+			t.Errorf("got error %v, for TriG:\n%s", err, test.trig)
+			continue
+		}
+
+		var b strings.Builder
+		w := TriGWriter{W: &b, Prefixes: map[string]string{"": "http://example.com/"}}
+		for _, q := range quads {
+			if err := w.WriteQuad(q); err != nil {
+				t.Fatal("write error:", err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal("close error:", err)
+		}
+		if got := b.String(); got != test.want {
+			t.Errorf("got TriG:\n%s\nwant:\n%s\nfor TriG:\n%s", got, test.want, test.trig)
+		}
+	}
+}
+
+func TestTriGWriterSortedOrder(t *testing.T) {
+	var b strings.Builder
+	w := TriGWriter{W: &b, Order: SortedOrder}
+	for _, q := range []Quad{
+		{Triple{"http://example.com/s", "http://example.com/p", "2", XSDInteger, "", ""}, "http://example.com/g2"},
+		{Triple{"http://example.com/s", "http://example.com/p", "1", XSDInteger, "", ""}, "http://example.com/g1"},
+		{Triple{"http://example.com/s", "http://example.com/p", "0", XSDInteger, "", ""}, ""},
+	} {
+		if err := w.WriteQuad(q); err != nil {
+			t.Fatal("write error:", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal("close error:", err)
+	}
+
+	const want = `<http://example.com/s> <http://example.com/p> 0 .
+
+GRAPH <http://example.com/g1> {
+	<http://example.com/s> <http://example.com/p> 1 .
+}
+
+GRAPH <http://example.com/g2> {
+	<http://example.com/s> <http://example.com/p> 2 .
+}
+`
+	if got := b.String(); got != want {
+		t.Errorf("got TriG:\n%s\nwant:\n%s", got, want)
+	}
+
+	if err := w.WriteQuad(Quad{Triple{"http://example.com/s", "http://example.com/p", "http://example.com/o", "", "", ""}, "g"}); err == nil {
+		t.Error("got no error for relative graph IRI")
+	}
+}
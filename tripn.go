@@ -6,11 +6,20 @@ import (
 	"fmt"
 	"math/big"
 	"strconv"
+	"strings"
 )
 
 // Triple contains an RDF statement.
+//
+// Nodes are plain strings. Quoted triples (RDF-star) and triple terms (RDF 1.2)
+// are encoded in SubjectIRI and Object as their N-Triples notation, e.g.,
+// "<< <http://example.com/s> <http://example.com/p> \"o\" >>", with canonical
+// escapes and with single spaces, as produced by Quoted and TripleTerm. Such
+// notation never collides with an IRI reference, as "<" is not allowed in IRIs.
+// Use IsQuotedTriple to tell them apart, and use ParseQuotedTriple to get the
+// Triple back. Blank nodes are encoded as Skolem IRIs instead (IsSkolemIRI).
 type Triple struct {
-	// The subject node is a IRI reference.
+	// The subject node is a IRI reference, or a quoted triple in RDF-star.
 	SubjectIRI string
 
 	// The predicate is a IRI reference (to its definition).
@@ -19,25 +28,66 @@ type Triple struct {
 	// The object node is a literal iff DatatypeIRI is not zero.
 	Object string
 
-	// Zero means that Object is a IRI reference.
+	// Zero means that Object is a IRI reference, or a quoted triple in
+	// RDF-star, or a triple term in RDF 1.2.
 	DatatypeIRI string
 
 	// The value space of language tags is always in lower case.
 	// When set, then the datatype IRI is fixed to the following.
 	// http://www.w3.org/1999/02/22-rdf-syntax-ns#langString
 	LangTag string
+
+	// The base direction is either "ltr" or "rtl", and it requires a
+	// language tag. When set, then the datatype IRI is fixed to the
+	// following instead.
+	// http://www.w3.org/1999/02/22-rdf-syntax-ns#dirLangString
+	BaseDir string
 }
 
-// String returns an N-Triples line excluding new-line character.
+// String returns an N-Triples line excluding new-line character. Quoted triples
+// are in N-Triples-star notation. Literals and IRI references are escaped like
+// in the canonical form of N-Triples.
 func (t Triple) String() string {
-	switch {
-	case t.DatatypeIRI == "":
-		return fmt.Sprintf("<%s> <%s> <%s> .", t.SubjectIRI, t.PredicateIRI, t.Object)
-	case t.LangTag == "":
-		return fmt.Sprintf("<%s> <%s> %q^^<%s> .", t.SubjectIRI, t.PredicateIRI, t.Object, t.DatatypeIRI)
-	default:
-		return fmt.Sprintf("<%s> <%s> %q@%s .", t.SubjectIRI, t.PredicateIRI, t.Object, t.LangTag)
+	return string(append(appendNTriple(nil, t), " ."...))
+}
+
+// Quoted returns the RDF-star notation of t, as in "<< <s> <p> <o> >>". Any
+// SubjectIRI or Object may hold such notation for a quoted triple. Quoted
+// triples are not asserted, i.e., they make no statement on their own.
+func (t Triple) Quoted() string {
+	return "<< " + string(appendNTriple(nil, t)) + " >>"
+}
+
+// TripleTerm returns the RDF 1.2 notation of t, as in "<<( <s> <p> <o> )>>".
+// Any Object may hold such notation for a triple term. Triple terms are not
+// asserted, i.e., they make no statement on their own.
+func (t Triple) TripleTerm() string {
+	return "<<( " + string(appendNTriple(nil, t)) + " )>>"
+}
+
+// IsQuotedTriple returns whether s is the notation of a quoted triple, or the
+// notation of a triple term, rather than an IRI reference. See Triple.Quoted
+// and Triple.TripleTerm for the format.
+func IsQuotedTriple(s string) bool {
+	return strings.HasPrefix(s, "<<")
+}
+
+// Quad contains an RDF statement with the graph it belongs to.
+type Quad struct {
+	Triple
+
+	// The graph is a IRI reference. Zero means the default graph.
+	GraphIRI string
+}
+
+// String returns an N-Quads line excluding new-line character.
+func (q Quad) String() string {
+	b := appendNTriple(nil, q.Triple)
+	if q.GraphIRI != "" {
+		b = append(b, ' ')
+		b = appendNTriplesIRI(b, q.GraphIRI)
 	}
+	return string(append(b, " ."...))
 }
 
 // XSDString links the XML Schema Definition of the primitive type.
@@ -0,0 +1,416 @@
+package tripn
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// TriXNS is the namespace of TriX elements.
+const trixNS = "http://www.w3.org/2004/03/trix/trix-1/"
+
+// TriXHeader opens a TriX document.
+const trixHeader = "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<TriX xmlns=\"" + trixNS + "\">\n"
+
+// XMLNSIRI is the namespace of the xml prefix, as in xml:lang.
+const xmlNSIRI = "http://www.w3.org/XML/1998/namespace"
+
+// TriXReader parses TriX, the XML serialization of graph and triple elements.
+// Quads come in the same form as TriGReader produces them. Each id element gets
+// a Skolem IRI, which is the same for all occurrences of its identifier in the
+// document, graph names included.
+type TriXReader struct {
+	// The XML decoder applies buffering when R is not an io.ByteReader.
+	R io.Reader
+
+	dec *xml.Decoder // lazy initiation
+
+	rootSeen bool // root element encountered
+	inTriX   bool // within the TriX root element
+	inGraph  bool // within a graph element
+
+	graphIRI   string // current graph, zero for default
+	graphNamed bool   // graph name passed, if any
+
+	tokLineNo int   // input position of last token
+	tokOffset int64 // input position of last token
+
+	skolemizer // blank node IRIs
+}
+
+// SyntaxErr is a convenience constructor. The position is that of the last
+// token read.
+func (r *TriXReader) syntaxErr(kind error, reason string) error {
+	return &SyntaxError{
+		LineNo:  r.tokLineNo,
+		Offset:  r.tokOffset,
+		Reason:  reason,
+		Err:     kind,
+		grammar: trixGrammar,
+	}
+}
+
+// Token returns the next token from the XML decoder, with namespaces resolved.
+// Comments, processing instructions and directives are skipped.
+func (r *TriXReader) token() (xml.Token, error) {
+	for {
+		r.tokLineNo, _ = r.dec.InputPos()
+		r.tokOffset = r.dec.InputOffset()
+
+		tok, err := r.dec.Token()
+		if err != nil {
+			var e *xml.SyntaxError
+			if errors.As(err, &e) {
+				return nil, &SyntaxError{
+					LineNo:  e.Line,
+					Offset:  -1,
+					Reason:  e.Msg,
+					Err:     ErrMalformedXML,
+					grammar: trixGrammar,
+				}
+			}
+			return nil, err
+		}
+
+		switch tok.(type) {
+		case xml.StartElement, xml.EndElement, xml.CharData:
+			return tok, nil
+		}
+	}
+}
+
+// TokenContinue is like token, yet it expects more to follow.
+func (r *TriXReader) tokenContinue() (xml.Token, error) {
+	tok, err := r.token()
+	if err != nil && errors.Is(err, io.EOF) {
+		err = fmt.Errorf("%w: element not closed", io.ErrUnexpectedEOF)
+	}
+	return tok, err
+}
+
+// ReadAppend adds the quad of the next triple element to dst, and it returns
+// the extended buffer.
+//
+// SyntaxError is used for malformed TriX exclusively, which includes malformed
+// XML. Stream errors pass as is, with the exception of io.EOF. Incomplete
+// records at the end of stream are addressed with io.ErrUnexpectedEOF instead.
+func (r *TriXReader) ReadAppend(dst []Quad) ([]Quad, error) {
+	if r.dec == nil {
+		r.dec = xml.NewDecoder(r.R)
+	}
+
+	for {
+		tok, err := r.token()
+		if err != nil {
+			if errors.Is(err, io.EOF) && (r.inTriX || !r.rootSeen) {
+				err = fmt.Errorf("%w: root element not closed", io.ErrUnexpectedEOF)
+			}
+			return dst, err
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if tok.Name.Space != trixNS {
+				return dst, r.syntaxErr(ErrUnexpectedToken, fmt.Sprintf("element %q not in the TriX namespace", tok.Name.Local))
+			}
+			switch {
+			case !r.rootSeen:
+				if tok.Name.Local != "TriX" {
+					return dst, r.syntaxErr(ErrUnexpectedToken, "root element is not TriX")
+				}
+				r.rootSeen = true
+				r.inTriX = true
+
+			case !r.inTriX:
+				return dst, r.syntaxErr(ErrMalformedXML, "more than one root element")
+
+			case !r.inGraph:
+				if tok.Name.Local != "graph" {
+					return dst, r.syntaxErr(ErrUnexpectedToken, fmt.Sprintf("element %q not permitted in TriX; expected graph", tok.Name.Local))
+				}
+				r.inGraph = true
+				r.graphIRI = ""
+				r.graphNamed = false
+
+			default:
+				switch tok.Name.Local {
+				case "uri", "id":
+					if r.graphNamed {
+						return dst, r.syntaxErr(ErrUnexpectedToken, "graph name after triple or more than once")
+					}
+					r.graphNamed = true
+					r.graphIRI, err = r.resource(tok)
+					if err != nil {
+						return dst, err
+					}
+
+				case "triple":
+					r.graphNamed = true
+					q := Quad{GraphIRI: r.graphIRI}
+					err = r.triple(&q.Triple)
+					if err != nil {
+						return dst, err
+					}
+					return append(dst, q), nil
+
+				default:
+					return dst, r.syntaxErr(ErrUnexpectedToken, fmt.Sprintf("element %q not permitted in graph; expected triple", tok.Name.Local))
+				}
+			}
+
+		case xml.EndElement:
+			if r.inGraph {
+				r.inGraph = false
+			} else {
+				r.inTriX = false
+			}
+
+		case xml.CharData:
+			if !isXMLSpace(tok) {
+				return dst, r.syntaxErr(ErrUnexpectedToken, "text outside of triple element")
+			}
+		}
+	}
+}
+
+// Triple reads the content of a triple element into t, including its end.
+func (r *TriXReader) triple(t *Triple) error {
+	for i := 0; ; i++ {
+		tok, err := r.tokenContinue()
+		if err != nil {
+			return err
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if tok.Name.Space != trixNS {
+				return r.syntaxErr(ErrUnexpectedToken, fmt.Sprintf("element %q not in the TriX namespace", tok.Name.Local))
+			}
+			switch i {
+			case 0:
+				if tok.Name.Local != "uri" && tok.Name.Local != "id" {
+					return r.syntaxErr(ErrUnexpectedToken, "subject is not a uri nor an id element")
+				}
+				t.SubjectIRI, err = r.resource(tok)
+			case 1:
+				if tok.Name.Local != "uri" {
+					return r.syntaxErr(ErrUnexpectedToken, "predicate is not a uri element")
+				}
+				t.PredicateIRI, err = r.resource(tok)
+			case 2:
+				err = r.object(tok, t)
+			default:
+				return r.syntaxErr(ErrUnexpectedToken, "more than three elements in triple")
+			}
+			if err != nil {
+				return err
+			}
+
+		case xml.EndElement:
+			if i != 3 {
+				return r.syntaxErr(ErrUnexpectedToken, "less than three elements in triple")
+			}
+			return nil
+
+		case xml.CharData:
+			if !isXMLSpace(tok) {
+				return r.syntaxErr(ErrUnexpectedToken, "text in triple element")
+			}
+			i-- // not an element
+		}
+	}
+}
+
+// Object reads the element of start as the object of t, including its end.
+func (r *TriXReader) object(start xml.StartElement, t *Triple) (err error) {
+	switch start.Name.Local {
+	case "uri", "id":
+		t.Object, err = r.resource(start)
+		return err
+
+	case "plainLiteral":
+		for _, a := range start.Attr {
+			if a.Name.Space == xmlNSIRI && a.Name.Local == "lang" && a.Value != "" {
+				t.LangTag = strings.ToLower(a.Value)
+			}
+		}
+		if t.LangTag == "" {
+			t.DatatypeIRI = XSDString
+		} else {
+			t.DatatypeIRI = rdfLangString
+		}
+
+	case "typedLiteral":
+		for _, a := range start.Attr {
+			if a.Name.Space == "" && a.Name.Local == "datatype" {
+				t.DatatypeIRI = a.Value
+			}
+		}
+		if t.DatatypeIRI == "" {
+			return r.syntaxErr(ErrUnexpectedToken, "typedLiteral without datatype attribute")
+		}
+
+	default:
+		return r.syntaxErr(ErrUnexpectedToken, "object is not a uri, nor an id, nor a plainLiteral, nor a typedLiteral element")
+	}
+
+	t.Object, err = r.text()
+	return err
+}
+
+// Resource reads the content of a uri or an id element, including its end.
+// Identifiers get a Skolem IRI.
+func (r *TriXReader) resource(start xml.StartElement) (IRI string, err error) {
+	s, err := r.text()
+	if err != nil {
+		return "", err
+	}
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", r.syntaxErr(ErrUnexpectedToken, fmt.Sprintf("empty %s element", start.Name.Local))
+	}
+	if start.Name.Local == "id" {
+		return r.blankIRI(s), nil
+	}
+	return s, nil
+}
+
+// Text reads character data up to the end of the current element.
+func (r *TriXReader) text() (string, error) {
+	var b strings.Builder
+	for {
+		tok, err := r.tokenContinue()
+		if err != nil {
+			return "", err
+		}
+		switch tok := tok.(type) {
+		case xml.CharData:
+			b.Write(tok)
+		case xml.EndElement:
+			return b.String(), nil
+		default:
+			return "", r.syntaxErr(ErrUnexpectedToken, "element in text-only element")
+		}
+	}
+}
+
+// TriXWriter encodes TriX. Consecutive quads of the same graph share a graph
+// element. Skolem IRIs, as minted by the readers, are written as blank node
+// identifiers. Close must be called to end the document.
+type TriXWriter struct {
+	W io.Writer
+
+	started  bool   // document opened
+	inGraph  bool   // graph element open
+	graphIRI string // of open graph element
+
+	blank turtleEncoder // blank node labels
+}
+
+// WriteQuad encodes q. Base directions, quoted triples, and characters which
+// are not permitted in XML can not be expressed in TriX. Such quads get an
+// error without any output written.
+func (w *TriXWriter) WriteQuad(q Quad) error {
+	if q.BaseDir != "" {
+		return fmt.Errorf("TriX can not express the base direction of %s", q)
+	}
+	if IsQuotedTriple(q.SubjectIRI) || q.DatatypeIRI == "" && IsQuotedTriple(q.Object) {
+		return fmt.Errorf("TriX can not express the quoted triple of %s", q)
+	}
+	for _, s := range [...]string{q.SubjectIRI, q.PredicateIRI, q.Object, q.DatatypeIRI, q.LangTag, q.GraphIRI} {
+		for _, c := range s {
+			if !isXMLChar(c) {
+				return fmt.Errorf("TriX can not express character %q of %s", c, q)
+			}
+		}
+	}
+
+	var b strings.Builder
+	if !w.started {
+		b.WriteString(trixHeader)
+	}
+	sameGraph := w.inGraph && w.graphIRI == q.GraphIRI
+	if w.inGraph && !sameGraph {
+		b.WriteString("\t</graph>\n")
+	}
+	if !sameGraph {
+		b.WriteString("\t<graph>\n")
+		if q.GraphIRI != "" {
+			b.WriteString("\t\t")
+			w.writeResource(&b, q.GraphIRI)
+			b.WriteByte('\n')
+		}
+	}
+
+	b.WriteString("\t\t<triple>\n\t\t\t")
+	w.writeResource(&b, q.SubjectIRI)
+	b.WriteString("\n\t\t\t<uri>")
+	writeXMLText(&b, []byte(q.PredicateIRI))
+	b.WriteString("</uri>\n\t\t\t")
+	switch q.DatatypeIRI {
+	case "":
+		w.writeResource(&b, q.Object)
+	case XSDString:
+		b.WriteString("<plainLiteral>")
+		writeXMLText(&b, []byte(q.Object))
+		b.WriteString("</plainLiteral>")
+	case rdfLangString:
+		b.WriteString(`<plainLiteral xml:lang="`)
+		writeXMLAttrValue(&b, q.LangTag)
+		b.WriteString(`">`)
+		writeXMLText(&b, []byte(q.Object))
+		b.WriteString("</plainLiteral>")
+	default:
+		b.WriteString(`<typedLiteral datatype="`)
+		writeXMLAttrValue(&b, q.DatatypeIRI)
+		b.WriteString(`">`)
+		writeXMLText(&b, []byte(q.Object))
+		b.WriteString("</typedLiteral>")
+	}
+	b.WriteString("\n\t\t</triple>\n")
+
+	if _, err := io.WriteString(w.W, b.String()); err != nil {
+		return err
+	}
+	w.started = true
+	w.inGraph = true
+	w.graphIRI = q.GraphIRI
+	return nil
+}
+
+// WriteResource writes either an id element for a Skolem IRI, or a uri element.
+func (w *TriXWriter) writeResource(b *strings.Builder, IRI string) {
+	if IsSkolemIRI(IRI) {
+		b.WriteString("<id>" + w.blank.label(IRI) + "</id>")
+		return
+	}
+	b.WriteString("<uri>")
+	writeXMLText(b, []byte(IRI))
+	b.WriteString("</uri>")
+}
+
+// WriteTriple encodes t in the default graph.
+func (w *TriXWriter) WriteTriple(t Triple) error {
+	return w.WriteQuad(Quad{Triple: t})
+}
+
+// Close ends the document. It does not close W.
+func (w *TriXWriter) Close() error {
+	var b strings.Builder
+	if !w.started {
+		b.WriteString(trixHeader)
+	}
+	if w.inGraph {
+		b.WriteString("\t</graph>\n")
+	}
+	b.WriteString("</TriX>\n")
+
+	if _, err := io.WriteString(w.W, b.String()); err != nil {
+		return err
+	}
+	w.started = true
+	w.inGraph = false
+	return nil
+}
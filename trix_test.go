@@ -0,0 +1,282 @@
+package tripn
+
+import (
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var trixQuads = []struct {
+	trix  string
+	quads []Quad
+}{
+	{`<TriX xmlns="http://www.w3.org/2004/03/trix/trix-1/"/>`, []Quad{}},
+
+	// TriX specification, example with named graphs and literals
+	{`<?xml version="1.0" encoding="utf-8"?>
+<TriX xmlns="http://www.w3.org/2004/03/trix/trix-1/">
+  <graph>
+    <uri>http://example.org/graph1</uri>
+    <triple>
+      <uri>http://example.org/Bob</uri>
+      <uri>http://example.org/wife</uri>
+      <uri>http://example.org/Mary</uri>
+    </triple>
+    <triple>
+      <uri>http://example.org/Bob</uri>
+      <uri>http://example.org/name</uri>
+      <plainLiteral>Bob</plainLiteral>
+    </triple>
+  </graph>
+  <graph>
+    <!-- default graph -->
+    <triple>
+      <id>x</id>
+      <uri>http://example.org/age</uri>
+      <typedLiteral datatype="http://www.w3.org/2001/XMLSchema#integer">32</typedLiteral>
+    </triple>
+    <triple>
+      <uri>http://example.org/Mary</uri>
+      <uri>http://example.org/name</uri>
+      <plainLiteral xml:lang="NL">Marie &amp; co</plainLiteral>
+    </triple>
+  </graph>
+  <graph>
+    <id>g</id>
+    <triple><uri>http://example.org/s</uri><uri>http://example.org/p</uri><id>x</id></triple>
+  </graph>
+</TriX>`,
+		[]Quad{
+			{Triple{"http://example.org/Bob", "http://example.org/wife", "http://example.org/Mary", "", "", ""}, "http://example.org/graph1"},
+			{Triple{"http://example.org/Bob", "http://example.org/name", "Bob", XSDString, "", ""}, "http://example.org/graph1"},
+			{Triple{"http://example.com/skolem-stub/blank#x", "http://example.org/age", "32", XSDInteger, "", ""}, ""},
+			{Triple{"http://example.org/Mary", "http://example.org/name", "Marie & co", rdfLangString, "nl", ""}, ""},
+			{Triple{"http://example.org/s", "http://example.org/p", "http://example.com/skolem-stub/blank#x", "", "", ""}, "http://example.com/skolem-stub/blank#g"},
+		},
+	},
+}
+
+func TestTriXReader(t *testing.T) {
+	for _, test := range trixQuads {
+		r := TriXReader{R: strings.NewReader(test.trix)}
+		r.skolemIRICache = "http://example.com/skolem-stub/"
+
+		got := []Quad{}
+		var err error
+		for err == nil {
+			got, err = r.ReadAppend(got)
+		}
+		if err != io.EOF {
+			t.Errorf("got error %v, for TriX:\n%s", err, test.trix)
+			continue
+		}
+		if !slices.Equal(got, test.quads) {
+			t.Errorf("got quads %q, want %q, for TriX:\n%s", got, test.quads, test.trix)
+		}
+	}
+}
+
+var trixSyntaxErrors = []struct {
+	trix   string
+	reason string
+}{
+	{`<TriX/>`,
+		`element "TriX" not in the TriX namespace`},
+	{`<RDF xmlns="http://www.w3.org/2004/03/trix/trix-1/"/>`,
+		"root element is not TriX"},
+	{`<TriX xmlns="http://www.w3.org/2004/03/trix/trix-1/"><triple/></TriX>`,
+		`element "triple" not permitted in TriX; expected graph`},
+	{`<TriX xmlns="http://www.w3.org/2004/03/trix/trix-1/"><graph><triple><uri>s</uri><uri>p</uri></triple></graph></TriX>`,
+		"less than three elements in triple"},
+	{`<TriX xmlns="http://www.w3.org/2004/03/trix/trix-1/"><graph><triple><uri>s</uri><id>p</id><uri>o</uri></triple></graph></TriX>`,
+		"predicate is not a uri element"},
+	{`<TriX xmlns="http://www.w3.org/2004/03/trix/trix-1/"><graph><triple><uri>s</uri><uri>p</uri><typedLiteral>o</typedLiteral></triple></graph></TriX>`,
+		"typedLiteral without datatype attribute"},
+	{`<TriX xmlns="http://www.w3.org/2004/03/trix/trix-1/"><graph><triple><uri>s</uri><uri>p</uri><uri>o</uri></triple><uri>g</uri></graph></TriX>`,
+		"graph name after triple or more than once"},
+	{`<TriX xmlns="http://www.w3.org/2004/03/trix/trix-1/"><graph><triple><uri><id>s</id></uri></triple></graph></TriX>`,
+		"element in text-only element"},
+}
+
+func TestTriXReaderSyntaxErrors(t *testing.T) {
+	for _, test := range trixSyntaxErrors {
+		r := TriXReader{R: strings.NewReader(test.trix)}
+
+		var err error
+		for err == nil {
+			_, err = r.ReadAppend(nil)
+		}
+		var e *SyntaxError
+		if !errors.As(err, &e) {
+			t.Errorf("got error %v, want a *SyntaxError, for TriX:\n%s", err, test.trix)
+			continue
+		}
+		if e.Reason != test.reason {
+			t.Errorf("got reason %q, want %q, for TriX:\n%s", e.Reason, test.reason, test.trix)
+		}
+		if !strings.HasPrefix(e.Error(), "TriX syntax violation") {
+			t.Errorf("got error message %q, want TriX", e.Error())
+		}
+	}
+}
+
+func TestTriXWriter(t *testing.T) {
+	quads := []Quad{
+		{Triple{"http://example.org/s", "http://example.org/p", "http://example.org/o", "", "", ""}, ""},
+		{Triple{"http://example.org/s", "http://example.org/p", "a < b & \"c\"", XSDString, "", ""}, ""},
+		{Triple{"http://example.org/s", "http://example.org/p", "hallo", rdfLangString, "nl", ""}, "http://example.org/g?a=1&b=2"},
+		{Triple{"http://example.org/s", "http://example.org/p", "1.0", XSDDecimal, "", ""}, "http://example.org/g?a=1&b=2"},
+		{Triple{"http://example.org/s", "http://example.org/p", "line\r\nfeed", XSDString, "", ""}, ""},
+	}
+
+	var b strings.Builder
+	w := TriXWriter{W: &b}
+	for _, q := range quads {
+		if err := w.WriteQuad(q); err != nil {
+			t.Fatal("write error:", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal("close error:", err)
+	}
+
+	const want = `<?xml version="1.0" encoding="UTF-8"?>
+<TriX xmlns="http://www.w3.org/2004/03/trix/trix-1/">
+	<graph>
+		<triple>
+			<uri>http://example.org/s</uri>
+			<uri>http://example.org/p</uri>
+			<uri>http://example.org/o</uri>
+		</triple>
+		<triple>
+			<uri>http://example.org/s</uri>
+			<uri>http://example.org/p</uri>
+			<plainLiteral>a &lt; b &amp; "c"</plainLiteral>
+		</triple>
+	</graph>
+	<graph>
+		<uri>http://example.org/g?a=1&amp;b=2</uri>
+		<triple>
+			<uri>http://example.org/s</uri>
+			<uri>http://example.org/p</uri>
+			<plainLiteral xml:lang="nl">hallo</plainLiteral>
+		</triple>
+		<triple>
+			<uri>http://example.org/s</uri>
+			<uri>http://example.org/p</uri>
+			<typedLiteral datatype="http://www.w3.org/2001/XMLSchema#decimal">1.0</typedLiteral>
+		</triple>
+	</graph>
+	<graph>
+		<triple>
+			<uri>http://example.org/s</uri>
+			<uri>http://example.org/p</uri>
+			<plainLiteral>line&#xD;
+feed</plainLiteral>
+		</triple>
+	</graph>
+</TriX>
+`
+	if got := b.String(); got != want {
+		t.Errorf("got TriX:\n%s\nwant:\n%s", got, want)
+	}
+
+	// round trip
+	r := TriXReader{R: strings.NewReader(b.String())}
+	got := []Quad{}
+	var err error
+	for err == nil {
+		got, err = r.ReadAppend(got)
+	}
+	if err != io.EOF {
+		t.Fatal("read error:", err)
+	}
+	if !slices.Equal(got, quads) {
+		t.Errorf("got quads %q, want %q", got, quads)
+	}
+}
+
+func TestTriXWriterUnsupported(t *testing.T) {
+	for _, q := range []Quad{
+		{Triple{"http://example.org/s", "http://example.org/p", "hello", rdfDirLangString, "en", "ltr"}, ""},
+		{Triple{"<< <http://example.org/a> <http://example.org/b> <http://example.org/c> >>", "http://example.org/p", "http://example.org/o", "", "", ""}, ""},
+		{Triple{"http://example.org/s", "http://example.org/p", "x\x01y", XSDString, "", ""}, ""},
+	} {
+		var b strings.Builder
+		w := TriXWriter{W: &b}
+		if err := w.WriteQuad(q); err == nil {
+			t.Errorf("got no error for %s", q)
+		}
+		if b.Len() != 0 {
+			t.Errorf("got output %q for %s", b.String(), q)
+		}
+	}
+}
+
+func TestTriXWriterBlankNodes(t *testing.T) {
+	var r Reader
+	x, g := r.newAnonIRI(), r.newAnonIRI()
+
+	var b strings.Builder
+	w := TriXWriter{W: &b}
+	for _, q := range []Quad{
+		{Triple{x, "http://example.org/p", x, "", "", ""}, g},
+	} {
+		if err := w.WriteQuad(q); err != nil {
+			t.Fatal("write error:", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal("close error:", err)
+	}
+
+	const want = `<?xml version="1.0" encoding="UTF-8"?>
+<TriX xmlns="http://www.w3.org/2004/03/trix/trix-1/">
+	<graph>
+		<id>b1</id>
+		<triple>
+			<id>b2</id>
+			<uri>http://example.org/p</uri>
+			<id>b2</id>
+		</triple>
+	</graph>
+</TriX>
+`
+	if got := b.String(); got != want {
+		t.Errorf("got TriX:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// FailWriter fails the first write.
+type failWriter struct {
+	b      strings.Builder
+	failed bool
+}
+
+func (w *failWriter) Write(p []byte) (int, error) {
+	if !w.failed {
+		w.failed = true
+		return 0, errors.New("write failure")
+	}
+	return w.b.Write(p)
+}
+
+func TestTriXWriterRetry(t *testing.T) {
+	var b failWriter
+	w := TriXWriter{W: &b}
+	q := Quad{Triple{"http://example.org/s", "http://example.org/p", "http://example.org/o", "", "", ""}, ""}
+	if err := w.WriteQuad(q); err == nil {
+		t.Fatal("got no error on write failure")
+	}
+	if err := w.WriteQuad(q); err != nil {
+		t.Fatal("write error:", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal("close error:", err)
+	}
+	if got := b.b.String(); !strings.HasPrefix(got, trixHeader+"\t<graph>\n") {
+		t.Errorf("got TriX:\n%s\nwant header and graph after retry", got)
+	}
+}
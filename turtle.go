@@ -0,0 +1,692 @@
+package tripn
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// TurtleOrder is the sequence of statements in Turtle output.
+type TurtleOrder uint8
+
+// Turtle output supports the following sequences.
+const (
+	// InputOrder has subjects, their predicates, and their objects in order
+	// of first appearance.
+	InputOrder TurtleOrder = iota
+
+	// SortedOrder has subjects, their predicates, and their objects in
+	// lexical order, with rdf:type as the first predicate. Output is then
+	// independent of the input order.
+	SortedOrder
+)
+
+// TurtleWriter encodes Turtle for human consumption. Statements are grouped
+// per subject with ";", and per predicate with ",". Duplicate statements are
+// omitted. Output is written on Close, as grouping needs all statements.
+//
+// Skolem IRIs, as minted by Reader, are written as blank nodes. The ones with a
+// single reference get folded into an anonymous node "[ … ]", or into a
+// collection "( … )" when they form a well-formed list. The ones with multiple
+// references, or with a reference from a quoted triple, get a blank node label
+// "_:…" instead.
+type TurtleWriter struct {
+	W io.Writer
+
+	// Prefixes maps labels to namespace IRIs, as in "ex" to
+	// "http://example.com/ns#". IRI references within a namespace are
+	// written as a prefixed name. Only the prefixes in use get a directive.
+	Prefixes map[string]string
+
+	Order TurtleOrder
+
+	triples []Triple
+}
+
+// WriteTriple buffers t. Triples with a relative IRI reference, or with a
+// malformed language tag, can not be expressed in Turtle. Such triples get an
+// error without any effect.
+func (w *TurtleWriter) WriteTriple(t Triple) error {
+	t, err := checkNTriple(t)
+	if err != nil {
+		return err
+	}
+	w.triples = append(w.triples, t)
+	return nil
+}
+
+// Close writes the document with all triples buffered. It does not close W.
+func (w *TurtleWriter) Close() error {
+	enc := turtleEncoder{prefixes: w.Prefixes}
+	if err := enc.checkPrefixes(); err != nil {
+		return err
+	}
+	subjects := groupTurtle(w.triples, w.Order)
+	enc.scan(subjects, "")
+	body := enc.appendStatements(nil, subjects, "")
+	w.triples = nil
+
+	doc := enc.appendPrefixes(nil)
+	if len(doc) != 0 && len(body) != 0 {
+		doc = append(doc, '\n')
+	}
+	doc = append(doc, body...)
+	_, err := w.W.Write(doc)
+	return err
+}
+
+// TurtleEncoder holds the notation state of a Turtle document.
+type turtleEncoder struct {
+	prefixes map[string]string // namespace IRI per label
+	used     map[string]bool   // prefix labels written
+	labels   map[string]string // blank node label per Skolem IRI
+
+	refs    map[string]int    // object occurrences per Skolem IRI
+	pinned  map[string]bool   // Skolem IRIs written as is
+	shared  map[string]bool   // Skolem IRIs which need a blank node label
+	graphOf map[string]string // first graph per Skolem IRI
+}
+
+// CheckPrefixes verifies the prefix mapping.
+func (e *turtleEncoder) checkPrefixes() error {
+	for label, IRI := range e.prefixes {
+		if scanPrefixLabel([]byte(label)) != len(label) {
+			return fmt.Errorf("Turtle can not express prefix label %q", label)
+		}
+		if err := checkNTriplesIRI(IRI); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AppendPrefixes appends a directive for each of the prefixes written, if any,
+// in order of label.
+func (e *turtleEncoder) appendPrefixes(dst []byte) []byte {
+	labels := make([]string, 0, len(e.used))
+	for label := range e.used {
+		labels = append(labels, label)
+	}
+	slices.Sort(labels)
+	for _, label := range labels {
+		dst = append(dst, "@prefix "...)
+		dst = append(dst, label...)
+		dst = append(dst, ": "...)
+		dst = appendNTriplesIRI(dst, e.prefixes[label])
+		dst = append(dst, " .\n"...)
+	}
+	return dst
+}
+
+// TurtleSubject has the statements of a subject grouped per predicate.
+type turtleSubject struct {
+	IRI        string
+	predicates []*turtlePredicate
+}
+
+// TurtlePredicate has the objects of a subject–predicate pair. Only the object
+// fields of each triple are in use.
+type turtlePredicate struct {
+	IRI     string
+	objects []Triple
+}
+
+// GroupTurtle returns the triples per subject, without duplicates.
+func groupTurtle(triples []Triple, order TurtleOrder) []*turtleSubject {
+	var subjects []*turtleSubject
+	subjectIndex := make(map[string]*turtleSubject)
+	predicateIndex := make(map[[2]string]*turtlePredicate)
+	seen := make(map[Triple]struct{}, len(triples))
+	for _, t := range triples {
+		if _, ok := seen[t]; ok {
+			continue
+		}
+		seen[t] = struct{}{}
+
+		s, ok := subjectIndex[t.SubjectIRI]
+		if !ok {
+			s = &turtleSubject{IRI: t.SubjectIRI}
+			subjectIndex[t.SubjectIRI] = s
+			subjects = append(subjects, s)
+		}
+		key := [2]string{t.SubjectIRI, t.PredicateIRI}
+		p, ok := predicateIndex[key]
+		if !ok {
+			p = &turtlePredicate{IRI: t.PredicateIRI}
+			predicateIndex[key] = p
+			s.predicates = append(s.predicates, p)
+		}
+		p.objects = append(p.objects, t)
+	}
+
+	if order == SortedOrder {
+		slices.SortFunc(subjects, func(a, b *turtleSubject) int {
+			return strings.Compare(a.IRI, b.IRI)
+		})
+		for _, s := range subjects {
+			slices.SortFunc(s.predicates, compareTurtlePredicates)
+			for _, p := range s.predicates {
+				slices.SortFunc(p.objects, compareTurtleObjects)
+			}
+		}
+	}
+	return subjects
+}
+
+// CompareTurtlePredicates orders rdf:type first, and lexical otherwise.
+func compareTurtlePredicates(a, b *turtlePredicate) int {
+	return cmp.Or(
+		-cmp.Compare(boolInt(a.IRI == rdfType), boolInt(b.IRI == rdfType)),
+		strings.Compare(a.IRI, b.IRI),
+	)
+}
+
+// CompareTurtleObjects orders IRI references before literals, and lexical
+// otherwise.
+func compareTurtleObjects(a, b Triple) int {
+	return cmp.Or(
+		cmp.Compare(boolInt(a.DatatypeIRI != ""), boolInt(b.DatatypeIRI != "")),
+		strings.Compare(a.Object, b.Object),
+		strings.Compare(a.DatatypeIRI, b.DatatypeIRI),
+		strings.Compare(a.LangTag, b.LangTag),
+		strings.Compare(a.BaseDir, b.BaseDir),
+	)
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Scan registers the use of Skolem IRIs in the subjects of a graph, with zero
+// for the default graph. All graphs must be scanned before any append.
+func (e *turtleEncoder) scan(subjects []*turtleSubject, graphIRI string) {
+	if e.refs == nil {
+		e.refs = make(map[string]int)
+		e.pinned = make(map[string]bool)
+		e.shared = make(map[string]bool)
+		e.graphOf = make(map[string]string)
+	}
+	if IsSkolemIRI(graphIRI) {
+		e.shared[graphIRI] = true
+	}
+
+	for _, s := range subjects {
+		e.scanNode(s.IRI, graphIRI)
+		for _, p := range s.predicates {
+			if IsSkolemIRI(p.IRI) {
+				e.pinned[p.IRI] = true
+			}
+			for _, o := range p.objects {
+				if o.DatatypeIRI != "" {
+					continue
+				}
+				if IsSkolemIRI(o.Object) {
+					e.refs[o.Object]++
+				}
+				e.scanNode(o.Object, graphIRI)
+			}
+		}
+	}
+}
+
+// ScanNode registers a subject or object node.
+func (e *turtleEncoder) scanNode(s, graphIRI string) {
+	if IsSkolemIRI(s) {
+		if g, ok := e.graphOf[s]; ok && g != graphIRI {
+			e.shared[s] = true
+		}
+		e.graphOf[s] = graphIRI
+		return
+	}
+	if !IsQuotedTriple(s) {
+		return
+	}
+	t, err := ParseQuotedTriple(s)
+	if err != nil {
+		return // written as is
+	}
+
+	// Skolem IRIs in a quoted triple need a blank node label
+	for _, node := range []string{t.SubjectIRI, t.Object} {
+		if node == t.Object && t.DatatypeIRI != "" {
+			break
+		}
+		if IsSkolemIRI(node) {
+			e.shared[node] = true
+		} else {
+			e.scanNode(node, graphIRI)
+		}
+	}
+	if IsSkolemIRI(t.PredicateIRI) {
+		e.pinned[t.PredicateIRI] = true
+	}
+}
+
+// IsAnonymous returns whether s is a Skolem IRI which may be written without
+// a blank node label.
+func (e *turtleEncoder) isAnonymous(s string) bool {
+	return IsSkolemIRI(s) && !e.pinned[s] && !e.shared[s]
+}
+
+// AppendStatements appends the subjects of a graph. Each line gets indent as a
+// prefix. Subjects are separated by an empty line.
+func (e *turtleEncoder) appendStatements(dst []byte, subjects []*turtleSubject, indent string) []byte {
+	g := newTurtleGraph(e, subjects)
+	for _, s := range g.subjects {
+		if !g.inline[s.IRI] {
+			dst = g.appendSubject(dst, s, indent)
+		}
+	}
+	// references in a cycle need a label
+	for _, s := range g.subjects {
+		if !g.done[s.IRI] {
+			delete(g.inline, s.IRI)
+			dst = g.appendSubject(dst, s, indent)
+		}
+	}
+	return dst
+}
+
+// TurtleGraph has the layout of statements. Skolem IRIs get folded back into
+// the anonymous nodes "[ … ]" and the collections "( … )" when referenced once.
+type turtleGraph struct {
+	*turtleEncoder
+
+	subjects     []*turtleSubject
+	subjectIndex map[string]*turtleSubject
+
+	inline map[string]bool // Skolem IRIs written in place of their reference
+	done   map[string]bool // subjects written
+
+	statementCount int
+}
+
+func newTurtleGraph(e *turtleEncoder, subjects []*turtleSubject) *turtleGraph {
+	g := &turtleGraph{
+		turtleEncoder: e,
+		subjects:      subjects,
+		subjectIndex:  make(map[string]*turtleSubject, len(subjects)),
+		inline:        make(map[string]bool),
+		done:          make(map[string]bool),
+	}
+	for _, s := range subjects {
+		g.subjectIndex[s.IRI] = s
+		for _, p := range s.predicates {
+			for _, o := range p.objects {
+				if o.DatatypeIRI == "" && e.refs[o.Object] == 1 && e.isAnonymous(o.Object) {
+					g.inline[o.Object] = true
+				}
+			}
+		}
+	}
+	return g
+}
+
+// AppendSubject appends the statements of s.
+func (g *turtleGraph) appendSubject(dst []byte, s *turtleSubject, indent string) []byte {
+	g.done[s.IRI] = true
+	if g.statementCount != 0 {
+		dst = append(dst, '\n')
+	}
+	g.statementCount++
+
+	dst = append(dst, indent...)
+	predicates := s.predicates
+	if g.refs[s.IRI] == 0 && g.isAnonymous(s.IRI) {
+		if items, others, ok := g.collectionSubject(s); ok {
+			dst = g.appendItems(dst, items)
+			predicates = others
+		} else {
+			dst = append(dst, "[]"...)
+		}
+	} else {
+		dst = g.appendNode(dst, s.IRI)
+	}
+	for i, p := range predicates {
+		if i != 0 {
+			dst = append(dst, " ;\n"...)
+			dst = append(dst, indent...)
+			dst = append(dst, '\t')
+		} else {
+			dst = append(dst, ' ')
+		}
+		dst = g.appendPredicate(dst, p)
+	}
+	return append(dst, " .\n"...)
+}
+
+// AppendPredicate appends the predicate with its objects.
+func (g *turtleGraph) appendPredicate(dst []byte, p *turtlePredicate) []byte {
+	if p.IRI == rdfType {
+		dst = append(dst, 'a')
+	} else {
+		dst = g.appendIRI(dst, p.IRI)
+	}
+	for i, o := range p.objects {
+		if i != 0 {
+			dst = append(dst, ',')
+		}
+		dst = append(dst, ' ')
+		dst = g.appendObject(dst, o)
+	}
+	return dst
+}
+
+// AppendObject appends the object of t.
+func (g *turtleGraph) appendObject(dst []byte, t Triple) []byte {
+	switch {
+	case t.DatatypeIRI != "":
+		return g.turtleEncoder.appendObject(dst, t)
+	case t.Object == rdfNil:
+		return append(dst, "()"...)
+	case g.inline[t.Object] && !g.done[t.Object]:
+		return g.appendInline(dst, t.Object)
+	}
+	return g.appendNode(dst, t.Object)
+}
+
+// AppendInline appends a Skolem IRI as either a collection, or as an anonymous
+// node with its predicate–object list.
+func (g *turtleGraph) appendInline(dst []byte, IRI string) []byte {
+	if items, ok := g.collection(IRI); ok {
+		return g.appendItems(dst, items)
+	}
+
+	g.done[IRI] = true
+	s := g.subjectIndex[IRI]
+	if s == nil {
+		return append(dst, "[]"...)
+	}
+	dst = append(dst, '[')
+	for i, p := range s.predicates {
+		if i != 0 {
+			dst = append(dst, " ;"...)
+		}
+		dst = append(dst, ' ')
+		dst = g.appendPredicate(dst, p)
+	}
+	return append(dst, " ]"...)
+}
+
+// AppendItems appends a collection "( … )".
+func (g *turtleGraph) appendItems(dst []byte, items []Triple) []byte {
+	dst = append(dst, '(')
+	for _, item := range items {
+		dst = append(dst, ' ')
+		dst = g.appendObject(dst, item)
+	}
+	return append(dst, " )"...)
+}
+
+// CollectionSubject returns the items when s is the head of a well-formed
+// collection, with others as the remaining predicates. Turtle needs at least
+// one other predicate for a collection in subject position.
+func (g *turtleGraph) collectionSubject(s *turtleSubject) (items []Triple, others []*turtlePredicate, ok bool) {
+	var first, rest *turtlePredicate
+	for _, p := range s.predicates {
+		switch {
+		case p.IRI == rdfFirst && first == nil:
+			first = p
+		case p.IRI == rdfRest && rest == nil:
+			rest = p
+		default:
+			others = append(others, p)
+		}
+	}
+	if first == nil || rest == nil || len(others) == 0 || len(first.objects) != 1 || len(rest.objects) != 1 || rest.objects[0].DatatypeIRI != "" {
+		return nil, nil, false
+	}
+	items = []Triple{first.objects[0]}
+	if next := rest.objects[0].Object; next != rdfNil {
+		tail, ok := g.collection(next)
+		if !ok {
+			return nil, nil, false
+		}
+		items = append(items, tail...)
+	}
+	return items, others, true
+}
+
+// Collection returns the items when IRI is the head of a well-formed chain of
+// rdf:first and rdf:rest statements, with nothing else on the nodes. The nodes
+// are marked as done on success.
+func (g *turtleGraph) collection(IRI string) (items []Triple, ok bool) {
+	var nodes []string
+	for node := IRI; node != rdfNil; {
+		s := g.subjectIndex[node]
+		if s == nil || !g.inline[node] || g.done[node] || len(s.predicates) != 2 || len(nodes) > len(g.subjects) {
+			return nil, false
+		}
+		first, rest := s.predicates[0], s.predicates[1]
+		if first.IRI != rdfFirst {
+			first, rest = rest, first
+		}
+		if first.IRI != rdfFirst || rest.IRI != rdfRest || len(first.objects) != 1 || len(rest.objects) != 1 || rest.objects[0].DatatypeIRI != "" {
+			return nil, false
+		}
+		items = append(items, first.objects[0])
+		nodes = append(nodes, node)
+		node = rest.objects[0].Object
+	}
+
+	for _, node := range nodes {
+		g.done[node] = true
+	}
+	return items, true
+}
+
+// Label returns the blank node label of a Skolem IRI. Labels are numbered in
+// order of appearance.
+func (e *turtleEncoder) label(IRI string) string {
+	l, ok := e.labels[IRI]
+	if !ok {
+		if e.labels == nil {
+			e.labels = make(map[string]string)
+		}
+		l = "b" + strconv.Itoa(len(e.labels)+1)
+		e.labels[IRI] = l
+	}
+	return l
+}
+
+// AppendObject appends the object of t.
+func (e *turtleEncoder) appendObject(dst []byte, t Triple) []byte {
+	switch {
+	case t.DatatypeIRI == "":
+		return e.appendNode(dst, t.Object)
+	case t.LangTag != "":
+		dst = appendTurtleString(dst, t.Object)
+		dst = append(dst, '@')
+		dst = append(dst, t.LangTag...)
+		if t.BaseDir != "" {
+			dst = append(dst, "--"...)
+			dst = append(dst, t.BaseDir...)
+		}
+		return dst
+	case t.DatatypeIRI == XSDString:
+		return appendTurtleString(dst, t.Object)
+	case t.DatatypeIRI == XSDBoolean && (t.Object == "true" || t.Object == "false"),
+		t.DatatypeIRI == turtleNumberDatatype(t.Object):
+		return append(dst, t.Object...)
+	}
+	dst = appendTurtleString(dst, t.Object)
+	dst = append(dst, "^^"...)
+	return e.appendIRI(dst, t.DatatypeIRI)
+}
+
+// AppendNode appends either a blank node label for a Skolem IRI, or a quoted
+// triple, or an IRI reference. Skolem IRIs used as a predicate remain as is.
+func (e *turtleEncoder) appendNode(dst []byte, s string) []byte {
+	switch {
+	case IsSkolemIRI(s) && !e.pinned[s]:
+		dst = append(dst, "_:"...)
+		return append(dst, e.label(s)...)
+	case IsQuotedTriple(s):
+		return e.appendQuoted(dst, s)
+	}
+	return e.appendIRI(dst, s)
+}
+
+// AppendQuoted appends a quoted triple, or a triple term, with its nodes in the
+// same notation as the statements.
+func (e *turtleEncoder) appendQuoted(dst []byte, s string) []byte {
+	t, err := ParseQuotedTriple(s)
+	if err != nil {
+		return append(dst, s...)
+	}
+	isTerm := strings.HasPrefix(s, "<<(")
+	if isTerm {
+		dst = append(dst, "<<( "...)
+	} else {
+		dst = append(dst, "<< "...)
+	}
+	dst = e.appendNode(dst, t.SubjectIRI)
+	dst = append(dst, ' ')
+	dst = e.appendIRI(dst, t.PredicateIRI)
+	dst = append(dst, ' ')
+	dst = e.appendObject(dst, t)
+	if isTerm {
+		return append(dst, " )>>"...)
+	}
+	return append(dst, " >>"...)
+}
+
+// AppendIRI appends s as a prefixed name when possible, and as an IRIREF
+// otherwise.
+func (e *turtleEncoder) appendIRI(dst []byte, s string) []byte {
+	var label, namespace string
+	found := false
+	for l, IRI := range e.prefixes {
+		if !strings.HasPrefix(s, IRI) || !isTurtleLocalName(s[len(IRI):]) {
+			continue
+		}
+		// longest namespace wins; label order resolves ties
+		if !found || len(IRI) > len(namespace) || len(IRI) == len(namespace) && l < label {
+			label, namespace, found = l, IRI, true
+		}
+	}
+	if !found {
+		return appendNTriplesIRI(dst, s)
+	}
+
+	if e.used == nil {
+		e.used = make(map[string]bool)
+	}
+	e.used[label] = true
+	dst = append(dst, label...)
+	dst = append(dst, ':')
+	return append(dst, s[len(namespace):]...)
+}
+
+// IsTurtleLocalName returns whether s matches PN_LOCAL without any escapes.
+func isTurtleLocalName(s string) bool {
+	if s == "" {
+		return true
+	}
+	if s[len(s)-1] == '.' {
+		return false
+	}
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == ':', c >= '0' && c <= '9':
+			i++
+		case c == '.' || c == '-':
+			if i == 0 {
+				return false
+			}
+			i++
+		case c == '%':
+			if i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2]) {
+				return false
+			}
+			i += 3
+		default:
+			r, size := utf8.DecodeRuneInString(s[i:])
+			if !isPNChars(r) || i == 0 && !isPNCharsBase(r) && r != '_' {
+				return false
+			}
+			i += size
+		}
+	}
+	return true
+}
+
+// TurtleNumberDatatype returns the datatype of s as a number token, with zero
+// for none.
+func turtleNumberDatatype(s string) string {
+	i := 0
+	if i < len(s) && (s[i] == '+' || s[i] == '-') {
+		i++
+	}
+	intDigits := digitsEnd(s, i) - i
+	i += intDigits
+
+	fracDigits, hasDot := 0, false
+	if i < len(s) && s[i] == '.' {
+		hasDot = true
+		fracDigits = digitsEnd(s, i+1) - (i + 1)
+		i += 1 + fracDigits
+	}
+	if intDigits == 0 && fracDigits == 0 {
+		return ""
+	}
+
+	if i == len(s) {
+		switch {
+		case !hasDot:
+			return XSDInteger
+		case fracDigits != 0:
+			return XSDDecimal
+		default:
+			return "" // "1." reads as an integer with a dot
+		}
+	}
+
+	if s[i] != 'E' && s[i] != 'e' {
+		return ""
+	}
+	i++
+	if i < len(s) && (s[i] == '+' || s[i] == '-') {
+		i++
+	}
+	if end := digitsEnd(s, i); end == i || end != len(s) {
+		return ""
+	}
+	return XSDDouble
+}
+
+// DigitsEnd returns the index after any "0".."9" in s from offset.
+func digitsEnd(s string, offset int) int {
+	for offset < len(s) && s[offset] >= '0' && s[offset] <= '9' {
+		offset++
+	}
+	return offset
+}
+
+// AppendTurtleString appends s as a STRING_LITERAL_QUOTE, or as a
+// STRING_LITERAL_LONG_QUOTE when s spans multiple lines.
+func appendTurtleString(dst []byte, s string) []byte {
+	if !strings.Contains(s, "\n") {
+		return appendNTriplesString(dst, s)
+	}
+
+	dst = append(dst, `"""`...)
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\n':
+			dst = append(dst, c)
+		case c == '"' && i+1 < len(s) && s[i+1] != '"':
+			dst = append(dst, c) // can't end the quote
+		default:
+			dst = appendStringChar(dst, c)
+		}
+	}
+	return append(dst, `"""`...)
+}
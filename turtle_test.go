@@ -0,0 +1,217 @@
+package tripn
+
+import (
+	"bufio"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var turtleWriterTriples = []Triple{
+	{"http://example.com/ns#s", "http://example.com/ns#p", "http://example.com/ns#o", "", "", ""},
+	{"http://example.com/ns#s", rdfType, "http://example.com/ns#Class", "", "", ""},
+	{"http://example.com/ns#s", "http://example.com/ns#p", "line 1\nline \"2\"", XSDString, "", ""},
+	{"http://example.com/ns#s", "http://example.com/ns#n", "42", XSDInteger, "", ""},
+	{"http://example.com/ns#s", "http://example.com/ns#n", "-0.5", XSDDecimal, "", ""},
+	{"http://example.com/ns#s", "http://example.com/ns#n", "1.5E3", XSDDouble, "", ""},
+	{"http://example.com/ns#s", "http://example.com/ns#n", "true", XSDBoolean, "", ""},
+	{"http://example.com/ns#s", "http://example.com/ns#n", "1.", XSDDecimal, "", ""},
+	{"http://example.com/ns#s", "http://example.com/ns#n", "yes", XSDBoolean, "", ""},
+	{"http://example.com/other/ab", "http://example.com/ns#label", "hallo", rdfLangString, "nl", ""},
+	{"http://example.com/other/ab", "http://example.com/ns#label", "hello", rdfDirLangString, "en", "ltr"},
+	{"http://example.com/ns#s", "http://example.com/ns#p", "http://example.com/ns#o", "", "", ""},
+	{`<< <http://example.com/ns#s> <http://example.com/ns#p> "x" >>`, "http://example.com/ns#source", "http://example.com/ns#.hidden", "", "", ""},
+}
+
+func TestTurtleWriter(t *testing.T) {
+	prefixes := map[string]string{
+		"ex":  "http://example.com/ns#",
+		"xsd": "http://www.w3.org/2001/XMLSchema#",
+		"foo": "http://example.com/foo#", // not in use
+	}
+
+	tests := []struct {
+		order TurtleOrder
+		want  string
+	}{
+		{InputOrder, `@prefix ex: <http://example.com/ns#> .
+@prefix xsd: <http://www.w3.org/2001/XMLSchema#> .
+
+ex:s ex:p ex:o, """line 1
+line "2\"""" ;
+	a ex:Class ;
+	ex:n 42, -0.5, 1.5E3, true, "1."^^xsd:decimal, "yes"^^xsd:boolean .
+
+<http://example.com/other/ab> ex:label "hallo"@nl, "hello"@en--ltr .
+
+<< ex:s ex:p "x" >> ex:source <http://example.com/ns#.hidden> .
+`},
+		{SortedOrder, `@prefix ex: <http://example.com/ns#> .
+@prefix xsd: <http://www.w3.org/2001/XMLSchema#> .
+
+<< ex:s ex:p "x" >> ex:source <http://example.com/ns#.hidden> .
+
+ex:s a ex:Class ;
+	ex:n -0.5, "1."^^xsd:decimal, 1.5E3, 42, true, "yes"^^xsd:boolean ;
+	ex:p ex:o, """line 1
+line "2\"""" .
+
+<http://example.com/other/ab> ex:label "hallo"@nl, "hello"@en--ltr .
+`},
+	}
+	for _, test := range tests {
+		var b strings.Builder
+		w := TurtleWriter{W: &b, Prefixes: prefixes, Order: test.order}
+		for _, triple := range turtleWriterTriples {
+			if err := w.WriteTriple(triple); err != nil {
+				t.Fatal("write error:", err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal("close error:", err)
+		}
+		if got := b.String(); got != test.want {
+			t.Errorf("got Turtle in order %d:\n%s\nwant:\n%s", test.order, got, test.want)
+		}
+
+		// round trip
+		r := Reader{R: bufio.NewReader(strings.NewReader(b.String()))}
+		got := []Triple{}
+		var err error
+		for err == nil {
+			got, err = r.ReadAppend(got)
+		}
+		if err != io.EOF {
+			t.Fatal("read error:", err)
+		}
+		want := slices.Compact(slices.SortedFunc(slices.Values(turtleWriterTriples), compareTriples))
+		if got = slices.SortedFunc(slices.Values(got), compareTriples); !slices.Equal(got, want) {
+			t.Errorf("got triples %q, want %q", got, want)
+		}
+	}
+}
+
+func compareTriples(a, b Triple) int {
+	return strings.Compare(a.String(), b.String())
+}
+
+func TestTurtleWriterUnsupported(t *testing.T) {
+	w := TurtleWriter{W: io.Discard}
+	if err := w.WriteTriple(Triple{"s", "http://example.com/p", "http://example.com/o", "", "", ""}); err == nil {
+		t.Error("got no error for relative IRI reference")
+	}
+
+	w.Prefixes = map[string]string{"1x": "http://example.com/"}
+	if err := w.Close(); err == nil {
+		t.Error("got no error for malformed prefix label")
+	}
+}
+
+var turtleSkolemFolds = []struct {
+	turtle string
+	want   string
+}{
+	// anonymous nodes and collections
+	{`@prefix : <http://example.com/> .
+:s :p [ :q [ :r 1 ] ; :q [] ] ; :list ( 1 [ :p 2 ] ( ) ( :a ) ) ; :empty () .
+[ :p :o ] .
+[] :p :o .
+`, `@prefix : <http://example.com/> .
+
+:s :p [ :q [ :r 1 ], [] ] ;
+	:list ( 1 [ :p 2 ] () ( :a ) ) ;
+	:empty () .
+
+[] :p :o .
+
+[] :p :o .
+`},
+
+	// labels for multiple references and for cycles
+	{`@prefix : <http://example.com/> .
+:s :p _:x ; :q _:x .
+_:x :p :o .
+_:y :p [ :p _:y ] .
+`, `@prefix : <http://example.com/> .
+
+:s :p _:b1 ;
+	:q _:b1 .
+
+_:b1 :p :o .
+
+_:b2 :p [ :p _:b2 ] .
+`},
+
+	// collection as subject
+	{`@prefix : <http://example.com/> .
+( 1 [ :p 2 ] ) :p ( :a ) .
+`, `@prefix : <http://example.com/> .
+
+( 1 [ :p 2 ] ) :p ( :a ) .
+`},
+
+	// blank nodes in quoted triples
+	{`@prefix : <http://example.com/> .
+:s :p _:x .
+<< :s :p _:x >> :p << _:y :p [] >> .
+`, `@prefix : <http://example.com/> .
+
+:s :p _:b1 .
+
+<< :s :p _:b1 >> :p << _:b2 :p _:b3 >> .
+`},
+
+	// malformed lists
+	{`@prefix : <http://example.com/> .
+@prefix rdf: <http://www.w3.org/1999/02/22-rdf-syntax-ns#> .
+:s :p [ rdf:first 1 ; rdf:rest [ rdf:first 2 ] ] .
+:s :p [ rdf:first 1, 2 ; rdf:rest () ] .
+`, `@prefix : <http://example.com/> .
+@prefix rdf: <http://www.w3.org/1999/02/22-rdf-syntax-ns#> .
+
+:s :p [ rdf:first 1 ; rdf:rest [ rdf:first 2 ] ], [ rdf:first 1, 2 ; rdf:rest () ] .
+`},
+}
+
+func TestTurtleWriterSkolemFolds(t *testing.T) {
+	prefixes := map[string]string{
+		"":    "http://example.com/",
+		"rdf": "http://www.w3.org/1999/02/22-rdf-syntax-ns#",
+	}
+	for _, test := range turtleSkolemFolds {
+		r := Reader{R: bufio.NewReader(strings.NewReader(test.turtle))}
+		var b strings.Builder
+		w := TurtleWriter{W: &b, Prefixes: prefixes}
+		var err error
+		for triple, e := range r.All() {
+			if e == nil {
+				e = w.WriteTriple(triple)
+			}
+			if e != nil {
+				err = e
+				break
+			}
+		}
+		if err != nil {
+			t.Errorf("got error %v, for Turtle:\n%s", err, test.turtle)
+			continue
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal("close error:", err)
+		}
+		if got := b.String(); got != test.want {
+			t.Errorf("got Turtle:\n%s\nwant:\n%s\nfor Turtle:\n%s", got, test.want, test.turtle)
+		}
+	}
+}
+
+func TestIsSkolemIRI(t *testing.T) {
+	r := Reader{}
+	if IRI := r.newAnonIRI(); !IsSkolemIRI(IRI) {
+		t.Errorf("Skolem IRI %q not recognized", IRI)
+	}
+	if IsSkolemIRI("http://example.com/") || IsSkolemIRI("web+skolem:") {
+		t.Error("regular IRI recognized as Skolem IRI")
+	}
+}